@@ -0,0 +1,6 @@
+// Package productpb holds the messages and the gRPC service generated from
+// api/product.proto and api/product_service.proto. Run go generate after
+// changing them; it needs protoc, protoc-gen-go and protoc-gen-go-grpc.
+package productpb
+
+//go:generate protoc -I .. --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative product.proto product_service.proto
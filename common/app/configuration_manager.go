@@ -1,15 +1,110 @@
 package app
 
-import "example.com/product-api/common/postgresql"
+import (
+	"example.com/product-api/common/postgresql"
+	"time"
+)
 
 type ConfigurationManager struct {
-	PostgreSqlConfig postgresql.Config
+	ServerConfig      ServerConfig
+	PostgreSqlConfig  postgresql.Config
+	TrashConfig       TrashConfig
+	OutboxConfig      OutboxConfig
+	WebhookConfig     WebhookConfig
+	CacheConfig       CacheConfig
+	HttpCacheConfig   HttpCacheConfig
+	IdempotencyConfig IdempotencyConfig
+}
+
+// ServerConfig holds the listen addresses of the REST and gRPC servers and
+// how long shutdown waits for in-flight requests.
+type ServerConfig struct {
+	HttpAddress     string
+	GrpcAddress     string
+	ShutdownTimeout time.Duration
+}
+
+// TrashConfig controls how long soft deleted products stay restorable and how
+// often the purge job looks for expired ones.
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// OutboxConfig controls the relay that delivers product events from the
+// outbox. Events always go to the webhook subscriptions; the log file and
+// webhook sinks are only used when LogFile and WebhookUrl are set. Delivered
+// events are kept for Retention, which bounds how long the change listener
+// may be disconnected before the event streams miss changes.
+type OutboxConfig struct {
+	PollInterval    time.Duration
+	BatchSize       int
+	Lease           time.Duration
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	Retention       time.Duration
+	LogFile         string
+	WebhookUrl      string
+	WebhookTimeout  time.Duration
+}
+
+// WebhookConfig controls the dispatcher that sends webhook deliveries to
+// subscribers and how failed deliveries are retried.
+type WebhookConfig struct {
+	PollInterval    time.Duration
+	BatchSize       int
+	Lease           time.Duration
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	Timeout         time.Duration
+}
+
+// CacheConfig switches the in-memory product cache on. Entries live for Ttl,
+// products that were not found for NegativeTtl, and at most Size of each
+// kind are kept.
+type CacheConfig struct {
+	Enabled     bool
+	Size        int
+	Ttl         time.Duration
+	NegativeTtl time.Duration
+}
+
+// HttpCacheConfig holds the Cache-Control value of the product GET routes,
+// keyed by route path.
+type HttpCacheConfig struct {
+	ProductCacheControl map[string]string
+}
+
+// IdempotencyConfig controls how long the response to a request with an
+// Idempotency-Key is replayed, after how long a request that never finished
+// gives up its key, and how often expired keys are removed.
+type IdempotencyConfig struct {
+	Window          time.Duration
+	InFlightTimeout time.Duration
+	PurgeInterval   time.Duration
 }
 
 func NewConfigurationManager() *ConfigurationManager {
 	postgreSqlConfig := getPostgreSqlConfig()
 	return &ConfigurationManager{
-		PostgreSqlConfig: postgreSqlConfig,
+		ServerConfig:      getServerConfig(),
+		PostgreSqlConfig:  postgreSqlConfig,
+		TrashConfig:       getTrashConfig(),
+		OutboxConfig:      getOutboxConfig(),
+		WebhookConfig:     getWebhookConfig(),
+		CacheConfig:       getCacheConfig(),
+		HttpCacheConfig:   getHttpCacheConfig(),
+		IdempotencyConfig: getIdempotencyConfig(),
+	}
+}
+
+func getServerConfig() ServerConfig {
+	return ServerConfig{
+		HttpAddress:     "localhost:8080",
+		GrpcAddress:     "localhost:9090",
+		ShutdownTimeout: 10 * time.Second,
 	}
 }
 
@@ -24,3 +119,67 @@ func getPostgreSqlConfig() postgresql.Config {
 		MaxConnectionIdleTime: "10s",
 	}
 }
+
+func getTrashConfig() TrashConfig {
+	return TrashConfig{
+		Retention:     30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+}
+
+func getOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		PollInterval:    time.Second,
+		BatchSize:       100,
+		Lease:           30 * time.Minute,
+		MaxAttempts:     10,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 5 * time.Minute,
+		Retention:       time.Hour,
+		LogFile:         "",
+		WebhookUrl:      "",
+		WebhookTimeout:  10 * time.Second,
+	}
+}
+
+func getWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		PollInterval:    time.Second,
+		BatchSize:       50,
+		Lease:           5 * time.Minute,
+		MaxAttempts:     8,
+		RetryBackoff:    10 * time.Second,
+		MaxRetryBackoff: time.Hour,
+		Timeout:         10 * time.Second,
+	}
+}
+
+func getCacheConfig() CacheConfig {
+	return CacheConfig{
+		Enabled:     false,
+		Size:        10000,
+		Ttl:         time.Minute,
+		NegativeTtl: 10 * time.Second,
+	}
+}
+
+func getHttpCacheConfig() HttpCacheConfig {
+	return HttpCacheConfig{
+		ProductCacheControl: map[string]string{
+			"/api/products":            "public, max-age=30",
+			"/api/products/:id":        "public, max-age=60",
+			"/api/products/search":     "public, max-age=30",
+			"/api/products/facets":     "public, max-age=300",
+			"/api/products/trash":      "no-store",
+			"/api/products/duplicates": "no-store",
+		},
+	}
+}
+
+func getIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Window:          24 * time.Hour,
+		InFlightTimeout: time.Minute,
+		PurgeInterval:   time.Hour,
+	}
+}
@@ -26,7 +26,7 @@ func GetConnectionPool(context context.Context, config Config) *pgxpool.Pool {
 	conn, err := pgxpool.ConnectConfig(context, connConfig)
 
 	if err != nil {
-		log.Error("Unable to connect to database: %v\n", err)
+		log.Errorf("Unable to connect to database: %v\n", err)
 		panic(err)
 	}
 
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type AttributeController struct {
+	attributeService service.IAttributeService
+}
+
+func NewAttributeController(attributeService service.IAttributeService) *AttributeController {
+	return &AttributeController{
+		attributeService: attributeService,
+	}
+}
+
+func (attributeController *AttributeController) RegisterRoutes(e *echo.Echo) {
+	e.PUT("/api/products/:id/attributes", attributeController.UpdateAttributes)
+}
+
+func (attributeController *AttributeController) UpdateAttributes(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var attributes map[string]interface{}
+	err = c.Bind(&attributes)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = attributeController.attributeService.UpdateAttributes(int64(productId), attributes)
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type CategoryController struct {
+	categoryService service.ICategoryService
+}
+
+func NewCategoryController(categoryService service.ICategoryService) *CategoryController {
+	return &CategoryController{
+		categoryService: categoryService,
+	}
+}
+
+func (categoryController *CategoryController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/categories", categoryController.GetAll)
+	e.GET("/api/categories/:id", categoryController.GetById)
+	e.GET("/api/categories/:id/children", categoryController.GetChildren)
+	e.POST("/api/categories", categoryController.Add)
+	e.PUT("/api/categories/:id", categoryController.Update)
+	e.DELETE("/api/categories/:id", categoryController.Delete)
+	e.GET("/api/products/:id/categories", categoryController.GetAllByProduct)
+	e.POST("/api/products/:id/categories/:categoryId", categoryController.AddProduct)
+	e.DELETE("/api/products/:id/categories/:categoryId", categoryController.RemoveProduct)
+}
+
+func (categoryController *CategoryController) GetAll(c echo.Context) error {
+	return c.JSON(http.StatusOK, response.ToCategoryResponseList(categoryController.categoryService.GetAll()))
+}
+
+func (categoryController *CategoryController) GetById(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	category, err := categoryController.categoryService.GetById(int64(categoryId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToCategoryResponse(category))
+}
+
+func (categoryController *CategoryController) GetChildren(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	children, err := categoryController.categoryService.GetChildren(int64(categoryId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToCategoryResponseList(children))
+}
+
+func (categoryController *CategoryController) Add(c echo.Context) error {
+	var addCategoryRequest request.AddCategoryRequest
+	err := c.Bind(&addCategoryRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = categoryController.categoryService.Add(addCategoryRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, addCategoryRequest.ToModel())
+}
+
+func (categoryController *CategoryController) Update(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var addCategoryRequest request.AddCategoryRequest
+	err = c.Bind(&addCategoryRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = categoryController.categoryService.Update(int64(categoryId), addCategoryRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (categoryController *CategoryController) Delete(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	_, err = categoryController.categoryService.GetById(int64(categoryId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = categoryController.categoryService.DeleteById(int64(categoryId))
+
+	if err != nil {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (categoryController *CategoryController) GetAllByProduct(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	categories, err := categoryController.categoryService.GetAllByProductId(int64(productId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToCategoryResponseList(categories))
+}
+
+func (categoryController *CategoryController) AddProduct(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("categoryId"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid category id"})
+	}
+
+	err = categoryController.categoryService.AddProduct(int64(categoryId), int64(productId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (categoryController *CategoryController) RemoveProduct(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("categoryId"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid category id"})
+	}
+
+	err = categoryController.categoryService.RemoveProduct(int64(categoryId), int64(productId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ProtoMarshaler is implemented by the payloads that have a message in
+// api/product.proto. They convert themselves to the message generated into
+// api/productpb and encode it with Marshal.
+type ProtoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+type ProtoUnmarshaler interface {
+	UnmarshalProto(data []byte) error
+}
+
+// Marshal encodes map fields in key order, so the same payload always gives
+// the same bytes.
+func Marshal(message proto.Message) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(message)
+}
+
+// NewStruct converts attributes into a google.protobuf.Struct. Empty
+// attributes give no message, as proto3 leaves unset fields out.
+func NewStruct(attributes map[string]interface{}) (*structpb.Struct, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	return structpb.NewStruct(attributes)
+}
+
+// StructAsMap converts a google.protobuf.Struct back into attributes, which
+// are nil when the field was not set.
+func StructAsMap(message *structpb.Struct) map[string]interface{} {
+	if message == nil {
+		return nil
+	}
+
+	return message.AsMap()
+}
@@ -0,0 +1,110 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Attributes is a free form attribute map that can also travel as XML,
+// which has no native map: each entry becomes
+// <attribute key="color">black</attribute>. Numbers and booleans are read
+// back with their JSON types; nested values are written as JSON text.
+type Attributes map[string]interface{}
+
+// StringAttributes is the XML friendly form of a map of string values.
+type StringAttributes map[string]string
+
+type xmlAttribute struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (attributes Attributes) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	values := map[string]string{}
+
+	for key, value := range attributes {
+		switch typed := value.(type) {
+		case string:
+			values[key] = typed
+		case float64, float32, int, int64, bool:
+			values[key] = fmt.Sprint(typed)
+		default:
+			encoded, err := json.Marshal(typed)
+			if err != nil {
+				return err
+			}
+			values[key] = string(encoded)
+		}
+	}
+
+	return encodeXmlAttributes(encoder, start, values)
+}
+
+func (attributes *Attributes) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	values, err := decodeXmlAttributes(decoder, start)
+
+	if err != nil {
+		return err
+	}
+
+	*attributes = Attributes{}
+
+	for key, value := range values {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			(*attributes)[key] = number
+		} else if boolean, err := strconv.ParseBool(value); err == nil {
+			(*attributes)[key] = boolean
+		} else {
+			(*attributes)[key] = value
+		}
+	}
+
+	return nil
+}
+
+func (attributes StringAttributes) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	return encodeXmlAttributes(encoder, start, attributes)
+}
+
+func (attributes *StringAttributes) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	values, err := decodeXmlAttributes(decoder, start)
+	*attributes = values
+	return err
+}
+
+func encodeXmlAttributes(encoder *xml.Encoder, start xml.StartElement, values map[string]string) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]xmlAttribute, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, xmlAttribute{Key: key, Value: values[key]})
+	}
+
+	return encoder.EncodeElement(struct {
+		Entries []xmlAttribute `xml:"attribute"`
+	}{entries}, start)
+}
+
+func decodeXmlAttributes(decoder *xml.Decoder, start xml.StartElement) (map[string]string, error) {
+	var decoded struct {
+		Entries []xmlAttribute `xml:"attribute"`
+	}
+
+	if err := decoder.DecodeElement(&decoded, &start); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for _, entry := range decoded.Entries {
+		values[entry.Key] = entry.Value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/graph"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+type GraphqlController struct {
+	executor *graph.Executor
+}
+
+func NewGraphqlController(executor *graph.Executor) *GraphqlController {
+	return &GraphqlController{
+		executor: executor,
+	}
+}
+
+func (graphqlController *GraphqlController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/graphql", graphqlController.Execute)
+}
+
+// Execute answers with 200 whenever the query ran, errors included, as
+// GraphQL clients read failures from the errors of the response body.
+func (graphqlController *GraphqlController) Execute(c echo.Context) error {
+	var graphqlRequest request.GraphqlRequest
+
+	if err := c.Bind(&graphqlRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if len(strings.TrimSpace(graphqlRequest.Query)) == 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Query can not be empty"})
+	}
+
+	result := graphqlController.executor.Execute(c.Request().Context(), graphqlRequest.Query,
+		graphqlRequest.OperationName, graphqlRequest.Variables)
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"example.com/product-api/domain"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// varyHeaders names the request headers a cached response depends on: the
+// format is negotiated from Accept, and responses to authorized requests may
+// differ from anonymous ones.
+const varyHeaders = echo.HeaderAccept + ", " + echo.HeaderAuthorization
+
+const (
+	HEADER_ETAG          = "ETag"
+	HEADER_IF_NONE_MATCH = "If-None-Match"
+)
+
+// httpCache adds the Cache-Control policy configured for the route to
+// successful and not modified responses, and Vary to all of them. Requests
+// with credentials are only cacheable by the client, so public becomes
+// private for them.
+func httpCache(policies map[string]string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			policy := policies[c.Path()]
+
+			if len(c.Request().Header.Get(echo.HeaderAuthorization)) != 0 {
+				policy = strings.Replace(policy, "public", "private", 1)
+			}
+
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, varyHeaders)
+
+			res.Before(func() {
+				if len(policy) != 0 && (res.Status == http.StatusOK || res.Status == http.StatusNotModified) {
+					res.Header().Set(echo.HeaderCacheControl, policy)
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// productETag is a weak validator of the representation of products: it
+// changes with their ids, order and versions and with the negotiated format.
+func productETag(c echo.Context, products []domain.Product) string {
+	format, _ := c.Get(formatContextKey).(string)
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "%s;%s;", format, c.QueryParam("expand"))
+
+	for _, product := range products {
+		fmt.Fprintf(hash, "%d:%d;", product.Id, product.Version)
+	}
+
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// notModified sets ETag and, when lastModified is not zero, Last-Modified,
+// and reports whether the request's conditions show the client already has
+// this representation. If-Modified-Since is only looked at without
+// If-None-Match, as RFC 9110 asks.
+func notModified(c echo.Context, etag string, lastModified time.Time) bool {
+	req, header := c.Request(), c.Response().Header()
+	header.Set(HEADER_ETAG, etag)
+
+	if !lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := req.Header.Get(HEADER_IF_NONE_MATCH); len(ifNoneMatch) != 0 {
+		return etagMatches(ifNoneMatch, etag)
+	}
+
+	ifModifiedSince, err := http.ParseTime(req.Header.Get(echo.HeaderIfModifiedSince))
+
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// etagMatches compares weakly, ignoring the W/ prefix on either side.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+)
+
+const (
+	HEADER_IDEMPOTENCY_KEY     = "Idempotency-Key"
+	HEADER_IDEMPOTENT_REPLAYED = "Idempotent-Replayed"
+)
+
+// idempotent makes retries of a request with an Idempotency-Key header safe:
+// the first response is stored and replayed to every retry with the same
+// payload. Reusing the key for another payload is answered with 422, and a
+// retry that arrives while the first request still runs with 409. Server
+// errors are not stored, so the request can be retried after them.
+func idempotent(idempotencyService service.IIdempotencyService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HEADER_IDEMPOTENCY_KEY)
+
+			if len(key) == 0 {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+
+			if err != nil {
+				return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+			}
+
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			route := c.Request().Method + " " + c.Request().URL.Path
+			claim, stored, err := idempotencyService.Begin(key, route, requestHash(c.Request(), body))
+
+			switch {
+			case errors.Is(err, service.ErrInvalidIdempotencyKey):
+				return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				return respond(c, http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+			case errors.Is(err, service.ErrIdempotencyKeyInFlight):
+				return respond(c, http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+			case err != nil:
+				return respond(c, http.StatusInternalServerError, response.ErrorResponse{ErrorDescription: err.Error()})
+			case stored != nil:
+				return replay(c, *stored)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// An error is turned into a response by echo only after the
+			// middleware returned, so it can not be stored here.
+			if err = next(c); err != nil || !c.Response().Committed || c.Response().Status >= http.StatusInternalServerError {
+				idempotencyService.Release(claim)
+				return err
+			}
+
+			idempotencyService.Complete(claim, domain.StoredResponse{
+				StatusCode: c.Response().Status,
+				Headers:    c.Response().Header().Clone(),
+				Body:       recorder.body.Bytes(),
+			})
+
+			return nil
+		}
+	}
+}
+
+// requestHash tells requests apart that reuse a key: the same key sent with
+// another body, query or content type is a different request.
+func requestHash(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+"\n"+req.URL.RequestURI()+"\n"+req.Header.Get(echo.HeaderContentType)+"\n")
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(c echo.Context, stored domain.StoredResponse) error {
+	header := c.Response().Header()
+
+	for name, values := range stored.Headers {
+		header[name] = values
+	}
+
+	header.Set(HEADER_IDEMPOTENT_REPLAYED, "true")
+	c.Response().WriteHeader(stored.StatusCode)
+
+	_, err := c.Response().Write(stored.Body)
+
+	return err
+}
+
+// responseRecorder keeps a copy of the body written through it.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
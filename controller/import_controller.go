@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+type ImportController struct {
+	productImportService service.IProductImportService
+}
+
+func NewImportController(productImportService service.IProductImportService) *ImportController {
+	return &ImportController{
+		productImportService: productImportService,
+	}
+}
+
+func (importController *ImportController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/products/import", importController.Import)
+}
+
+// Import accepts either a multipart upload with a file part or the file as
+// the raw request body. The format comes from the format parameter, the file
+// extension or the content type; map.<field>=<column> parameters map product
+// fields to spreadsheet columns. With report=csv the failed rows are returned
+// as a downloadable CSV instead of JSON.
+func (importController *ImportController) Import(c echo.Context) error {
+	dryRun := false
+
+	if value := c.QueryParam("dryRun"); len(value) != 0 {
+		parsed, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter dryRun must be true or false"})
+		}
+
+		dryRun = parsed
+	}
+
+	mapping := map[string]string{}
+	for param, values := range c.QueryParams() {
+		if strings.HasPrefix(param, "map.") {
+			mapping[strings.TrimPrefix(param, "map.")] = values[0]
+		}
+	}
+
+	source, format, err := importSource(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if value := c.QueryParam("format"); len(value) != 0 {
+		format = strings.ToLower(value)
+	}
+
+	report, err := importController.productImportService.Import(source, format, mapping, dryRun)
+
+	if errors.Is(err, service.ErrImportTooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if c.QueryParam("report") == "csv" {
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="import-errors.csv"`)
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=UTF-8")
+		c.Response().WriteHeader(http.StatusOK)
+		return response.WriteImportErrorReport(c.Response(), report)
+	}
+
+	return c.JSON(http.StatusOK, response.ToImportReportResponse(report))
+}
+
+// importSource streams the uploaded file without buffering the multipart
+// form, and guesses its format from the file name or content type.
+func importSource(c echo.Context) (io.Reader, string, error) {
+	multipartReader, err := c.Request().MultipartReader()
+
+	if err != nil {
+		return c.Request().Body, importFormatOf(c.Request().Header.Get(echo.HeaderContentType), ""), nil
+	}
+
+	for {
+		part, err := multipartReader.NextPart()
+
+		if err == io.EOF {
+			return nil, "", errors.New("Multipart upload has no file part")
+		}
+
+		if err != nil {
+			return nil, "", errors.New("Could not read multipart upload")
+		}
+
+		if part.FormName() == "file" {
+			return part, importFormatOf(part.Header.Get(echo.HeaderContentType), part.FileName()), nil
+		}
+	}
+}
+
+func importFormatOf(contentType string, fileName string) string {
+	if extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), ".")); len(extension) != 0 {
+		return extension
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case xlsxContentType:
+		return service.IMPORT_FORMAT_XLSX
+	case "text/csv":
+		return service.IMPORT_FORMAT_CSV
+	}
+
+	return ""
+}
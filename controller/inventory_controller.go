@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type InventoryController struct {
+	inventoryService service.IInventoryService
+}
+
+func NewInventoryController(inventoryService service.IInventoryService) *InventoryController {
+	return &InventoryController{
+		inventoryService: inventoryService,
+	}
+}
+
+func (inventoryController *InventoryController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/products/:id/inventory", inventoryController.GetAllByProduct)
+	e.POST("/api/products/:id/inventory/:storeId/adjust", inventoryController.Adjust)
+	e.POST("/api/products/:id/inventory/:storeId/reserve", inventoryController.Reserve)
+	e.POST("/api/products/:id/inventory/:storeId/release", inventoryController.Release)
+	e.POST("/api/products/:id/inventory/:storeId/commit", inventoryController.Commit)
+}
+
+func (inventoryController *InventoryController) GetAllByProduct(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	inventories, err := inventoryController.inventoryService.GetAllByProductId(int64(productId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToInventoryResponseList(inventories))
+}
+
+func (inventoryController *InventoryController) Adjust(c echo.Context) error {
+	return inventoryController.change(c, true, inventoryController.inventoryService.Adjust)
+}
+
+func (inventoryController *InventoryController) Reserve(c echo.Context) error {
+	return inventoryController.change(c, false, inventoryController.inventoryService.Reserve)
+}
+
+func (inventoryController *InventoryController) Release(c echo.Context) error {
+	return inventoryController.change(c, false, inventoryController.inventoryService.Release)
+}
+
+func (inventoryController *InventoryController) Commit(c echo.Context) error {
+	return inventoryController.change(c, false, inventoryController.inventoryService.Commit)
+}
+
+// change parses the shared path and body of the stock operations. Only
+// adjustments may carry a negative quantity.
+func (inventoryController *InventoryController) change(c echo.Context, allowNegative bool, operation func(int64, int64, int32) (domain.Inventory, error)) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	storeId, err := strconv.Atoi(c.Param("storeId"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid store id"})
+	}
+
+	var inventoryQuantityRequest request.InventoryQuantityRequest
+	err = c.Bind(&inventoryQuantityRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if inventoryQuantityRequest.Quantity == 0 || (!allowNegative && inventoryQuantityRequest.Quantity < 0) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Quantity must be greater than 0"})
+	}
+
+	inventory, err := operation(int64(productId), int64(storeId), inventoryQuantityRequest.Quantity)
+
+	var inventoryConflictError *service.InventoryConflictError
+	if errors.As(err, &inventoryConflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToInventoryResponse(inventory))
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"example.com/product-api/controller/codec"
+	"example.com/product-api/controller/response"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MIME_JSON     = "application/json"
+	MIME_XML      = "application/xml"
+	MIME_MSGPACK  = "application/msgpack"
+	MIME_PROTOBUF = "application/x-protobuf"
+)
+
+// ALL_FORMATS can be negotiated by endpoints whose payloads all have a
+// message in api/product.proto; TEXT_AND_MSGPACK_FORMATS by the others.
+var (
+	ALL_FORMATS              = []string{MIME_JSON, MIME_XML, MIME_MSGPACK, MIME_PROTOBUF}
+	TEXT_AND_MSGPACK_FORMATS = []string{MIME_JSON, MIME_XML, MIME_MSGPACK}
+)
+
+// mediaTypeAliases maps the other names clients use for our formats.
+var mediaTypeAliases = map[string]string{
+	"text/xml":                        MIME_XML,
+	"application/x-msgpack":           MIME_MSGPACK,
+	"application/vnd.msgpack":         MIME_MSGPACK,
+	"application/protobuf":            MIME_PROTOBUF,
+	"application/vnd.google.protobuf": MIME_PROTOBUF,
+}
+
+const formatContextKey = "responseFormat"
+
+var errUnsupportedMediaType = errors.New("Unsupported request content type")
+
+// negotiate picks the response format from the Accept header among formats
+// before the handler runs, so an unacceptable request is answered with 406
+// without side effects. respond then encodes with the chosen format.
+func negotiate(formats ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			format, ok := acceptedFormat(c.Request().Header.Get(echo.HeaderAccept), formats)
+
+			if !ok {
+				return c.JSON(http.StatusNotAcceptable, response.ErrorResponse{
+					ErrorDescription: "Acceptable types are " + strings.Join(formats, ", "),
+				})
+			}
+
+			c.Set(formatContextKey, format)
+
+			return next(c)
+		}
+	}
+}
+
+type acceptedMediaType struct {
+	mediaType string
+	quality   float64
+}
+
+// acceptedFormat returns the supported format with the highest quality in
+// the Accept header. A missing header or a wildcard means JSON.
+func acceptedFormat(accept string, formats []string) (string, bool) {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return MIME_JSON, true
+	}
+
+	var mediaTypes []acceptedMediaType
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		if alias, found := mediaTypeAliases[mediaType]; found {
+			mediaType = alias
+		}
+
+		mediaTypes = append(mediaTypes, acceptedMediaType{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(mediaTypes, func(i, j int) bool {
+		return mediaTypes[i].quality > mediaTypes[j].quality
+	})
+
+	for _, accepted := range mediaTypes {
+		if accepted.quality <= 0 {
+			continue
+		}
+
+		if accepted.mediaType == "*/*" || accepted.mediaType == "application/*" {
+			return MIME_JSON, true
+		}
+
+		for _, format := range formats {
+			if accepted.mediaType == format {
+				return format, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// respond encodes value in the format chosen by negotiate, JSON when the
+// route is not negotiated.
+func respond(c echo.Context, status int, value interface{}) error {
+	format, _ := c.Get(formatContextKey).(string)
+
+	switch format {
+	case MIME_XML:
+		return c.XML(status, xmlRoot(value))
+	case MIME_MSGPACK:
+		encoded, err := marshalMsgpack(value)
+		if err != nil {
+			return err
+		}
+		return c.Blob(status, MIME_MSGPACK, encoded)
+	case MIME_PROTOBUF:
+		if productResponses, ok := value.([]response.ProductResponse); ok {
+			value = response.ProductResponseList(productResponses)
+		}
+		protoMarshaler, ok := value.(codec.ProtoMarshaler)
+		if !ok {
+			return c.JSON(http.StatusNotAcceptable, response.ErrorResponse{ErrorDescription: "Response has no protobuf message"})
+		}
+		encoded, err := protoMarshaler.MarshalProto()
+		if err != nil {
+			return err
+		}
+		return c.Blob(status, MIME_PROTOBUF, encoded)
+	default:
+		return c.JSON(status, value)
+	}
+}
+
+// xmlList gives slices the root element a well formed XML document needs.
+type xmlList struct {
+	XMLName xml.Name      `xml:"list"`
+	Items   []interface{} `xml:"item"`
+}
+
+func xmlRoot(value interface{}) interface{} {
+	reflected := reflect.ValueOf(value)
+
+	if reflected.Kind() != reflect.Slice {
+		return value
+	}
+
+	list := xmlList{Items: make([]interface{}, 0, reflected.Len())}
+	for i := 0; i < reflected.Len(); i++ {
+		list.Items = append(list.Items, reflected.Index(i).Interface())
+	}
+
+	return list
+}
+
+// bind decodes the request body according to its Content-Type. It returns
+// errUnsupportedMediaType for formats the body type can not be read from.
+func bind(c echo.Context, value interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+
+	if alias, found := mediaTypeAliases[mediaType]; found {
+		mediaType = alias
+	}
+
+	switch mediaType {
+	case "", MIME_JSON:
+		return c.Bind(value)
+	case MIME_XML:
+		return xml.NewDecoder(c.Request().Body).Decode(value)
+	case MIME_MSGPACK:
+		decoder := msgpack.NewDecoder(c.Request().Body)
+		decoder.SetCustomStructTag("json")
+		return decoder.Decode(value)
+	case MIME_PROTOBUF:
+		protoUnmarshaler, ok := value.(codec.ProtoUnmarshaler)
+		if !ok {
+			return errUnsupportedMediaType
+		}
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return protoUnmarshaler.UnmarshalProto(body)
+	default:
+		return errUnsupportedMediaType
+	}
+}
+
+// bindError answers a failed bind with 415 for unsupported content types
+// and 400 for bodies that could not be decoded.
+func bindError(c echo.Context, err error) error {
+	if errors.Is(err, errUnsupportedMediaType) {
+		return respond(c, http.StatusUnsupportedMediaType, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+}
+
+func marshalMsgpack(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := msgpack.NewEncoder(&buffer)
+	encoder.SetCustomStructTag("json")
+
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type IProductCacheStats interface {
+	Stats() domain.ProductCacheStats
+}
+
+type ProductCacheController struct {
+	productCache IProductCacheStats
+}
+
+func NewProductCacheController(productCache IProductCacheStats) *ProductCacheController {
+	return &ProductCacheController{
+		productCache: productCache,
+	}
+}
+
+func (productCacheController *ProductCacheController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/cache/products", productCacheController.GetStats)
+}
+
+func (productCacheController *ProductCacheController) GetStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, response.ToProductCacheStatsResponse(productCacheController.productCache.Stats()))
+}
@@ -1,99 +1,413 @@
 package controller
 
 import (
+	"errors"
 	"example.com/product-api/controller/request"
 	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
 	"example.com/product-api/service"
+	"fmt"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type ProductController struct {
-	productService service.IProductService
+	productService     service.IProductService
+	variantService     service.IVariantService
+	idempotencyService service.IIdempotencyService
+	cachePolicies      map[string]string
 }
 
-func NewProductController(productService service.IProductService) *ProductController {
+// NewProductController takes the Cache-Control value of the GET routes keyed
+// by route path; routes without one get no Cache-Control header.
+func NewProductController(productService service.IProductService, variantService service.IVariantService,
+	idempotencyService service.IIdempotencyService, cachePolicies map[string]string) *ProductController {
 	return &ProductController{
-		productService: productService,
+		productService:     productService,
+		variantService:     variantService,
+		idempotencyService: idempotencyService,
+		cachePolicies:      cachePolicies,
 	}
 }
 
+// RegisterRoutes negotiates JSON, XML, MessagePack and, where api/product.proto
+// has a message for the payload, Protobuf from the Accept header. Creating
+// and bulk requests may carry an Idempotency-Key.
 func (productController *ProductController) RegisterRoutes(e *echo.Echo) {
-	e.GET("/api/products", productController.GetAll)
-	e.GET("/api/products/:id", productController.GetById)
-	e.POST("/api/products", productController.Add)
-	e.PUT("/api/products/:id", productController.UpdatePrice)
-	e.DELETE("/api/products/:id", productController.Delete)
+	cache := httpCache(productController.cachePolicies)
+	idempotency := idempotent(productController.idempotencyService)
+
+	e.GET("/api/products", productController.GetAll, negotiate(ALL_FORMATS...), cache)
+	e.GET("/api/products/search", productController.Search, negotiate(TEXT_AND_MSGPACK_FORMATS...), cache)
+	e.GET("/api/products/facets", productController.GetFacets, negotiate(TEXT_AND_MSGPACK_FORMATS...), cache)
+	e.GET("/api/products/trash", productController.GetTrash, negotiate(TEXT_AND_MSGPACK_FORMATS...), cache)
+	e.GET("/api/products/duplicates", productController.GetDuplicates, negotiate(TEXT_AND_MSGPACK_FORMATS...), cache)
+	e.GET("/api/products/export", productController.Export)
+	e.GET("/api/products/:id", productController.GetById, negotiate(ALL_FORMATS...), cache)
+	e.POST("/api/products", productController.Add, negotiate(ALL_FORMATS...), idempotency)
+	e.POST("/api/products/bulk", productController.Bulk, negotiate(TEXT_AND_MSGPACK_FORMATS...), idempotency)
+	e.PUT("/api/products/by-ref", productController.SyncByRef, negotiate(TEXT_AND_MSGPACK_FORMATS...))
+	e.PUT("/api/products/by-ref/:ref", productController.UpsertByRef, negotiate(TEXT_AND_MSGPACK_FORMATS...))
+	e.PUT("/api/products/:id", productController.UpdatePrice, negotiate(ALL_FORMATS...))
+	e.DELETE("/api/products/:id", productController.Delete, negotiate(ALL_FORMATS...))
+	e.POST("/api/products/:id/restore", productController.Restore, negotiate(ALL_FORMATS...))
 }
 
+// GetAll answers If-None-Match with 304 when no listed product changed. The
+// list carries no Last-Modified: a product leaving it does not make any of
+// the remaining ones newer.
 func (productController *ProductController) GetAll(c echo.Context) error {
 	store := c.QueryParam("store")
+	var products []domain.Product
+
+	switch {
+	case hasExtendedProductFilter(c):
+		filter, err := productFilterFromQuery(c)
+
+		if err != nil {
+			return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+		}
+
+		products = productController.productService.GetAllByFilter(filter)
+	case len(store) == 0:
+		products = productController.productService.GetAll()
+	default:
+		products = productController.productService.GetAllByStore(store)
+	}
+
+	if productController.hasValidators(c) && notModified(c, productETag(c, products), time.Time{}) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return respond(c, http.StatusOK, productController.toProductResponseList(c, products))
+}
+
+func (productController *ProductController) Search(c echo.Context) error {
+	filter, err := productFilterFromQuery(c)
+
+	if err != nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	limit := 0
+
+	if limitParam := c.QueryParam("limit"); len(limitParam) != 0 {
+		limit, err = strconv.Atoi(limitParam)
+
+		if err != nil {
+			return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter limit must be a number"})
+		}
+	}
+
+	results, err := productController.productService.Search(c.QueryParam("q"), filter, limit)
+
+	if err != nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return respond(c, http.StatusOK, response.ToProductSearchResponseList(results))
+}
+
+func (productController *ProductController) GetFacets(c echo.Context) error {
+	filter, err := productFilterFromQuery(c)
+
+	if err != nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return respond(c, http.StatusOK, response.ToProductFacetsResponse(productController.productService.GetFacets(filter)))
+}
+
+// GetDuplicates reports the pairs of products in a store whose names are so
+// similar that they are likely the same product.
+func (productController *ProductController) GetDuplicates(c echo.Context) error {
+	threshold, limit := 0.0, 0
+	var err error
 
-	if len(store) == 0 {
-		return c.JSON(http.StatusOK, response.ToProductResponseList(productController.productService.GetAll()))
+	if thresholdParam := c.QueryParam("threshold"); len(thresholdParam) != 0 {
+		threshold, err = strconv.ParseFloat(thresholdParam, 32)
+
+		if err != nil {
+			return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter threshold must be a number"})
+		}
+	}
+
+	if limitParam := c.QueryParam("limit"); len(limitParam) != 0 {
+		limit, err = strconv.Atoi(limitParam)
+
+		if err != nil {
+			return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter limit must be a number"})
+		}
+	}
+
+	duplicates, err := productController.productService.FindDuplicates(c.QueryParam("store"), float32(threshold), limit)
+
+	if err != nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, response.ToProductResponseList(productController.productService.GetAllByStore(store)))
+	return respond(c, http.StatusOK, response.ToProductDuplicateResponseList(duplicates))
+}
+
+// exportFlushInterval is how many products are written between flushes of
+// the response, so clients start receiving data right away.
+const exportFlushInterval = 1000
+
+// Export streams the products matching the listing filters straight to the
+// response. The status is held back until the query produced its first row
+// or finished without any, so a failure to start the export is still a 500.
+// Once the first row is out the status can no longer change, so a failure
+// midway is only logged and the response ends early.
+func (productController *ProductController) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	contentType := response.ExportContentType(format)
+
+	if len(contentType) == 0 {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter format must be csv, ndjson or parquet"})
+	}
+
+	filter, err := productFilterFromQuery(c)
+
+	if err != nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	out := &exportResponseWriter{response: c.Response(), contentType: contentType, fileName: "products." + format}
+	exportWriter, err := response.NewProductExportWriter(format, out)
+
+	if err != nil {
+		log.Errorf("Error while starting product export %v", err)
+		return respond(c, http.StatusInternalServerError, response.ErrorResponse{ErrorDescription: "Error while exporting products"})
+	}
+
+	written := 0
+	err = productController.productService.Export(filter, func(product domain.Product) error {
+		if err := out.commit(); err != nil {
+			return err
+		}
+
+		if err := exportWriter.Write(product); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushInterval == 0 {
+			c.Response().Flush()
+		}
+
+		return nil
+	})
+
+	if err != nil && !out.committed {
+		log.Errorf("Error while exporting products %v", err)
+		return respond(c, http.StatusInternalServerError, response.ErrorResponse{ErrorDescription: "Error while exporting products"})
+	}
+
+	if err == nil {
+		err = out.commit()
+	}
+
+	if err == nil {
+		err = exportWriter.Close()
+	}
+
+	if err != nil {
+		log.Errorf("Error while exporting products %v", err)
+	}
+
+	return nil
+}
+
+// exportResponseWriter holds back what the export writer produces before the
+// first row, such as a csv header or parquet magic bytes, until commit sends
+// the headers of a successful export.
+type exportResponseWriter struct {
+	response    *echo.Response
+	contentType string
+	fileName    string
+	pending     []byte
+	committed   bool
+}
+
+func (exportResponseWriter *exportResponseWriter) Write(data []byte) (int, error) {
+	if !exportResponseWriter.committed {
+		exportResponseWriter.pending = append(exportResponseWriter.pending, data...)
+		return len(data), nil
+	}
+
+	return exportResponseWriter.response.Write(data)
+}
+
+func (exportResponseWriter *exportResponseWriter) commit() error {
+	if exportResponseWriter.committed {
+		return nil
+	}
+
+	exportResponseWriter.committed = true
+
+	header := exportResponseWriter.response.Header()
+	header.Set(echo.HeaderContentType, exportResponseWriter.contentType)
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, exportResponseWriter.fileName))
+	exportResponseWriter.response.WriteHeader(http.StatusOK)
+
+	_, err := exportResponseWriter.response.Write(exportResponseWriter.pending)
+	exportResponseWriter.pending = nil
+
+	return err
 }
 
 func (productController *ProductController) GetById(c echo.Context) error {
 	productId, err := strconv.Atoi(c.Param("id"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
 	}
 
 	product, err := productController.productService.GetById(int64(productId))
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+		return respond(c, http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if productController.hasValidators(c) && notModified(c, productETag(c, []domain.Product{product}), product.UpdatedAt) {
+		return c.NoContent(http.StatusNotModified)
 	}
 
-	return c.JSON(http.StatusOK, response.ToProductResponse(product))
+	return respond(c, http.StatusOK, productController.toProductResponseList(c, []domain.Product{product})[0])
 }
 
 func (productController *ProductController) Add(c echo.Context) error {
 	var addProductRequest request.AddProductRequest
-	err := c.Bind(&addProductRequest)
+	err := bind(c, &addProductRequest)
+
+	if err != nil {
+		return bindError(c, err)
+	}
+
+	_, err = productController.productService.Add(addProductRequest.ToModel())
+
+	if errors.Is(err, service.ErrDuplicateProduct) {
+		return respond(c, http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return respond(c, http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return respond(c, http.StatusCreated, addProductRequest)
+
+}
+
+// Bulk applies creates, updates and deletes in one request. The default
+// atomic mode applies all of them or none; bestEffort applies the valid ones
+// and reports the outcome of every item.
+func (productController *ProductController) Bulk(c echo.Context) error {
+	var bulkProductRequest request.BulkProductRequest
+	err := bind(c, &bulkProductRequest)
+
+	if err != nil {
+		return bindError(c, err)
+	}
+
+	if bulkProductRequest.Mode != "" && bulkProductRequest.Mode != request.BULK_MODE_ATOMIC && bulkProductRequest.Mode != request.BULK_MODE_BEST_EFFORT {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter mode must be atomic or bestEffort"})
+	}
+
+	results, err := productController.productService.ApplyBulk(bulkProductRequest.ToModel())
+
+	if err != nil && results == nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return respond(c, http.StatusUnprocessableEntity, response.ToBulkProductResponse(false, results))
+	}
+
+	return respond(c, http.StatusOK, response.ToBulkProductResponse(true, results))
+}
+
+// UpsertByRef creates or updates the product with the external reference in
+// the path, answering 201 when it created the product and 200 otherwise.
+func (productController *ProductController) UpsertByRef(c echo.Context) error {
+	var addProductRequest request.AddProductRequest
+	err := bind(c, &addProductRequest)
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+		return bindError(c, err)
 	}
 
-	err = productController.productService.Add(addProductRequest.ToModel())
+	productCreate := addProductRequest.ToModel()
+	productCreate.ExternalRef = pathParam(c, "ref")
+
+	upsert, err := productController.productService.UpsertByExternalRef(productCreate)
+
+	if errors.Is(err, service.ErrDuplicateProduct) {
+		return respond(c, http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
 
 	if err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+		return respond(c, http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
 	}
 
-	return c.JSON(http.StatusCreated, addProductRequest.ToModel())
+	if upsert.Outcome == domain.UPSERT_CREATED {
+		return respond(c, http.StatusCreated, response.ToProductUpsertResponse(upsert))
+	}
 
+	return respond(c, http.StatusOK, response.ToProductUpsertResponse(upsert))
+}
+
+// SyncByRef upserts a feed of products by their external references. In the
+// full mode the feed is the whole catalog and synced products missing from
+// it are discontinued; the default partial mode only upserts.
+func (productController *ProductController) SyncByRef(c echo.Context) error {
+	var syncProductRequest request.SyncProductRequest
+	err := bind(c, &syncProductRequest)
+
+	if err != nil {
+		return bindError(c, err)
+	}
+
+	if syncProductRequest.Mode != "" && syncProductRequest.Mode != request.SYNC_MODE_FULL && syncProductRequest.Mode != request.SYNC_MODE_PARTIAL {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "Parameter mode must be full or partial"})
+	}
+
+	report, err := productController.productService.SyncByExternalRef(syncProductRequest.ToModel())
+
+	if err != nil {
+		return respond(c, http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return respond(c, http.StatusOK, response.ToProductSyncResponse(report))
 }
 
 func (productController *ProductController) UpdatePrice(c echo.Context) error {
 	productId, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
 	}
 
 	newPrice := c.QueryParam("newPrice")
 	if len(newPrice) == 0 {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{
 			ErrorDescription: "Parameter newPrice is required!",
 		})
 	}
 
 	convertedPrice, err := strconv.ParseFloat(newPrice, 32)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{
 			ErrorDescription: "NewPrice Format Disrupted!",
 		})
 	}
 
 	err = productController.productService.UpdatePrice(int64(productId), float32(convertedPrice))
 	if err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+		return respond(c, http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
 	}
 
 	return c.NoContent(http.StatusOK)
@@ -103,14 +417,85 @@ func (productController *ProductController) Delete(c echo.Context) error {
 	productId, err := strconv.Atoi(c.Param("id"))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
 	}
 
 	err = productController.productService.DeleteById(int64(productId))
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+		return respond(c, http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
 	}
 
 	return c.NoContent(http.StatusOK)
 }
+
+func (productController *ProductController) GetTrash(c echo.Context) error {
+	return respond(c, http.StatusOK, response.ToDeletedProductResponseList(productController.productService.GetAllDeleted()))
+}
+
+func (productController *ProductController) Restore(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return respond(c, http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	err = productController.productService.Restore(int64(productId))
+
+	if errors.Is(err, service.ErrDuplicateProduct) {
+		return respond(c, http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return respond(c, http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (productController *ProductController) toProductResponseList(c echo.Context, products []domain.Product) []response.ProductResponse {
+	if !expands(c, "variants") {
+		return response.ToProductResponseList(products)
+	}
+
+	productIds := make([]int64, 0, len(products))
+	for _, product := range products {
+		productIds = append(productIds, product.Id)
+	}
+
+	return response.ToProductResponseListWithVariants(products, productController.variantService.GetAllByProductIds(productIds))
+}
+
+// hasValidators reports whether the product versions describe the whole
+// response. Variants have no version of their own, so responses that embed
+// them are sent without ETag and Last-Modified.
+func (productController *ProductController) hasValidators(c echo.Context) bool {
+	return !expands(c, "variants")
+}
+
+// expands reports whether the comma separated expand query parameter names
+// the given relation.
+func expands(c echo.Context, relation string) bool {
+	for _, expand := range strings.Split(c.QueryParam("expand"), ",") {
+		if strings.TrimSpace(expand) == relation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathParam unescapes a path parameter. Echo matches on the escaped path when
+// the request has one, as for references containing %2F, and leaves the
+// parameter escaped then.
+func pathParam(c echo.Context, name string) string {
+	if len(c.Request().URL.RawPath) == 0 {
+		return c.Param(name)
+	}
+
+	if value, err := url.PathUnescape(c.Param(name)); err == nil {
+		return value
+	}
+
+	return c.Param(name)
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sseHeartbeatInterval keeps idle streams from being closed by proxies.
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseRetryMillis tells EventSource clients how long to wait before
+// reconnecting after the stream ends.
+const sseRetryMillis = 3000
+
+type ProductEventController struct {
+	productEventBus service.IProductEventBus
+}
+
+func NewProductEventController(productEventBus service.IProductEventBus) *ProductEventController {
+	return &ProductEventController{
+		productEventBus: productEventBus,
+	}
+}
+
+func (productEventController *ProductEventController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/products/events", productEventController.Stream)
+}
+
+// productEventFilter keeps the events of one store and, when productIds is
+// not empty, of the listed products.
+type productEventFilter struct {
+	store      string
+	productIds map[int64]bool
+}
+
+func (filter productEventFilter) matches(event domain.ProductEvent) bool {
+	if len(filter.store) != 0 && !strings.EqualFold(event.Product.Store, domain.NormalizeStoreName(filter.store)) {
+		return false
+	}
+
+	return len(filter.productIds) == 0 || filter.productIds[event.Product.Id]
+}
+
+func productEventFilterFromQuery(c echo.Context) (productEventFilter, error) {
+	filter := productEventFilter{store: c.QueryParam("store"), productIds: map[int64]bool{}}
+
+	if products := c.QueryParam("product"); len(products) != 0 {
+		for _, product := range strings.Split(products, ",") {
+			productId, err := strconv.ParseInt(strings.TrimSpace(product), 10, 64)
+
+			if err != nil {
+				return productEventFilter{}, errors.New("Parameter product must be a list of product ids")
+			}
+
+			filter.productIds[productId] = true
+		}
+	}
+
+	return filter, nil
+}
+
+// lastEventId reads the Last-Event-ID header EventSource sends when it
+// reconnects, or the lastEventId parameter for the first connection.
+func lastEventId(c echo.Context) (uint64, error) {
+	value := c.Request().Header.Get("Last-Event-ID")
+
+	if len(value) == 0 {
+		value = c.QueryParam("lastEventId")
+	}
+
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+
+	if err != nil {
+		return 0, errors.New("Last event id must be an event id")
+	}
+
+	return id, nil
+}
+
+// Stream sends product events as Server-Sent Events until the client goes
+// away. When the client falls too far behind, the bus drops it and the
+// stream ends; EventSource reconnects with the id of the last event it got
+// and catches up from the replay buffer.
+func (productEventController *ProductEventController) Stream(c echo.Context) error {
+	filter, err := productEventFilterFromQuery(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	afterId, err := lastEventId(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	subscription := productEventController.productEventBus.Subscribe(afterId)
+	defer subscription.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "text/event-stream")
+	header.Set(echo.HeaderCacheControl, "no-cache")
+	header.Set(echo.HeaderConnection, "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Response().WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(c.Response(), "retry: %d\n\n", sseRetryMillis); err != nil {
+		return nil
+	}
+
+	for _, event := range subscription.Replay {
+		if err := writeProductEvent(c, filter, event); err != nil {
+			return nil
+		}
+	}
+
+	c.Response().Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Response(), ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case event, open := <-subscription.Events:
+			if !open {
+				return nil
+			}
+			if err := writeProductEvent(c, filter, event); err != nil {
+				return nil
+			}
+		}
+
+		c.Response().Flush()
+	}
+}
+
+func writeProductEvent(c echo.Context, filter productEventFilter, event domain.ProductEvent) error {
+	if !filter.matches(event) {
+		return nil
+	}
+
+	data, err := json.Marshal(response.ToProductEventResponse(event))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Response(), "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+
+	return err
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// extendedProductFilterParams are the listing parameters beyond the original
+// store filter. Any of them routes GetAll through the filter query.
+var extendedProductFilterParams = []string{"category", "includeDescendants", "inStock", "tags", "tagMatch"}
+
+func hasExtendedProductFilter(c echo.Context) bool {
+	for _, param := range extendedProductFilterParams {
+		if len(c.QueryParam(param)) != 0 {
+			return true
+		}
+	}
+
+	for param := range c.QueryParams() {
+		if strings.HasPrefix(param, "attr.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attributeFilterPattern matches attr.<key> and attr.<key>[<operator>].
+var attributeFilterPattern = regexp.MustCompile(`^attr\.([^\[\]]+)(?:\[([a-z]+)\])?$`)
+
+var attributeOperators = map[string]bool{
+	domain.ATTRIBUTE_EQ:     true,
+	domain.ATTRIBUTE_NE:     true,
+	domain.ATTRIBUTE_GT:     true,
+	domain.ATTRIBUTE_GTE:    true,
+	domain.ATTRIBUTE_LT:     true,
+	domain.ATTRIBUTE_LTE:    true,
+	domain.ATTRIBUTE_EXISTS: true,
+}
+
+func attributeConditionsFromQuery(c echo.Context) ([]domain.AttributeCondition, error) {
+	var conditions []domain.AttributeCondition
+
+	params := c.QueryParams()
+	keys := make([]string, 0, len(params))
+	for param := range params {
+		keys = append(keys, param)
+	}
+	sort.Strings(keys)
+
+	for _, param := range keys {
+		if !strings.HasPrefix(param, "attr.") {
+			continue
+		}
+
+		match := attributeFilterPattern.FindStringSubmatch(param)
+
+		if match == nil || !domain.IsAttributeKey(match[1]) {
+			return nil, errors.New(fmt.Sprintf("Parameter %s is not a valid attribute filter", param))
+		}
+
+		operator := match[2]
+		if len(operator) == 0 {
+			operator = domain.ATTRIBUTE_EQ
+		}
+
+		if !attributeOperators[operator] {
+			return nil, errors.New(fmt.Sprintf("Attribute filter operator %s is not supported", operator))
+		}
+
+		for _, value := range params[param] {
+			condition := domain.AttributeCondition{Key: match[1], Operator: operator, Value: value}
+
+			switch operator {
+			case domain.ATTRIBUTE_GT, domain.ATTRIBUTE_GTE, domain.ATTRIBUTE_LT, domain.ATTRIBUTE_LTE:
+				number, err := strconv.ParseFloat(value, 64)
+
+				if err != nil {
+					return nil, errors.New(fmt.Sprintf("Parameter %s must be a number", param))
+				}
+
+				condition.Number = number
+			}
+
+			conditions = append(conditions, condition)
+		}
+	}
+
+	return conditions, nil
+}
+
+func productFilterFromQuery(c echo.Context) (domain.ProductFilter, error) {
+	filter := domain.ProductFilter{
+		Store: c.QueryParam("store"),
+	}
+
+	if category := c.QueryParam("category"); len(category) != 0 {
+		categoryId, err := strconv.ParseInt(category, 10, 64)
+
+		if err != nil {
+			return domain.ProductFilter{}, errors.New("Parameter category must be a category id")
+		}
+
+		filter.CategoryId = categoryId
+	}
+
+	if includeDescendants := c.QueryParam("includeDescendants"); len(includeDescendants) != 0 {
+		value, err := strconv.ParseBool(includeDescendants)
+
+		if err != nil {
+			return domain.ProductFilter{}, errors.New("Parameter includeDescendants must be true or false")
+		}
+
+		filter.IncludeDescendants = value
+	}
+
+	if inStock := c.QueryParam("inStock"); len(inStock) != 0 {
+		value, err := strconv.ParseBool(inStock)
+
+		if err != nil {
+			return domain.ProductFilter{}, errors.New("Parameter inStock must be true or false")
+		}
+
+		filter.InStock = value
+	}
+
+	if tags := c.QueryParam("tags"); len(tags) != 0 {
+		normalizedTags, err := service.NormalizeTags(strings.Split(tags, ","))
+
+		if err != nil {
+			return domain.ProductFilter{}, err
+		}
+
+		filter.Tags = normalizedTags
+	}
+
+	switch c.QueryParam("tagMatch") {
+	case "", "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return domain.ProductFilter{}, errors.New("Parameter tagMatch must be any or all")
+	}
+
+	attributes, err := attributeConditionsFromQuery(c)
+
+	if err != nil {
+		return domain.ProductFilter{}, err
+	}
+
+	filter.Attributes = attributes
+
+	return filter, nil
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"errors"
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type PromotionController struct {
+	promotionService service.IPromotionService
+}
+
+func NewPromotionController(promotionService service.IPromotionService) *PromotionController {
+	return &PromotionController{
+		promotionService: promotionService,
+	}
+}
+
+func (promotionController *PromotionController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/promotions", promotionController.GetAll)
+	e.GET("/api/promotions/:id", promotionController.GetById)
+	e.POST("/api/promotions", promotionController.Add)
+	e.PUT("/api/promotions/:id", promotionController.Update)
+	e.DELETE("/api/promotions/:id", promotionController.Delete)
+	e.POST("/api/products/:id/quote", promotionController.Quote)
+	e.POST("/api/products/:id/redeem", promotionController.Redeem)
+}
+
+func (promotionController *PromotionController) GetAll(c echo.Context) error {
+	return c.JSON(http.StatusOK, response.ToPromotionResponseList(promotionController.promotionService.GetAll()))
+}
+
+func (promotionController *PromotionController) GetById(c echo.Context) error {
+	promotionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	promotion, err := promotionController.promotionService.GetById(int64(promotionId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToPromotionResponse(promotion))
+}
+
+func (promotionController *PromotionController) Add(c echo.Context) error {
+	var addPromotionRequest request.AddPromotionRequest
+	err := c.Bind(&addPromotionRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = promotionController.promotionService.Add(addPromotionRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, addPromotionRequest.ToModel())
+}
+
+func (promotionController *PromotionController) Update(c echo.Context) error {
+	promotionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var addPromotionRequest request.AddPromotionRequest
+	err = c.Bind(&addPromotionRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = promotionController.promotionService.Update(int64(promotionId), addPromotionRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (promotionController *PromotionController) Delete(c echo.Context) error {
+	promotionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	err = promotionController.promotionService.DeleteById(int64(promotionId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (promotionController *PromotionController) Quote(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	quote, err := promotionController.promotionService.Quote(int64(productId), c.QueryParam("coupon"))
+
+	var couponRefusedError *service.CouponRefusedError
+	if errors.As(err, &couponRefusedError) {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToQuoteResponse(quote))
+}
+
+func (promotionController *PromotionController) Redeem(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	coupon := c.QueryParam("coupon")
+
+	if len(coupon) == 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter a coupon"})
+	}
+
+	quote, err := promotionController.promotionService.Redeem(int64(productId), coupon)
+
+	var couponRefusedError *service.CouponRefusedError
+	if errors.As(err, &couponRefusedError) {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToQuoteResponse(quote))
+}
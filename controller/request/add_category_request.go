@@ -0,0 +1,20 @@
+package request
+
+import (
+	"encoding/json"
+	"example.com/product-api/service/dto"
+)
+
+type AddCategoryRequest struct {
+	Name            string          `json:"name"`
+	ParentId        int64           `json:"parentId"`
+	AttributeSchema json.RawMessage `json:"attributeSchema"`
+}
+
+func (addCategoryRequest *AddCategoryRequest) ToModel() dto.CategoryCreate {
+	return dto.CategoryCreate{
+		Name:            addCategoryRequest.Name,
+		ParentId:        addCategoryRequest.ParentId,
+		AttributeSchema: addCategoryRequest.AttributeSchema,
+	}
+}
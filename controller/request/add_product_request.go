@@ -1,19 +1,26 @@
 package request
 
-import "example.com/product-api/service/dto"
+import (
+	"example.com/product-api/controller/codec"
+	"example.com/product-api/service/dto"
+)
 
 type AddProductRequest struct {
-	Name     string  `json:"name"`
-	Price    float32 `json:"price"`
-	Discount float32 `json:"discount"`
-	Store    string  `json:"store"`
+	Name       string           `json:"name" xml:"name"`
+	Price      float32          `json:"price" xml:"price"`
+	Discount   float32          `json:"discount" xml:"discount"`
+	StoreId    int64            `json:"storeId" xml:"storeId"`
+	Store      string           `json:"store" xml:"store"`
+	Attributes codec.Attributes `json:"attributes" xml:"attributes,omitempty"`
 }
 
 func (addProductRequest *AddProductRequest) ToModel() dto.ProductCreate {
 	return dto.ProductCreate{
-		Name:     addProductRequest.Name,
-		Price:    addProductRequest.Price,
-		Discount: addProductRequest.Discount,
-		Store:    addProductRequest.Store,
+		Name:       addProductRequest.Name,
+		Price:      addProductRequest.Price,
+		Discount:   addProductRequest.Discount,
+		StoreId:    addProductRequest.StoreId,
+		Store:      addProductRequest.Store,
+		Attributes: addProductRequest.Attributes,
 	}
 }
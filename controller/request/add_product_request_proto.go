@@ -0,0 +1,47 @@
+package request
+
+import (
+	"example.com/product-api/api/productpb"
+	"example.com/product-api/controller/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnmarshalProto decodes the AddProductRequest message of api/product.proto.
+// Unknown fields are skipped.
+func (addProductRequest *AddProductRequest) UnmarshalProto(data []byte) error {
+	var message productpb.AddProductRequest
+
+	if err := proto.Unmarshal(data, &message); err != nil {
+		return err
+	}
+
+	*addProductRequest = AddProductRequest{
+		Name:       message.Name,
+		Price:      message.Price,
+		Discount:   message.Discount,
+		StoreId:    message.StoreId,
+		Store:      message.Store,
+		Attributes: codec.StructAsMap(message.Attributes),
+	}
+
+	return nil
+}
+
+// MarshalProto encodes the request back into the same message, which is
+// what POST /api/products echoes on success.
+func (addProductRequest AddProductRequest) MarshalProto() ([]byte, error) {
+	attributes, err := codec.NewStruct(addProductRequest.Attributes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Marshal(&productpb.AddProductRequest{
+		Name:       addProductRequest.Name,
+		Price:      addProductRequest.Price,
+		Discount:   addProductRequest.Discount,
+		StoreId:    addProductRequest.StoreId,
+		Store:      addProductRequest.Store,
+		Attributes: attributes,
+	})
+}
@@ -0,0 +1,30 @@
+package request
+
+import (
+	"example.com/product-api/service/dto"
+	"time"
+)
+
+type AddPromotionRequest struct {
+	Code         string    `json:"code"`
+	ProductIds   []int64   `json:"productIds"`
+	StoreIds     []int64   `json:"storeIds"`
+	DiscountType string    `json:"discountType"`
+	Value        float32   `json:"value"`
+	ValidFrom    time.Time `json:"validFrom"`
+	ValidUntil   time.Time `json:"validUntil"`
+	UsageLimit   int32     `json:"usageLimit"`
+}
+
+func (addPromotionRequest *AddPromotionRequest) ToModel() dto.PromotionCreate {
+	return dto.PromotionCreate{
+		Code:         addPromotionRequest.Code,
+		ProductIds:   addPromotionRequest.ProductIds,
+		StoreIds:     addPromotionRequest.StoreIds,
+		DiscountType: addPromotionRequest.DiscountType,
+		Value:        addPromotionRequest.Value,
+		ValidFrom:    addPromotionRequest.ValidFrom,
+		ValidUntil:   addPromotionRequest.ValidUntil,
+		UsageLimit:   addPromotionRequest.UsageLimit,
+	}
+}
@@ -0,0 +1,13 @@
+package request
+
+import "example.com/product-api/service/dto"
+
+type AddStoreRequest struct {
+	Name string `json:"name"`
+}
+
+func (addStoreRequest *AddStoreRequest) ToModel() dto.StoreCreate {
+	return dto.StoreCreate{
+		Name: addStoreRequest.Name,
+	}
+}
@@ -0,0 +1,19 @@
+package request
+
+import "example.com/product-api/service/dto"
+
+type AddVariantRequest struct {
+	Sku           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+	PriceOverride *float32          `json:"priceOverride"`
+	Barcode       string            `json:"barcode"`
+}
+
+func (addVariantRequest *AddVariantRequest) ToModel() dto.VariantCreate {
+	return dto.VariantCreate{
+		Sku:           addVariantRequest.Sku,
+		Attributes:    addVariantRequest.Attributes,
+		PriceOverride: addVariantRequest.PriceOverride,
+		Barcode:       addVariantRequest.Barcode,
+	}
+}
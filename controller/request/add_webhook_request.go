@@ -0,0 +1,19 @@
+package request
+
+import "example.com/product-api/service/dto"
+
+type AddWebhookRequest struct {
+	Url        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	StoreId    int64    `json:"storeId"`
+	Secret     string   `json:"secret"`
+}
+
+func (addWebhookRequest *AddWebhookRequest) ToModel() dto.WebhookCreate {
+	return dto.WebhookCreate{
+		Url:        addWebhookRequest.Url,
+		EventTypes: addWebhookRequest.EventTypes,
+		StoreId:    addWebhookRequest.StoreId,
+		Secret:     addWebhookRequest.Secret,
+	}
+}
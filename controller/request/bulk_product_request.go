@@ -0,0 +1,37 @@
+package request
+
+import "example.com/product-api/service/dto"
+
+const (
+	BULK_MODE_ATOMIC      = "atomic"
+	BULK_MODE_BEST_EFFORT = "bestEffort"
+)
+
+type BulkProductRequest struct {
+	Mode    string               `json:"mode" xml:"mode"`
+	Creates []AddProductRequest  `json:"creates" xml:"creates>product"`
+	Updates []UpdatePriceRequest `json:"updates" xml:"updates>update"`
+	Deletes []int64              `json:"deletes" xml:"deletes>id"`
+}
+
+type UpdatePriceRequest struct {
+	Id    int64   `json:"id" xml:"id"`
+	Price float32 `json:"price" xml:"price"`
+}
+
+func (bulkProductRequest *BulkProductRequest) ToModel() dto.ProductBulk {
+	productBulk := dto.ProductBulk{
+		Atomic:  bulkProductRequest.Mode != BULK_MODE_BEST_EFFORT,
+		Deletes: bulkProductRequest.Deletes,
+	}
+
+	for _, addProductRequest := range bulkProductRequest.Creates {
+		productBulk.Creates = append(productBulk.Creates, addProductRequest.ToModel())
+	}
+
+	for _, updatePriceRequest := range bulkProductRequest.Updates {
+		productBulk.Updates = append(productBulk.Updates, dto.ProductPriceUpdate{Id: updatePriceRequest.Id, Price: updatePriceRequest.Price})
+	}
+
+	return productBulk
+}
@@ -0,0 +1,5 @@
+package request
+
+type InventoryQuantityRequest struct {
+	Quantity int32 `json:"quantity"`
+}
@@ -0,0 +1,30 @@
+package request
+
+import "example.com/product-api/service/dto"
+
+const (
+	SYNC_MODE_FULL    = "full"
+	SYNC_MODE_PARTIAL = "partial"
+)
+
+type SyncProductRequest struct {
+	Mode     string                 `json:"mode" xml:"mode"`
+	Products []SyncedProductRequest `json:"products" xml:"products>product"`
+}
+
+type SyncedProductRequest struct {
+	ExternalRef string `json:"externalRef" xml:"externalRef"`
+	AddProductRequest
+}
+
+func (syncProductRequest *SyncProductRequest) ToModel() dto.ProductSync {
+	productSync := dto.ProductSync{Full: syncProductRequest.Mode == SYNC_MODE_FULL}
+
+	for _, syncedProductRequest := range syncProductRequest.Products {
+		productCreate := syncedProductRequest.ToModel()
+		productCreate.ExternalRef = syncedProductRequest.ExternalRef
+		productSync.Products = append(productSync.Products, productCreate)
+	}
+
+	return productSync
+}
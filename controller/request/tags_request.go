@@ -0,0 +1,5 @@
+package request
+
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
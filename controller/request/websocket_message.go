@@ -0,0 +1,13 @@
+package request
+
+const (
+	WS_SUBSCRIBE   = "subscribe"
+	WS_UNSUBSCRIBE = "unsubscribe"
+)
+
+// WebsocketMessage is sent by /ws clients to change what they receive.
+type WebsocketMessage struct {
+	Action     string   `json:"action"`
+	ProductIds []int64  `json:"productIds"`
+	Stores     []string `json:"stores"`
+}
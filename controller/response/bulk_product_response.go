@@ -0,0 +1,30 @@
+package response
+
+import "example.com/product-api/domain"
+
+type BulkProductResponse struct {
+	Applied bool                 `json:"applied" xml:"applied"`
+	Results []BulkResultResponse `json:"results" xml:"results>result"`
+}
+
+type BulkResultResponse struct {
+	Action string `json:"action" xml:"action"`
+	Index  int    `json:"index" xml:"index"`
+	Id     int64  `json:"id,omitempty" xml:"id,omitempty"`
+	Error  string `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+func ToBulkProductResponse(applied bool, results []domain.BulkResult) BulkProductResponse {
+	bulkProductResponse := BulkProductResponse{Applied: applied, Results: []BulkResultResponse{}}
+
+	for _, result := range results {
+		bulkProductResponse.Results = append(bulkProductResponse.Results, BulkResultResponse{
+			Action: result.Action,
+			Index:  result.Index,
+			Id:     result.Id,
+			Error:  result.Error,
+		})
+	}
+
+	return bulkProductResponse
+}
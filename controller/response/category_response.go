@@ -0,0 +1,32 @@
+package response
+
+import (
+	"encoding/json"
+	"example.com/product-api/domain"
+)
+
+type CategoryResponse struct {
+	Id              int64           `json:"id"`
+	Name            string          `json:"name"`
+	ParentId        int64           `json:"parentId,omitempty"`
+	AttributeSchema json.RawMessage `json:"attributeSchema,omitempty"`
+}
+
+func ToCategoryResponse(category domain.Category) CategoryResponse {
+	return CategoryResponse{
+		Id:              category.Id,
+		Name:            category.Name,
+		ParentId:        category.ParentId,
+		AttributeSchema: category.AttributeSchema,
+	}
+}
+
+func ToCategoryResponseList(categories []domain.Category) []CategoryResponse {
+	var categoryResponses = []CategoryResponse{}
+
+	for _, category := range categories {
+		categoryResponses = append(categoryResponses, ToCategoryResponse(category))
+	}
+
+	return categoryResponses
+}
@@ -0,0 +1,26 @@
+package response
+
+import (
+	"example.com/product-api/domain"
+	"time"
+)
+
+type DeletedProductResponse struct {
+	Id int64 `json:"id" xml:"id"`
+	ProductResponse
+	DeletedAt time.Time `json:"deletedAt" xml:"deletedAt"`
+}
+
+func ToDeletedProductResponseList(deletedProducts []domain.DeletedProduct) []DeletedProductResponse {
+	var deletedProductResponses = []DeletedProductResponse{}
+
+	for _, deletedProduct := range deletedProducts {
+		deletedProductResponses = append(deletedProductResponses, DeletedProductResponse{
+			Id:              deletedProduct.Id,
+			ProductResponse: ToProductResponse(deletedProduct.Product),
+			DeletedAt:       deletedProduct.DeletedAt,
+		})
+	}
+
+	return deletedProductResponses
+}
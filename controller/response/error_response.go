@@ -1,5 +1,5 @@
 package response
 
 type ErrorResponse struct {
-	ErrorDescription string `json:"errorDescription"`
+	ErrorDescription string `json:"errorDescription" xml:"errorDescription"`
 }
@@ -0,0 +1,60 @@
+package response
+
+import (
+	"encoding/csv"
+	"example.com/product-api/domain"
+	"io"
+	"strconv"
+)
+
+type ImportReportResponse struct {
+	DryRun   bool                     `json:"dryRun"`
+	Rows     int                      `json:"rows"`
+	Imported int                      `json:"imported"`
+	Failed   int                      `json:"failed"`
+	Errors   []ImportRowErrorResponse `json:"errors"`
+}
+
+type ImportRowErrorResponse struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+func ToImportReportResponse(report domain.ImportReport) ImportReportResponse {
+	reportResponse := ImportReportResponse{
+		DryRun:   report.DryRun,
+		Rows:     report.Rows,
+		Imported: report.Imported,
+		Failed:   len(report.Errors),
+		Errors:   []ImportRowErrorResponse{},
+	}
+
+	for _, rowError := range report.Errors {
+		reportResponse.Errors = append(reportResponse.Errors, ImportRowErrorResponse{Row: rowError.Row, Error: rowError.Error})
+	}
+
+	return reportResponse
+}
+
+// WriteImportErrorReport writes the failed rows as CSV so partners can fix
+// their spreadsheet line by line.
+func WriteImportErrorReport(writer io.Writer, report domain.ImportReport) error {
+	csvWriter := csv.NewWriter(writer)
+
+	err := csvWriter.Write([]string{"row", "error"})
+
+	for _, rowError := range report.Errors {
+		if err != nil {
+			return err
+		}
+		err = csvWriter.Write([]string{strconv.Itoa(rowError.Row), rowError.Error})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
@@ -0,0 +1,33 @@
+package response
+
+import "example.com/product-api/domain"
+
+type InventoryResponse struct {
+	ProductId int64  `json:"productId"`
+	StoreId   int64  `json:"storeId"`
+	Store     string `json:"store"`
+	OnHand    int32  `json:"onHand"`
+	Reserved  int32  `json:"reserved"`
+	Available int32  `json:"available"`
+}
+
+func ToInventoryResponse(inventory domain.Inventory) InventoryResponse {
+	return InventoryResponse{
+		ProductId: inventory.ProductId,
+		StoreId:   inventory.StoreId,
+		Store:     inventory.Store,
+		OnHand:    inventory.OnHand,
+		Reserved:  inventory.Reserved,
+		Available: inventory.Available(),
+	}
+}
+
+func ToInventoryResponseList(inventories []domain.Inventory) []InventoryResponse {
+	var inventoryResponses = []InventoryResponse{}
+
+	for _, inventory := range inventories {
+		inventoryResponses = append(inventoryResponses, ToInventoryResponse(inventory))
+	}
+
+	return inventoryResponses
+}
@@ -0,0 +1,31 @@
+package response
+
+import "example.com/product-api/domain"
+
+type ProductCacheStatsResponse struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRatio      float64 `json:"hitRatio"`
+	Invalidations int64   `json:"invalidations"`
+	Products      int     `json:"products"`
+	NotFound      int     `json:"notFound"`
+	Stores        int     `json:"stores"`
+}
+
+func ToProductCacheStatsResponse(stats domain.ProductCacheStats) ProductCacheStatsResponse {
+	hitRatio := 0.0
+
+	if lookups := stats.Hits + stats.Misses; lookups != 0 {
+		hitRatio = float64(stats.Hits) / float64(lookups)
+	}
+
+	return ProductCacheStatsResponse{
+		Hits:          stats.Hits,
+		Misses:        stats.Misses,
+		HitRatio:      hitRatio,
+		Invalidations: stats.Invalidations,
+		Products:      stats.Products,
+		NotFound:      stats.NotFound,
+		Stores:        stats.Stores,
+	}
+}
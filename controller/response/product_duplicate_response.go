@@ -0,0 +1,31 @@
+package response
+
+import "example.com/product-api/domain"
+
+type ProductDuplicateResponse struct {
+	StoreId       int64   `json:"storeId" xml:"storeId"`
+	Store         string  `json:"store" xml:"store"`
+	ProductId     int64   `json:"productId" xml:"productId"`
+	Name          string  `json:"name" xml:"name"`
+	DuplicateId   int64   `json:"duplicateId" xml:"duplicateId"`
+	DuplicateName string  `json:"duplicateName" xml:"duplicateName"`
+	Similarity    float32 `json:"similarity" xml:"similarity"`
+}
+
+func ToProductDuplicateResponseList(duplicates []domain.ProductDuplicate) []ProductDuplicateResponse {
+	var duplicateResponses = []ProductDuplicateResponse{}
+
+	for _, duplicate := range duplicates {
+		duplicateResponses = append(duplicateResponses, ProductDuplicateResponse{
+			StoreId:       duplicate.Product.StoreId,
+			Store:         duplicate.Product.Store,
+			ProductId:     duplicate.Product.Id,
+			Name:          duplicate.Product.Name,
+			DuplicateId:   duplicate.Duplicate.Id,
+			DuplicateName: duplicate.Duplicate.Name,
+			Similarity:    duplicate.Similarity,
+		})
+	}
+
+	return duplicateResponses
+}
@@ -0,0 +1,36 @@
+package response
+
+import (
+	"example.com/product-api/domain"
+	"time"
+)
+
+type ProductEventResponse struct {
+	Id            uint64                 `json:"id"`
+	Type          string                 `json:"type"`
+	ProductId     int64                  `json:"productId"`
+	StoreId       int64                  `json:"storeId"`
+	Store         string                 `json:"store"`
+	Name          string                 `json:"name,omitempty"`
+	Price         float32                `json:"price,omitempty"`
+	PreviousPrice float32                `json:"previousPrice,omitempty"`
+	Discount      float32                `json:"discount,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	OccurredAt    time.Time              `json:"occurredAt"`
+}
+
+func ToProductEventResponse(event domain.ProductEvent) ProductEventResponse {
+	return ProductEventResponse{
+		Id:            event.Id,
+		Type:          event.Type,
+		ProductId:     event.Product.Id,
+		StoreId:       event.Product.StoreId,
+		Store:         event.Product.Store,
+		Name:          event.Product.Name,
+		Price:         event.Product.Price,
+		PreviousPrice: event.PreviousPrice,
+		Discount:      event.Product.Discount,
+		Attributes:    event.Product.Attributes,
+		OccurredAt:    event.OccurredAt,
+	}
+}
@@ -0,0 +1,172 @@
+package response
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"example.com/product-api/domain"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"io"
+	"strconv"
+)
+
+const (
+	EXPORT_FORMAT_CSV     = "csv"
+	EXPORT_FORMAT_NDJSON  = "ndjson"
+	EXPORT_FORMAT_PARQUET = "parquet"
+)
+
+// parquetRowGroupSize bounds how much of the export the parquet writer keeps
+// in memory before flushing a row group.
+const parquetRowGroupSize = 8 * 1024 * 1024
+
+// ProductExportWriter encodes products one at a time. Close must be called
+// once after the last product to flush buffered output and any footer.
+type ProductExportWriter interface {
+	Write(product domain.Product) error
+	Close() error
+}
+
+type ProductExportRow struct {
+	Id         int64                  `json:"id"`
+	Name       string                 `json:"name"`
+	Price      float32                `json:"price"`
+	Discount   float32                `json:"discount"`
+	StoreId    int64                  `json:"storeId"`
+	Store      string                 `json:"store"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+type productExportParquetRow struct {
+	Id         int64   `parquet:"name=id, type=INT64"`
+	Name       string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Price      float32 `parquet:"name=price, type=FLOAT"`
+	Discount   float32 `parquet:"name=discount, type=FLOAT"`
+	StoreId    int64   `parquet:"name=store_id, type=INT64"`
+	Store      string  `parquet:"name=store, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Attributes string  `parquet:"name=attributes, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// ExportContentType returns the media type of the format, or an empty string
+// when the format is not supported.
+func ExportContentType(format string) string {
+	switch format {
+	case EXPORT_FORMAT_CSV:
+		return "text/csv; charset=UTF-8"
+	case EXPORT_FORMAT_NDJSON:
+		return "application/x-ndjson"
+	case EXPORT_FORMAT_PARQUET:
+		return "application/vnd.apache.parquet"
+	}
+	return ""
+}
+
+func NewProductExportWriter(format string, out io.Writer) (ProductExportWriter, error) {
+	switch format {
+	case EXPORT_FORMAT_CSV:
+		csvWriter := csv.NewWriter(out)
+		err := csvWriter.Write([]string{"id", "name", "price", "discount", "storeId", "store", "attributes"})
+		return &csvProductExportWriter{csvWriter: csvWriter}, err
+	case EXPORT_FORMAT_NDJSON:
+		return &ndjsonProductExportWriter{encoder: json.NewEncoder(out)}, nil
+	case EXPORT_FORMAT_PARQUET:
+		parquetWriter, err := writer.NewParquetWriterFromWriter(out, new(productExportParquetRow), 1)
+		if err != nil {
+			return nil, err
+		}
+		parquetWriter.RowGroupSize = parquetRowGroupSize
+		parquetWriter.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetProductExportWriter{parquetWriter: parquetWriter}, nil
+	}
+	return nil, errors.New("Parameter format must be csv, ndjson or parquet")
+}
+
+func ToProductExportRow(product domain.Product) ProductExportRow {
+	return ProductExportRow{
+		Id:         product.Id,
+		Name:       product.Name,
+		Price:      product.Price,
+		Discount:   product.Discount,
+		StoreId:    product.StoreId,
+		Store:      product.Store,
+		Attributes: product.Attributes,
+	}
+}
+
+type csvProductExportWriter struct {
+	csvWriter *csv.Writer
+}
+
+func (csvProductExportWriter *csvProductExportWriter) Write(product domain.Product) error {
+	attributes, err := exportAttributes(product)
+
+	if err != nil {
+		return err
+	}
+
+	return csvProductExportWriter.csvWriter.Write([]string{
+		strconv.FormatInt(product.Id, 10),
+		product.Name,
+		strconv.FormatFloat(float64(product.Price), 'f', -1, 32),
+		strconv.FormatFloat(float64(product.Discount), 'f', -1, 32),
+		strconv.FormatInt(product.StoreId, 10),
+		product.Store,
+		attributes,
+	})
+}
+
+func (csvProductExportWriter *csvProductExportWriter) Close() error {
+	csvProductExportWriter.csvWriter.Flush()
+	return csvProductExportWriter.csvWriter.Error()
+}
+
+type ndjsonProductExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (ndjsonProductExportWriter *ndjsonProductExportWriter) Write(product domain.Product) error {
+	return ndjsonProductExportWriter.encoder.Encode(ToProductExportRow(product))
+}
+
+func (ndjsonProductExportWriter *ndjsonProductExportWriter) Close() error {
+	return nil
+}
+
+type parquetProductExportWriter struct {
+	parquetWriter *writer.ParquetWriter
+}
+
+func (parquetProductExportWriter *parquetProductExportWriter) Write(product domain.Product) error {
+	attributes, err := exportAttributes(product)
+
+	if err != nil {
+		return err
+	}
+
+	return parquetProductExportWriter.parquetWriter.Write(productExportParquetRow{
+		Id:         product.Id,
+		Name:       product.Name,
+		Price:      product.Price,
+		Discount:   product.Discount,
+		StoreId:    product.StoreId,
+		Store:      product.Store,
+		Attributes: attributes,
+	})
+}
+
+func (parquetProductExportWriter *parquetProductExportWriter) Close() error {
+	return parquetProductExportWriter.parquetWriter.WriteStop()
+}
+
+// exportAttributes flattens the attributes into a JSON object, or an empty
+// string for products without attributes.
+func exportAttributes(product domain.Product) (string, error) {
+	if len(product.Attributes) == 0 {
+		return "", nil
+	}
+
+	attributes, err := json.Marshal(product.Attributes)
+
+	return string(attributes), err
+}
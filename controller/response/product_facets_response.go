@@ -0,0 +1,49 @@
+package response
+
+import "example.com/product-api/domain"
+
+type FacetCountResponse struct {
+	Value string `json:"value" xml:"value"`
+	Count int64  `json:"count" xml:"count"`
+}
+
+type PriceBucketResponse struct {
+	From  float32  `json:"from" xml:"from"`
+	To    *float32 `json:"to,omitempty" xml:"to,omitempty"`
+	Count int64    `json:"count" xml:"count"`
+}
+
+type ProductFacetsResponse struct {
+	Tags         []FacetCountResponse  `json:"tags" xml:"tags>tag"`
+	Stores       []FacetCountResponse  `json:"stores" xml:"stores>store"`
+	PriceBuckets []PriceBucketResponse `json:"priceBuckets" xml:"priceBuckets>priceBucket"`
+}
+
+func ToProductFacetsResponse(facets domain.ProductFacets) ProductFacetsResponse {
+	facetsResponse := ProductFacetsResponse{
+		Tags:         toFacetCountResponseList(facets.Tags),
+		Stores:       toFacetCountResponseList(facets.Stores),
+		PriceBuckets: []PriceBucketResponse{},
+	}
+
+	for _, priceBucket := range facets.PriceBuckets {
+		priceBucketResponse := PriceBucketResponse{From: priceBucket.From, Count: priceBucket.Count}
+		if priceBucket.To != 0 {
+			to := priceBucket.To
+			priceBucketResponse.To = &to
+		}
+		facetsResponse.PriceBuckets = append(facetsResponse.PriceBuckets, priceBucketResponse)
+	}
+
+	return facetsResponse
+}
+
+func toFacetCountResponseList(facetCounts []domain.FacetCount) []FacetCountResponse {
+	var facetCountResponses = []FacetCountResponse{}
+
+	for _, facetCount := range facetCounts {
+		facetCountResponses = append(facetCountResponses, FacetCountResponse{Value: facetCount.Value, Count: facetCount.Count})
+	}
+
+	return facetCountResponses
+}
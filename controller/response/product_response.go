@@ -1,20 +1,33 @@
 package response
 
-import "example.com/product-api/domain"
+import (
+	"example.com/product-api/controller/codec"
+	"example.com/product-api/domain"
+)
 
 type ProductResponse struct {
-	Name     string  `json:"name"`
-	Price    float32 `json:"price"`
-	Discount float32 `json:"discount"`
-	Store    string  `json:"store"`
+	Name         string           `json:"name" xml:"name"`
+	Price        float32          `json:"price" xml:"price"`
+	Discount     float32          `json:"discount" xml:"discount"`
+	StoreId      int64            `json:"storeId" xml:"storeId"`
+	Store        string           `json:"store" xml:"store"`
+	Attributes   codec.Attributes `json:"attributes,omitempty" xml:"attributes,omitempty"`
+	ExternalRef  string           `json:"externalRef,omitempty" xml:"externalRef,omitempty"`
+	Discontinued bool             `json:"discontinued,omitempty" xml:"discontinued,omitempty"`
+
+	Variants []VariantResponse `json:"variants,omitempty" xml:"variants>variant,omitempty"`
 }
 
 func ToProductResponse(product domain.Product) ProductResponse {
 	return ProductResponse{
-		Name:     product.Name,
-		Price:    product.Price,
-		Discount: product.Discount,
-		Store:    product.Store,
+		Name:         product.Name,
+		Price:        product.Price,
+		Discount:     product.Discount,
+		StoreId:      product.StoreId,
+		Store:        product.Store,
+		Attributes:   product.Attributes,
+		ExternalRef:  product.ExternalRef,
+		Discontinued: product.Discontinued,
 	}
 }
 
@@ -27,3 +40,23 @@ func ToProductResponseList(products []domain.Product) []ProductResponse {
 
 	return productResponses
 }
+
+// ToProductResponseListWithVariants embeds each product's variants, taken
+// from one batch load covering the whole list.
+func ToProductResponseListWithVariants(products []domain.Product, variants []domain.Variant) []ProductResponse {
+	variantsByProductId := map[int64][]VariantResponse{}
+
+	for _, variant := range variants {
+		variantsByProductId[variant.ProductId] = append(variantsByProductId[variant.ProductId], ToVariantResponse(variant))
+	}
+
+	var productResponses []ProductResponse
+
+	for _, product := range products {
+		productResponse := ToProductResponse(product)
+		productResponse.Variants = variantsByProductId[product.Id]
+		productResponses = append(productResponses, productResponse)
+	}
+
+	return productResponses
+}
@@ -0,0 +1,72 @@
+package response
+
+import (
+	"example.com/product-api/api/productpb"
+	"example.com/product-api/controller/codec"
+)
+
+// ProductResponseList is the ProductResponseList message of
+// api/product.proto; JSON and the other formats encode the plain slice.
+type ProductResponseList []ProductResponse
+
+// MarshalProto encodes the ProductResponse message of api/product.proto.
+func (productResponse ProductResponse) MarshalProto() ([]byte, error) {
+	message, err := productResponse.toProto()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Marshal(message)
+}
+
+func (productResponse ProductResponse) toProto() (*productpb.ProductResponse, error) {
+	attributes, err := codec.NewStruct(productResponse.Attributes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := &productpb.ProductResponse{
+		Name:         productResponse.Name,
+		Price:        productResponse.Price,
+		Discount:     productResponse.Discount,
+		StoreId:      productResponse.StoreId,
+		Store:        productResponse.Store,
+		Attributes:   attributes,
+		ExternalRef:  productResponse.ExternalRef,
+		Discontinued: productResponse.Discontinued,
+	}
+
+	for _, variantResponse := range productResponse.Variants {
+		message.Variants = append(message.Variants, &productpb.VariantResponse{
+			Id:            variantResponse.Id,
+			Sku:           variantResponse.Sku,
+			Attributes:    variantResponse.Attributes,
+			PriceOverride: variantResponse.PriceOverride,
+			Barcode:       variantResponse.Barcode,
+		})
+	}
+
+	return message, nil
+}
+
+func (productResponseList ProductResponseList) MarshalProto() ([]byte, error) {
+	message := &productpb.ProductResponseList{}
+
+	for _, productResponse := range productResponseList {
+		product, err := productResponse.toProto()
+
+		if err != nil {
+			return nil, err
+		}
+
+		message.Products = append(message.Products, product)
+	}
+
+	return codec.Marshal(message)
+}
+
+func (errorResponse ErrorResponse) MarshalProto() ([]byte, error) {
+	return codec.Marshal(&productpb.ErrorResponse{ErrorDescription: errorResponse.ErrorDescription})
+}
@@ -0,0 +1,23 @@
+package response
+
+import "example.com/product-api/domain"
+
+type ProductSearchResponse struct {
+	ProductResponse
+	Rank      float32 `json:"rank" xml:"rank"`
+	Highlight string  `json:"highlight" xml:"highlight"`
+}
+
+func ToProductSearchResponseList(results []domain.ProductSearchResult) []ProductSearchResponse {
+	var searchResponses = []ProductSearchResponse{}
+
+	for _, result := range results {
+		searchResponses = append(searchResponses, ProductSearchResponse{
+			ProductResponse: ToProductResponse(result.Product),
+			Rank:            result.Rank,
+			Highlight:       result.Highlight,
+		})
+	}
+
+	return searchResponses
+}
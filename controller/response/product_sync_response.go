@@ -0,0 +1,53 @@
+package response
+
+import "example.com/product-api/domain"
+
+type ProductUpsertResponse struct {
+	ProductResponse
+	Outcome string `json:"outcome" xml:"outcome"`
+}
+
+type ProductSyncItemResponse struct {
+	ExternalRef string `json:"externalRef" xml:"externalRef"`
+	Outcome     string `json:"outcome" xml:"outcome"`
+}
+
+type ProductSyncResponse struct {
+	Created          int                       `json:"created" xml:"created"`
+	Updated          int                       `json:"updated" xml:"updated"`
+	Unchanged        int                       `json:"unchanged" xml:"unchanged"`
+	Discontinued     int                       `json:"discontinued" xml:"discontinued"`
+	Products         []ProductSyncItemResponse `json:"products" xml:"products>product"`
+	DiscontinuedRefs []string                  `json:"discontinuedRefs" xml:"discontinuedRefs>externalRef"`
+}
+
+func ToProductUpsertResponse(upsert domain.ProductUpsert) ProductUpsertResponse {
+	return ProductUpsertResponse{ProductResponse: ToProductResponse(upsert.Product), Outcome: upsert.Outcome}
+}
+
+func ToProductSyncResponse(report domain.ProductSyncReport) ProductSyncResponse {
+	syncResponse := ProductSyncResponse{
+		Discontinued:     len(report.Discontinued),
+		Products:         []ProductSyncItemResponse{},
+		DiscontinuedRefs: []string{},
+	}
+
+	for _, upsert := range report.Upserts {
+		switch upsert.Outcome {
+		case domain.UPSERT_CREATED:
+			syncResponse.Created++
+		case domain.UPSERT_UPDATED:
+			syncResponse.Updated++
+		default:
+			syncResponse.Unchanged++
+		}
+
+		syncResponse.Products = append(syncResponse.Products, ProductSyncItemResponse{ExternalRef: upsert.Product.ExternalRef, Outcome: upsert.Outcome})
+	}
+
+	for _, product := range report.Discontinued {
+		syncResponse.DiscontinuedRefs = append(syncResponse.DiscontinuedRefs, product.ExternalRef)
+	}
+
+	return syncResponse
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	"example.com/product-api/domain"
+	"time"
+)
+
+type PromotionResponse struct {
+	Id           int64     `json:"id"`
+	Code         string    `json:"code"`
+	ProductIds   []int64   `json:"productIds"`
+	StoreIds     []int64   `json:"storeIds"`
+	DiscountType string    `json:"discountType"`
+	Value        float32   `json:"value"`
+	ValidFrom    time.Time `json:"validFrom"`
+	ValidUntil   time.Time `json:"validUntil"`
+	UsageLimit   int32     `json:"usageLimit"`
+	UsageCount   int32     `json:"usageCount"`
+}
+
+func ToPromotionResponse(promotion domain.Promotion) PromotionResponse {
+	return PromotionResponse{
+		Id:           promotion.Id,
+		Code:         promotion.Code,
+		ProductIds:   promotion.ProductIds,
+		StoreIds:     promotion.StoreIds,
+		DiscountType: promotion.DiscountType,
+		Value:        promotion.Value,
+		ValidFrom:    promotion.ValidFrom,
+		ValidUntil:   promotion.ValidUntil,
+		UsageLimit:   promotion.UsageLimit,
+		UsageCount:   promotion.UsageCount,
+	}
+}
+
+func ToPromotionResponseList(promotions []domain.Promotion) []PromotionResponse {
+	var promotionResponses = []PromotionResponse{}
+
+	for _, promotion := range promotions {
+		promotionResponses = append(promotionResponses, ToPromotionResponse(promotion))
+	}
+
+	return promotionResponses
+}
@@ -0,0 +1,23 @@
+package response
+
+import "example.com/product-api/service/dto"
+
+type QuoteResponse struct {
+	ProductId      int64   `json:"productId"`
+	Price          float32 `json:"price"`
+	Discount       float32 `json:"discount"`
+	CouponCode     string  `json:"couponCode,omitempty"`
+	CouponDiscount float32 `json:"couponDiscount"`
+	EffectivePrice float32 `json:"effectivePrice"`
+}
+
+func ToQuoteResponse(quote dto.Quote) QuoteResponse {
+	return QuoteResponse{
+		ProductId:      quote.ProductId,
+		Price:          quote.Price,
+		Discount:       quote.Discount,
+		CouponCode:     quote.CouponCode,
+		CouponDiscount: quote.CouponDiscount,
+		EffectivePrice: quote.EffectivePrice,
+	}
+}
@@ -0,0 +1,25 @@
+package response
+
+import "example.com/product-api/domain"
+
+type StoreResponse struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func ToStoreResponse(store domain.Store) StoreResponse {
+	return StoreResponse{
+		Id:   store.Id,
+		Name: store.Name,
+	}
+}
+
+func ToStoreResponseList(stores []domain.Store) []StoreResponse {
+	var storeResponses = []StoreResponse{}
+
+	for _, store := range stores {
+		storeResponses = append(storeResponses, ToStoreResponse(store))
+	}
+
+	return storeResponses
+}
@@ -0,0 +1,34 @@
+package response
+
+import (
+	"example.com/product-api/controller/codec"
+	"example.com/product-api/domain"
+)
+
+type VariantResponse struct {
+	Id            int64                  `json:"id" xml:"id"`
+	Sku           string                 `json:"sku" xml:"sku"`
+	Attributes    codec.StringAttributes `json:"attributes" xml:"attributes"`
+	PriceOverride *float32               `json:"priceOverride,omitempty" xml:"priceOverride,omitempty"`
+	Barcode       string                 `json:"barcode,omitempty" xml:"barcode,omitempty"`
+}
+
+func ToVariantResponse(variant domain.Variant) VariantResponse {
+	return VariantResponse{
+		Id:            variant.Id,
+		Sku:           variant.Sku,
+		Attributes:    variant.Attributes,
+		PriceOverride: variant.PriceOverride,
+		Barcode:       variant.Barcode,
+	}
+}
+
+func ToVariantResponseList(variants []domain.Variant) []VariantResponse {
+	var variantResponses = []VariantResponse{}
+
+	for _, variant := range variants {
+		variantResponses = append(variantResponses, ToVariantResponse(variant))
+	}
+
+	return variantResponses
+}
@@ -0,0 +1,94 @@
+package response
+
+import (
+	"encoding/json"
+	"example.com/product-api/domain"
+	"time"
+)
+
+// WebhookResponse leaves out the secret, which is only shown once in the
+// WebhookCreatedResponse.
+type WebhookResponse struct {
+	Id         int64     `json:"id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	StoreId    int64     `json:"storeId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookCreatedResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	Id             int64           `json:"id"`
+	EventId        int64           `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func ToWebhookResponse(subscription domain.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		Id:         subscription.Id,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		StoreId:    subscription.StoreId,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+func ToWebhookCreatedResponse(subscription domain.WebhookSubscription) WebhookCreatedResponse {
+	return WebhookCreatedResponse{WebhookResponse: ToWebhookResponse(subscription), Secret: subscription.Secret}
+}
+
+func ToWebhookResponseList(subscriptions []domain.WebhookSubscription) []WebhookResponse {
+	var webhookResponses = []WebhookResponse{}
+
+	for _, subscription := range subscriptions {
+		webhookResponses = append(webhookResponses, ToWebhookResponse(subscription))
+	}
+
+	return webhookResponses
+}
+
+// ToWebhookDeliveryResponse only shows the next attempt of deliveries that
+// are still going to be sent.
+func ToWebhookDeliveryResponse(delivery domain.WebhookDelivery) WebhookDeliveryResponse {
+	deliveryResponse := WebhookDeliveryResponse{
+		Id:             delivery.Id,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		Payload:        delivery.Payload,
+	}
+
+	if delivery.Status == domain.WEBHOOK_PENDING || delivery.Status == domain.WEBHOOK_FAILED {
+		nextAttemptAt := delivery.NextAttemptAt
+		deliveryResponse.NextAttemptAt = &nextAttemptAt
+	}
+
+	return deliveryResponse
+}
+
+func ToWebhookDeliveryResponseList(deliveries []domain.WebhookDelivery) []WebhookDeliveryResponse {
+	var deliveryResponses = []WebhookDeliveryResponse{}
+
+	for _, delivery := range deliveries {
+		deliveryResponses = append(deliveryResponses, ToWebhookDeliveryResponse(delivery))
+	}
+
+	return deliveryResponses
+}
@@ -0,0 +1,75 @@
+package response
+
+import (
+	"example.com/product-api/domain"
+	"sort"
+)
+
+const (
+	WS_SUBSCRIBED = "subscribed"
+	WS_PRICE      = "price"
+	WS_DELETED    = "deleted"
+	WS_ERROR      = "error"
+)
+
+type WebsocketSubscribedResponse struct {
+	Type       string   `json:"type"`
+	ProductIds []int64  `json:"productIds"`
+	Stores     []string `json:"stores"`
+}
+
+// WebsocketProductUpdate is pushed for every change to a subscribed product.
+// Discount is left out when the change did not carry it, as for bulk price
+// updates.
+type WebsocketProductUpdate struct {
+	Type          string  `json:"type"`
+	EventId       uint64  `json:"eventId"`
+	ProductId     int64   `json:"productId"`
+	Store         string  `json:"store"`
+	Price         float32 `json:"price,omitempty"`
+	PreviousPrice float32 `json:"previousPrice,omitempty"`
+	Discount      float32 `json:"discount,omitempty"`
+}
+
+type WebsocketErrorResponse struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+func ToWebsocketSubscribedResponse(productIds map[int64]bool, stores map[string]bool) WebsocketSubscribedResponse {
+	subscribedResponse := WebsocketSubscribedResponse{Type: WS_SUBSCRIBED, ProductIds: []int64{}, Stores: []string{}}
+
+	for productId := range productIds {
+		subscribedResponse.ProductIds = append(subscribedResponse.ProductIds, productId)
+	}
+	for store := range stores {
+		subscribedResponse.Stores = append(subscribedResponse.Stores, store)
+	}
+
+	sort.Slice(subscribedResponse.ProductIds, func(i, j int) bool {
+		return subscribedResponse.ProductIds[i] < subscribedResponse.ProductIds[j]
+	})
+	sort.Strings(subscribedResponse.Stores)
+
+	return subscribedResponse
+}
+
+// ToWebsocketProductUpdate returns false for events that change neither
+// price nor discount.
+func ToWebsocketProductUpdate(event domain.ProductEvent) (WebsocketProductUpdate, bool) {
+	update := WebsocketProductUpdate{EventId: event.Id, ProductId: event.Product.Id, Store: event.Product.Store}
+
+	switch event.Type {
+	case domain.PRODUCT_CREATED, domain.PRODUCT_UPDATED, domain.PRODUCT_PRICE_CHANGED:
+		update.Type = WS_PRICE
+		update.Price = event.Product.Price
+		update.PreviousPrice = event.PreviousPrice
+		update.Discount = event.Product.Discount
+	case domain.PRODUCT_DELETED:
+		update.Type = WS_DELETED
+	default:
+		return WebsocketProductUpdate{}, false
+	}
+
+	return update, true
+}
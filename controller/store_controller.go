@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type StoreController struct {
+	storeService service.IStoreService
+}
+
+func NewStoreController(storeService service.IStoreService) *StoreController {
+	return &StoreController{
+		storeService: storeService,
+	}
+}
+
+func (storeController *StoreController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/stores", storeController.GetAll)
+	e.GET("/api/stores/:id", storeController.GetById)
+	e.GET("/api/stores/:id/products", storeController.GetProducts)
+	e.POST("/api/stores", storeController.Add)
+	e.PUT("/api/stores/:id", storeController.Rename)
+	e.DELETE("/api/stores/:id", storeController.Delete)
+}
+
+func (storeController *StoreController) GetAll(c echo.Context) error {
+	return c.JSON(http.StatusOK, response.ToStoreResponseList(storeController.storeService.GetAll()))
+}
+
+func (storeController *StoreController) GetById(c echo.Context) error {
+	storeId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	store, err := storeController.storeService.GetById(int64(storeId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToStoreResponse(store))
+}
+
+func (storeController *StoreController) GetProducts(c echo.Context) error {
+	storeId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	products, err := storeController.storeService.GetProducts(int64(storeId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToProductResponseList(products))
+}
+
+func (storeController *StoreController) Add(c echo.Context) error {
+	var addStoreRequest request.AddStoreRequest
+	err := c.Bind(&addStoreRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = storeController.storeService.Add(addStoreRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, addStoreRequest.ToModel())
+}
+
+func (storeController *StoreController) Rename(c echo.Context) error {
+	storeId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var addStoreRequest request.AddStoreRequest
+	err = c.Bind(&addStoreRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = storeController.storeService.Rename(int64(storeId), addStoreRequest.Name)
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (storeController *StoreController) Delete(c echo.Context) error {
+	storeId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	_, err = storeController.storeService.GetById(int64(storeId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = storeController.storeService.DeleteById(int64(storeId))
+
+	if err != nil {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type TagController struct {
+	tagService service.ITagService
+}
+
+func NewTagController(tagService service.ITagService) *TagController {
+	return &TagController{
+		tagService: tagService,
+	}
+}
+
+func (tagController *TagController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/products/:id/tags", tagController.GetAll)
+	e.POST("/api/products/:id/tags", tagController.Add)
+	e.DELETE("/api/products/:id/tags", tagController.Remove)
+}
+
+func (tagController *TagController) GetAll(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	tags, err := tagController.tagService.GetAllByProductId(int64(productId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
+
+func (tagController *TagController) Add(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var tagsRequest request.TagsRequest
+	err = c.Bind(&tagsRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = tagController.tagService.AddToProduct(int64(productId), tagsRequest.Tags)
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (tagController *TagController) Remove(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var tagsRequest request.TagsRequest
+	err = c.Bind(&tagsRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = tagController.tagService.RemoveFromProduct(int64(productId), tagsRequest.Tags)
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
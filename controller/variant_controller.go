@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type VariantController struct {
+	variantService service.IVariantService
+}
+
+func NewVariantController(variantService service.IVariantService) *VariantController {
+	return &VariantController{
+		variantService: variantService,
+	}
+}
+
+func (variantController *VariantController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/products/:id/variants", variantController.GetAll)
+	e.GET("/api/products/:id/variants/:variantId", variantController.GetById)
+	e.POST("/api/products/:id/variants", variantController.Add)
+	e.PUT("/api/products/:id/variants/:variantId", variantController.Update)
+	e.DELETE("/api/products/:id/variants/:variantId", variantController.Delete)
+}
+
+func (variantController *VariantController) GetAll(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	variants, err := variantController.variantService.GetAllByProductId(int64(productId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToVariantResponseList(variants))
+}
+
+func (variantController *VariantController) GetById(c echo.Context) error {
+	productId, variantId, err := variantIdsFromPath(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	variant, err := variantController.variantService.GetById(productId, variantId)
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToVariantResponse(variant))
+}
+
+func (variantController *VariantController) Add(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var addVariantRequest request.AddVariantRequest
+	err = c.Bind(&addVariantRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = variantController.variantService.Add(int64(productId), addVariantRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, addVariantRequest.ToModel())
+}
+
+func (variantController *VariantController) Update(c echo.Context) error {
+	productId, variantId, err := variantIdsFromPath(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	var addVariantRequest request.AddVariantRequest
+	err = c.Bind(&addVariantRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	err = variantController.variantService.Update(productId, variantId, addVariantRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (variantController *VariantController) Delete(c echo.Context) error {
+	productId, variantId, err := variantIdsFromPath(c)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	err = variantController.variantService.DeleteById(productId, variantId)
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func variantIdsFromPath(c echo.Context) (int64, int64, error) {
+	productId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	variantId, err := strconv.Atoi(c.Param("variantId"))
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int64(productId), int64(variantId), nil
+}
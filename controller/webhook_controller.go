@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type WebhookController struct {
+	webhookService service.IWebhookService
+}
+
+func NewWebhookController(webhookService service.IWebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+func (webhookController *WebhookController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/webhooks", webhookController.GetAll)
+	e.GET("/api/webhooks/:id", webhookController.GetById)
+	e.POST("/api/webhooks", webhookController.Add)
+	e.DELETE("/api/webhooks/:id", webhookController.Delete)
+	e.GET("/api/webhooks/:id/deliveries", webhookController.GetDeliveries)
+	e.POST("/api/webhooks/:id/deliveries/:deliveryId/redeliver", webhookController.Redeliver)
+}
+
+func (webhookController *WebhookController) GetAll(c echo.Context) error {
+	return c.JSON(http.StatusOK, response.ToWebhookResponseList(webhookController.webhookService.GetAll()))
+}
+
+func (webhookController *WebhookController) GetById(c echo.Context) error {
+	subscriptionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	subscription, err := webhookController.webhookService.GetById(int64(subscriptionId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToWebhookResponse(subscription))
+}
+
+// Add answers with the secret, which is not shown again afterwards.
+func (webhookController *WebhookController) Add(c echo.Context) error {
+	var addWebhookRequest request.AddWebhookRequest
+	err := c.Bind(&addWebhookRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	subscription, err := webhookController.webhookService.Add(addWebhookRequest.ToModel())
+
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, response.ToWebhookCreatedResponse(subscription))
+}
+
+func (webhookController *WebhookController) Delete(c echo.Context) error {
+	subscriptionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	err = webhookController.webhookService.DeleteById(int64(subscriptionId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// GetDeliveries returns the delivery log of the webhook, latest first,
+// optionally filtered with ?status=.
+func (webhookController *WebhookController) GetDeliveries(c echo.Context) error {
+	subscriptionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	deliveries, err := webhookController.webhookService.GetDeliveries(int64(subscriptionId), c.QueryParam("status"))
+
+	if errors.Is(err, service.ErrInvalidDeliveryStatus) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.ToWebhookDeliveryResponseList(deliveries))
+}
+
+// Redeliver schedules the delivery to be sent again right away; it is sent
+// by the dispatcher, hence 202.
+func (webhookController *WebhookController) Redeliver(c echo.Context) error {
+	subscriptionId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid id"})
+	}
+
+	deliveryId, err := strconv.Atoi(c.Param("deliveryId"))
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{ErrorDescription: "enter valid delivery id"})
+	}
+
+	err = webhookController.webhookService.Redeliver(int64(subscriptionId), int64(deliveryId))
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{ErrorDescription: err.Error()})
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
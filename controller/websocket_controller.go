@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"encoding/json"
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// WS_MAX_SUBSCRIPTIONS bounds the product ids and stores one connection
+	// may subscribe to.
+	WS_MAX_SUBSCRIPTIONS = 1000
+	// WS_MAX_MESSAGE_SIZE bounds the client messages read from a connection.
+	WS_MAX_MESSAGE_SIZE = 64 * 1024
+	// WS_SEND_BUFFER bounds the replies queued for a connection.
+	WS_SEND_BUFFER = 16
+
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 60 * time.Second
+	wsPingInterval = wsPongTimeout * 9 / 10
+)
+
+type WebsocketController struct {
+	productEventBus service.IProductEventBus
+	upgrader        websocket.Upgrader
+}
+
+func NewWebsocketController(productEventBus service.IProductEventBus) *WebsocketController {
+	return &WebsocketController{
+		productEventBus: productEventBus,
+		upgrader:        websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024},
+	}
+}
+
+// RegisterRoutes adds /ws as an ordinary route, so middleware registered on
+// the server with e.Use, such as authentication, runs on the handshake
+// before the connection is upgraded.
+func (websocketController *WebsocketController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/ws", websocketController.Connect)
+}
+
+// Connect upgrades the request and pushes price and discount updates of the
+// subscribed products and stores until either side closes.
+//
+// Each connection has its own event bus subscription. A client that reads
+// slower than updates arrive first blocks the writer, then fills its
+// subscription buffer; the bus drops it and the connection is closed with
+// "try again later", so a slow client never holds up the others.
+func (websocketController *WebsocketController) Connect(c echo.Context) error {
+	conn, err := websocketController.upgrader.Upgrade(c.Response(), c.Request(), nil)
+
+	if err != nil {
+		// The upgrader has already answered the request.
+		return nil
+	}
+
+	session := &websocketSession{
+		conn:         conn,
+		subscription: websocketController.productEventBus.Subscribe(0),
+		productIds:   map[int64]bool{},
+		stores:       map[string]bool{},
+		replies:      make(chan interface{}, WS_SEND_BUFFER),
+		done:         make(chan struct{}),
+	}
+
+	go session.readLoop()
+	session.writeLoop()
+
+	return nil
+}
+
+type websocketSession struct {
+	conn         *websocket.Conn
+	subscription *service.ProductEventSubscription
+
+	mutex      sync.Mutex
+	productIds map[int64]bool
+	stores     map[string]bool
+
+	replies chan interface{}
+	done    chan struct{}
+}
+
+// readLoop applies subscribe and unsubscribe messages until the connection
+// fails, then signals the writer through done.
+func (session *websocketSession) readLoop() {
+	defer close(session.done)
+
+	session.conn.SetReadLimit(WS_MAX_MESSAGE_SIZE)
+	session.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	session.conn.SetPongHandler(func(string) error {
+		return session.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, data, err := session.conn.ReadMessage()
+
+		if err != nil {
+			return
+		}
+
+		var message request.WebsocketMessage
+		var reply interface{}
+
+		if err := json.Unmarshal(data, &message); err != nil {
+			reply = response.WebsocketErrorResponse{Type: response.WS_ERROR, Error: "Message must be a JSON object"}
+		} else {
+			reply = session.apply(message)
+		}
+
+		if !session.reply(reply) {
+			return
+		}
+	}
+}
+
+// reply queues a message for the writer. A client that does not read its
+// replies is disconnected once WS_SEND_BUFFER of them are pending.
+func (session *websocketSession) reply(message interface{}) bool {
+	select {
+	case session.replies <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+func (session *websocketSession) apply(message request.WebsocketMessage) interface{} {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	switch message.Action {
+	case request.WS_SUBSCRIBE:
+		added := 0
+		for _, productId := range message.ProductIds {
+			if !session.productIds[productId] {
+				added++
+			}
+		}
+		for _, store := range message.Stores {
+			if !session.stores[storeSubscriptionKey(store)] {
+				added++
+			}
+		}
+
+		if len(session.productIds)+len(session.stores)+added > WS_MAX_SUBSCRIPTIONS {
+			return response.WebsocketErrorResponse{Type: response.WS_ERROR,
+				Error: fmt.Sprintf("A connection can not have more than %d subscriptions", WS_MAX_SUBSCRIPTIONS)}
+		}
+
+		for _, productId := range message.ProductIds {
+			session.productIds[productId] = true
+		}
+		for _, store := range message.Stores {
+			session.stores[storeSubscriptionKey(store)] = true
+		}
+	case request.WS_UNSUBSCRIBE:
+		for _, productId := range message.ProductIds {
+			delete(session.productIds, productId)
+		}
+		for _, store := range message.Stores {
+			delete(session.stores, storeSubscriptionKey(store))
+		}
+	default:
+		return response.WebsocketErrorResponse{Type: response.WS_ERROR, Error: "Action must be subscribe or unsubscribe"}
+	}
+
+	return response.ToWebsocketSubscribedResponse(session.productIds, session.stores)
+}
+
+func (session *websocketSession) isSubscribed(event domain.ProductEvent) bool {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	return session.productIds[event.Product.Id] || session.stores[storeSubscriptionKey(event.Product.Store)]
+}
+
+// writeLoop is the only writer of the connection, as websocket connections
+// support one concurrent writer.
+func (session *websocketSession) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+
+	defer func() {
+		ping.Stop()
+		session.subscription.Close()
+		session.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-session.done:
+			return
+		case message := <-session.replies:
+			if session.write(message) != nil {
+				return
+			}
+		case event, open := <-session.subscription.Events:
+			if !open {
+				session.conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Client too slow"), time.Now().Add(wsWriteTimeout))
+				return
+			}
+
+			message, pushed := response.ToWebsocketProductUpdate(event)
+			if !pushed || !session.isSubscribed(event) {
+				continue
+			}
+
+			if session.write(message) != nil {
+				return
+			}
+		case <-ping.C:
+			if session.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (session *websocketSession) write(message interface{}) error {
+	session.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+
+	return session.conn.WriteJSON(message)
+}
+
+func storeSubscriptionKey(store string) string {
+	return strings.ToLower(domain.NormalizeStoreName(store))
+}
@@ -0,0 +1,8 @@
+package domain
+
+type Category struct {
+	Id              int64
+	Name            string
+	ParentId        int64
+	AttributeSchema []byte
+}
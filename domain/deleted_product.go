@@ -0,0 +1,8 @@
+package domain
+
+import "time"
+
+type DeletedProduct struct {
+	Product
+	DeletedAt time.Time
+}
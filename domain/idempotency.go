@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// StoredResponse is the first response to a request with an idempotency key,
+// replayed to every retry of the request.
+type StoredResponse struct {
+	StatusCode int
+	Headers    map[string][]string
+	Body       []byte
+}
+
+// IdempotencyRecord is what is known about an idempotency key. Response is
+// nil while the first request is still in flight.
+type IdempotencyRecord struct {
+	Key         string
+	Route       string
+	RequestHash string
+	Response    *StoredResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,16 @@
+package domain
+
+// ImportReport sums up an import. In a dry run Imported counts the rows that
+// passed validation and would have been written.
+type ImportReport struct {
+	DryRun   bool
+	Rows     int
+	Imported int
+	Errors   []ImportRowError
+}
+
+// ImportRowError points at the spreadsheet row, counting the header as row 1.
+type ImportRowError struct {
+	Row   int
+	Error string
+}
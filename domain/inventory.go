@@ -0,0 +1,13 @@
+package domain
+
+type Inventory struct {
+	ProductId int64
+	StoreId   int64
+	Store     string
+	OnHand    int32
+	Reserved  int32
+}
+
+func (inventory Inventory) Available() int32 {
+	return inventory.OnHand - inventory.Reserved
+}
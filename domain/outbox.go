@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// OutboxMessage is a product event waiting in the outbox. DeliveredSinks
+// names the sinks that already have it, so a retry only goes to the others.
+type OutboxMessage struct {
+	Id             int64
+	Event          ProductEvent
+	Attempts       int
+	DeliveredSinks []string
+}
+
+// OutboxOutcome is what the relay did with a message. A delivered message
+// is removed from the outbox; otherwise it is retried at NextAttemptAt, or
+// kept aside for inspection when DeadLetter is set.
+type OutboxOutcome struct {
+	Id             int64
+	Delivered      bool
+	DeliveredSinks []string
+	Error          string
+	NextAttemptAt  time.Time
+	DeadLetter     bool
+}
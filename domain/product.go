@@ -1,9 +1,21 @@
 package domain
 
+import "time"
+
+// Version and UpdatedAt change with every update of the product, which lets
+// clients and caches tell whether the copy they hold is current. ExternalRef
+// is the ERP's reference of a synced product; Discontinued is set once a full
+// sync no longer contains it.
 type Product struct {
-	Id       int64
-	Name     string
-	Price    float32
-	Discount float32
-	Store    string
+	Id           int64
+	Name         string
+	Price        float32
+	Discount     float32
+	StoreId      int64
+	Store        string
+	Attributes   map[string]interface{}
+	Version      int64
+	UpdatedAt    time.Time
+	ExternalRef  string
+	Discontinued bool
 }
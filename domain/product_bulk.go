@@ -0,0 +1,27 @@
+package domain
+
+const (
+	BULK_CREATE = "create"
+	BULK_UPDATE = "update"
+	BULK_DELETE = "delete"
+)
+
+// BulkOperation is one item of a bulk request. Creates carry the whole
+// product, updates the product id and new price, deletes only the id.
+type BulkOperation struct {
+	Action  string
+	Index   int
+	Product Product
+}
+
+// BulkResult reports the outcome of the operation at Index of its action's
+// list. Error is empty when the operation succeeded, in which case StoreId
+// and Store name the store of the product.
+type BulkResult struct {
+	Action  string
+	Index   int
+	Id      int64
+	StoreId int64
+	Store   string
+	Error   string
+}
@@ -0,0 +1,12 @@
+package domain
+
+// ProductCacheStats counts the lookups answered by the product cache, as
+// hits, or by the database, as misses, and how many entries it holds.
+type ProductCacheStats struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64
+	Products      int
+	NotFound      int
+	Stores        int
+}
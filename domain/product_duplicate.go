@@ -0,0 +1,10 @@
+package domain
+
+// ProductDuplicate pairs two products of a store whose names are so similar
+// that they are likely the same product. Similarity is the trigram similarity
+// of the names, from 0 to 1.
+type ProductDuplicate struct {
+	Product    Product
+	Duplicate  Product
+	Similarity float32
+}
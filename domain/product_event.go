@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+const (
+	PRODUCT_CREATED       = "created"
+	PRODUCT_UPDATED       = "updated"
+	PRODUCT_PRICE_CHANGED = "price_changed"
+	PRODUCT_DELETED       = "deleted"
+)
+
+// ProductEvent describes one change to a product. Product holds the state
+// after the change as far as it is known; deletes and bulk price updates
+// only carry the ids, store and price. Id is the outbox id, which stays the
+// same across redeliveries and is the same on every instance.
+type ProductEvent struct {
+	Id            uint64
+	Type          string
+	Product       Product
+	PreviousPrice float32
+	OccurredAt    time.Time
+}
@@ -0,0 +1,22 @@
+package domain
+
+// PRICE_BUCKET_BOUNDS split prices into [0, 500), [500, 1000), ... and a
+// last open-ended bucket from 10000 upwards.
+var PRICE_BUCKET_BOUNDS = []float32{500, 1000, 2500, 5000, 10000}
+
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+type PriceBucketCount struct {
+	From  float32
+	To    float32
+	Count int64
+}
+
+type ProductFacets struct {
+	Tags         []FacetCount
+	Stores       []FacetCount
+	PriceBuckets []PriceBucketCount
+}
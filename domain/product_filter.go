@@ -0,0 +1,40 @@
+package domain
+
+import "regexp"
+
+const (
+	ATTRIBUTE_EQ     = "eq"
+	ATTRIBUTE_NE     = "ne"
+	ATTRIBUTE_GT     = "gt"
+	ATTRIBUTE_GTE    = "gte"
+	ATTRIBUTE_LT     = "lt"
+	ATTRIBUTE_LTE    = "lte"
+	ATTRIBUTE_EXISTS = "exists"
+)
+
+type ProductFilter struct {
+	Store              string
+	CategoryId         int64
+	IncludeDescendants bool
+	InStock            bool
+	Attributes         []AttributeCondition
+	Tags               []string
+	MatchAllTags       bool
+}
+
+// AttributeCondition compares one key of the product attributes. Value is
+// used by eq and ne, Number by the ordering operators.
+type AttributeCondition struct {
+	Key      string
+	Operator string
+	Value    string
+	Number   float64
+}
+
+var attributeKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// IsAttributeKey limits attribute keys to names that can be used in the
+// attr.<key> filter syntax.
+func IsAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
@@ -0,0 +1,6 @@
+package domain
+
+type ProductPage struct {
+	Products    []Product
+	HasNextPage bool
+}
@@ -0,0 +1,7 @@
+package domain
+
+type ProductSearchResult struct {
+	Product   Product
+	Rank      float32
+	Highlight string
+}
@@ -0,0 +1,21 @@
+package domain
+
+const (
+	UPSERT_CREATED   = "created"
+	UPSERT_UPDATED   = "updated"
+	UPSERT_UNCHANGED = "unchanged"
+)
+
+// ProductUpsert reports what an upsert by external reference did: created
+// the product, updated it, or found it already as sent.
+type ProductUpsert struct {
+	Product Product
+	Outcome string
+}
+
+// ProductSyncReport lists the outcome of every product of a feed in feed
+// order, followed by the products a full feed discontinued.
+type ProductSyncReport struct {
+	Upserts      []ProductUpsert
+	Discontinued []Product
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+const (
+	PERCENT_DISCOUNT = "percent"
+	AMOUNT_DISCOUNT  = "amount"
+)
+
+type Promotion struct {
+	Id           int64
+	Code         string
+	ProductIds   []int64
+	StoreIds     []int64
+	DiscountType string
+	Value        float32
+	ValidFrom    time.Time
+	ValidUntil   time.Time
+	UsageLimit   int32
+	UsageCount   int32
+}
+
+func (promotion Promotion) AppliesTo(product Product) bool {
+	if len(promotion.ProductIds) == 0 && len(promotion.StoreIds) == 0 {
+		return true
+	}
+
+	for _, productId := range promotion.ProductIds {
+		if productId == product.Id {
+			return true
+		}
+	}
+
+	for _, storeId := range promotion.StoreIds {
+		if storeId == product.StoreId {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "strings"
+
+type Store struct {
+	Id   int64
+	Name string
+}
+
+func NormalizeStoreName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
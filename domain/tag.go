@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func IsTag(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
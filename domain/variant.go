@@ -0,0 +1,10 @@
+package domain
+
+type Variant struct {
+	Id            int64
+	ProductId     int64
+	Sku           string
+	Attributes    map[string]string
+	PriceOverride *float32
+	Barcode       string
+}
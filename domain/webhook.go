@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	WEBHOOK_PENDING   = "pending"
+	WEBHOOK_SUCCEEDED = "succeeded"
+	WEBHOOK_FAILED    = "failed"
+	WEBHOOK_DEAD      = "dead"
+)
+
+// WebhookSubscription sends the product events of EventTypes to Url. With a
+// StoreId of 0 it gets the events of every store.
+type WebhookSubscription struct {
+	Id         int64
+	Url        string
+	EventTypes []string
+	StoreId    int64
+	Secret     string
+	CreatedAt  time.Time
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to one
+// subscription. Payload is kept so that every attempt, including a manual
+// redelivery, posts the same body. Url and Secret come from the
+// subscription when the delivery is due.
+type WebhookDelivery struct {
+	Id             int64
+	SubscriptionId int64
+	EventId        int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	Url            string
+	Secret         string
+}
+
+// WebhookDeliveryOutcome is the result of one attempt. Status is
+// WEBHOOK_SUCCEEDED, WEBHOOK_FAILED to retry at NextAttemptAt, or
+// WEBHOOK_DEAD when no attempts are left.
+type WebhookDeliveryOutcome struct {
+	Id            int64
+	Status        string
+	StatusCode    int
+	Error         string
+	NextAttemptAt time.Time
+}
@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// JSON is the scalar product attributes are exposed as. Inputs must be
+// objects, the same as in the REST API.
+type JSON map[string]interface{}
+
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (value *JSON) UnmarshalGraphQL(input interface{}) error {
+	object, ok := input.(map[string]interface{})
+
+	if !ok {
+		return errors.New("Attributes must be an object")
+	}
+
+	*value = object
+
+	return nil
+}
+
+func (value JSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(value))
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/graph-gophers/dataloader"
+	"strings"
+)
+
+type loadersContextKey struct{}
+
+// loaders batch the lookups made while resolving one request. Products only
+// carry their store name, so stores are loaded by name.
+type loaders struct {
+	stores *dataloader.Loader
+}
+
+func newLoaders(storeService service.IStoreService) *loaders {
+	return &loaders{
+		stores: dataloader.NewBatchedLoader(storeBatchFunc(storeService)),
+	}
+}
+
+func withLoaders(ctx context.Context, requestLoaders *loaders) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, requestLoaders)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersContextKey{}).(*loaders)
+}
+
+func storeKey(storeName string) dataloader.Key {
+	return dataloader.StringKey(strings.ToLower(domain.NormalizeStoreName(storeName)))
+}
+
+// storeBatchFunc resolves every store name of a batch with one query. Names
+// without a store resolve to nil.
+func storeBatchFunc(storeService service.IStoreService) dataloader.BatchFunc {
+	return func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		storesByKey := map[string]domain.Store{}
+
+		for _, store := range storeService.GetAllByNames(keys.Keys()) {
+			storesByKey[storeKey(store.Name).String()] = store
+		}
+
+		results := make([]*dataloader.Result, len(keys))
+
+		for i, key := range keys {
+			if store, found := storesByKey[key.String()]; found {
+				results[i] = &dataloader.Result{Data: &store}
+			} else {
+				results[i] = &dataloader.Result{Data: (*domain.Store)(nil)}
+			}
+		}
+
+		return results
+	}
+}
+
+func loadStore(ctx context.Context, storeName string) (*domain.Store, error) {
+	data, err := loadersFrom(ctx).stores.Load(ctx, storeKey(storeName))()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data.(*domain.Store), nil
+}
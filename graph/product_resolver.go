@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"example.com/product-api/domain"
+	"github.com/graph-gophers/graphql-go"
+	"strconv"
+)
+
+type productResolver struct {
+	product domain.Product
+}
+
+func (productResolver *productResolver) Id() graphql.ID {
+	return toId(productResolver.product.Id)
+}
+
+func (productResolver *productResolver) Name() string {
+	return productResolver.product.Name
+}
+
+func (productResolver *productResolver) Price() float64 {
+	return float64(productResolver.product.Price)
+}
+
+func (productResolver *productResolver) Discount() float64 {
+	return float64(productResolver.product.Discount)
+}
+
+// Store goes through the request's store loader, so listing a page of
+// products costs one store query however many products it holds.
+func (productResolver *productResolver) Store(ctx context.Context) (*storeResolver, error) {
+	store, err := loadStore(ctx, productResolver.product.Store)
+
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	return &storeResolver{store: *store}, nil
+}
+
+func (productResolver *productResolver) Attributes() *JSON {
+	if len(productResolver.product.Attributes) == 0 {
+		return nil
+	}
+
+	attributes := JSON(productResolver.product.Attributes)
+
+	return &attributes
+}
+
+type storeResolver struct {
+	store domain.Store
+}
+
+func (storeResolver *storeResolver) Id() graphql.ID {
+	return toId(storeResolver.store.Id)
+}
+
+func (storeResolver *storeResolver) Name() string {
+	return storeResolver.store.Name
+}
+
+type productConnectionResolver struct {
+	page domain.ProductPage
+}
+
+func (productConnectionResolver *productConnectionResolver) Items() []*productResolver {
+	items := make([]*productResolver, 0, len(productConnectionResolver.page.Products))
+
+	for _, product := range productConnectionResolver.page.Products {
+		items = append(items, &productResolver{product: product})
+	}
+
+	return items
+}
+
+func (productConnectionResolver *productConnectionResolver) EndCursor() *graphql.ID {
+	products := productConnectionResolver.page.Products
+
+	if len(products) == 0 {
+		return nil
+	}
+
+	cursor := toId(products[len(products)-1].Id)
+
+	return &cursor
+}
+
+func (productConnectionResolver *productConnectionResolver) HasNextPage() bool {
+	return productConnectionResolver.page.HasNextPage
+}
+
+func toId(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
@@ -0,0 +1,225 @@
+package graph
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/graph-gophers/graphql-go"
+	"strconv"
+)
+
+// Resolver is the root resolver of the Query and Mutation types.
+type Resolver struct {
+	productService service.IProductService
+}
+
+type productFilterInput struct {
+	Store              *string
+	Category           *graphql.ID
+	IncludeDescendants *bool
+	InStock            *bool
+	Tags               *[]string
+	TagMatch           *string
+}
+
+type productInput struct {
+	Name       string
+	Price      float64
+	Discount   *float64
+	StoreId    *graphql.ID
+	Store      *string
+	Attributes *JSON
+}
+
+func (resolver *Resolver) Product(args struct{ Id graphql.ID }) (*productResolver, error) {
+	productId, err := parseId(args.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := resolver.productService.GetById(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &productResolver{product: product}, nil
+}
+
+// Products pages through the filtered products. The cursor is the id of the
+// last product of the previous page.
+func (resolver *Resolver) Products(args struct {
+	Filter *productFilterInput
+	First  *int32
+	After  *graphql.ID
+}) (*productConnectionResolver, error) {
+	filter, err := toProductFilter(args.Filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if args.First != nil {
+		limit = int(*args.First)
+
+		if limit <= 0 {
+			return nil, errors.New("First must be greater than 0")
+		}
+	}
+
+	var afterId int64
+	if args.After != nil {
+		afterId, err = parseId(*args.After)
+
+		if err != nil {
+			return nil, errors.New("After must be a cursor returned as endCursor")
+		}
+	}
+
+	page, err := resolver.productService.GetPage(filter, afterId, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &productConnectionResolver{page: page}, nil
+}
+
+func (resolver *Resolver) CreateProduct(args struct{ Input productInput }) (*productResolver, error) {
+	productCreate := dto.ProductCreate{
+		Name:  args.Input.Name,
+		Price: float32(args.Input.Price),
+	}
+
+	if args.Input.Discount != nil {
+		productCreate.Discount = float32(*args.Input.Discount)
+	}
+
+	if args.Input.StoreId != nil {
+		storeId, err := parseId(*args.Input.StoreId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		productCreate.StoreId = storeId
+	}
+
+	if args.Input.Store != nil {
+		productCreate.Store = *args.Input.Store
+	}
+
+	if args.Input.Attributes != nil {
+		productCreate.Attributes = *args.Input.Attributes
+	}
+
+	product, err := resolver.productService.Add(productCreate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &productResolver{product: product}, nil
+}
+
+func (resolver *Resolver) UpdatePrice(args struct {
+	Id    graphql.ID
+	Price float64
+}) (*productResolver, error) {
+	productId, err := parseId(args.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Price <= 0 {
+		return nil, errors.New("Price must be greater than 0")
+	}
+
+	_, err = resolver.productService.GetById(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = resolver.productService.UpdatePrice(productId, float32(args.Price))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resolver.Product(struct{ Id graphql.ID }{Id: args.Id})
+}
+
+func (resolver *Resolver) DeleteProduct(args struct{ Id graphql.ID }) (bool, error) {
+	productId, err := parseId(args.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	err = resolver.productService.DeleteById(productId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func toProductFilter(input *productFilterInput) (domain.ProductFilter, error) {
+	filter := domain.ProductFilter{}
+
+	if input == nil {
+		return filter, nil
+	}
+
+	if input.Store != nil {
+		filter.Store = *input.Store
+	}
+
+	if input.Category != nil {
+		categoryId, err := parseId(*input.Category)
+
+		if err != nil {
+			return domain.ProductFilter{}, errors.New("Category must be a category id")
+		}
+
+		filter.CategoryId = categoryId
+	}
+
+	if input.IncludeDescendants != nil {
+		filter.IncludeDescendants = *input.IncludeDescendants
+	}
+
+	if input.InStock != nil {
+		filter.InStock = *input.InStock
+	}
+
+	if input.Tags != nil && len(*input.Tags) != 0 {
+		tags, err := service.NormalizeTags(*input.Tags)
+
+		if err != nil {
+			return domain.ProductFilter{}, err
+		}
+
+		filter.Tags = tags
+	}
+
+	filter.MatchAllTags = input.TagMatch != nil && *input.TagMatch == "ALL"
+
+	return filter, nil
+}
+
+func parseId(id graphql.ID) (int64, error) {
+	value, err := strconv.ParseInt(string(id), 10, 64)
+
+	if err != nil || value <= 0 {
+		return 0, errors.New("enter valid id")
+	}
+
+	return value, nil
+}
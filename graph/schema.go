@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+	"example.com/product-api/service"
+	"github.com/graph-gophers/graphql-go"
+)
+
+const schema = `
+schema {
+    query: Query
+    mutation: Mutation
+}
+
+scalar JSON
+
+type Query {
+    product(id: ID!): Product
+    products(filter: ProductFilter, first: Int, after: ID): ProductConnection!
+}
+
+type Mutation {
+    createProduct(input: ProductInput!): Product!
+    updatePrice(id: ID!, price: Float!): Product!
+    deleteProduct(id: ID!): Boolean!
+}
+
+type Product {
+    id: ID!
+    name: String!
+    price: Float!
+    discount: Float!
+    store: Store
+    attributes: JSON
+}
+
+type Store {
+    id: ID!
+    name: String!
+}
+
+type ProductConnection {
+    items: [Product!]!
+    endCursor: ID
+    hasNextPage: Boolean!
+}
+
+enum TagMatch {
+    ANY
+    ALL
+}
+
+input ProductFilter {
+    store: String
+    category: ID
+    includeDescendants: Boolean
+    inStock: Boolean
+    tags: [String!]
+    tagMatch: TagMatch
+}
+
+input ProductInput {
+    name: String!
+    price: Float!
+    discount: Float
+    storeId: ID
+    store: String
+    attributes: JSON
+}
+`
+
+// maxQueryDepth bounds how deeply a query may nest selections.
+const maxQueryDepth = 10
+
+// Executor runs GraphQL requests against the product schema. Every request
+// gets its own loaders, so batching and caching never span two requests.
+type Executor struct {
+	schema       *graphql.Schema
+	storeService service.IStoreService
+}
+
+func NewExecutor(productService service.IProductService, storeService service.IStoreService) *Executor {
+	return &Executor{
+		// A whole page is resolved in parallel so the store lookups of its
+		// products land in the same loader batch.
+		schema: graphql.MustParseSchema(schema, &Resolver{productService: productService},
+			graphql.MaxDepth(maxQueryDepth),
+			graphql.MaxParallelism(service.MAX_PAGE_SIZE)),
+		storeService: storeService,
+	}
+}
+
+func (executor *Executor) Execute(ctx context.Context, query string, operationName string, variables map[string]interface{}) *graphql.Response {
+	ctx = withLoaders(ctx, newLoaders(executor.storeService))
+
+	return executor.schema.Exec(ctx, query, operationName, variables)
+}
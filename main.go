@@ -2,30 +2,203 @@ package main
 
 import (
 	"context"
+	"errors"
 	"example.com/product-api/common/app"
 	"example.com/product-api/common/postgresql"
 	"example.com/product-api/controller"
+	"example.com/product-api/graph"
 	"example.com/product-api/persistence"
+	"example.com/product-api/rpc"
 	"example.com/product-api/service"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	e := echo.New()
 
 	configurationManager := app.NewConfigurationManager()
 	dbPool := postgresql.GetConnectionPool(ctx, configurationManager.PostgreSqlConfig)
 
-	productRepository := persistence.NewProductRepository(dbPool)
+	var productRepository persistence.IProductRepository = persistence.NewProductRepository(dbPool)
+	productChangeListener := persistence.NewProductChangeListener(dbPool, productRepository)
+	productChangeHandlers := []persistence.IProductChangeHandler{}
+
+	cacheConfig := configurationManager.CacheConfig
+
+	if cacheConfig.Enabled {
+		cachedProductRepository := persistence.NewCachedProductRepository(productRepository, persistence.ProductCacheConfig{
+			Size:        cacheConfig.Size,
+			Ttl:         cacheConfig.Ttl,
+			NegativeTtl: cacheConfig.NegativeTtl,
+		})
+		productChangeHandlers = append(productChangeHandlers, cachedProductRepository)
+		controller.NewProductCacheController(cachedProductRepository).RegisterRoutes(e)
+		productRepository = cachedProductRepository
+	}
+
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
 	productService := service.NewProductService(productRepository)
-	productController := controller.NewProductController(productService)
+	productEventController := controller.NewProductEventController(productEventBus)
+	websocketController := controller.NewWebsocketController(productEventBus)
+
+	webhookRepository := persistence.NewWebhookRepository(dbPool)
+	webhookService := service.NewWebhookService(webhookRepository)
+	webhookController := controller.NewWebhookController(webhookService)
+
+	webhookConfig := configurationManager.WebhookConfig
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepository, service.WebhookDispatcherConfig{
+		PollInterval:    webhookConfig.PollInterval,
+		BatchSize:       webhookConfig.BatchSize,
+		Lease:           webhookConfig.Lease,
+		MaxAttempts:     webhookConfig.MaxAttempts,
+		RetryBackoff:    webhookConfig.RetryBackoff,
+		MaxRetryBackoff: webhookConfig.MaxRetryBackoff,
+		Timeout:         webhookConfig.Timeout,
+	})
+	go webhookDispatcher.Run(ctx)
+
+	outboxConfig := configurationManager.OutboxConfig
+	outboxSinks := []service.IOutboxSink{service.NewWebhookSubscriptionSink(webhookRepository)}
+
+	if len(outboxConfig.LogFile) != 0 {
+		outboxSinks = append(outboxSinks, service.NewLogFileSink(outboxConfig.LogFile))
+	}
+
+	if len(outboxConfig.WebhookUrl) != 0 {
+		outboxSinks = append(outboxSinks, service.NewWebhookSink(outboxConfig.WebhookUrl, outboxConfig.WebhookTimeout))
+	}
+
+	outboxRepository := persistence.NewOutboxRepository(dbPool)
+	outboxRelay := service.NewOutboxRelay(outboxRepository, outboxSinks, service.OutboxRelayConfig{
+		PollInterval:    outboxConfig.PollInterval,
+		BatchSize:       outboxConfig.BatchSize,
+		Lease:           outboxConfig.Lease,
+		MaxAttempts:     outboxConfig.MaxAttempts,
+		RetryBackoff:    outboxConfig.RetryBackoff,
+		MaxRetryBackoff: outboxConfig.MaxRetryBackoff,
+		Retention:       outboxConfig.Retention,
+	})
+	go outboxRelay.Run(ctx)
+
+	// The event bus sink is fed from the change notifications rather than by
+	// the relay, so that every instance sees the changes of all of them. The
+	// same notifications keep the product cache in step with the writes of
+	// other instances.
+	productEventFeed := service.NewProductEventFeed(service.NewProductEventBusSink(productEventBus), outboxRepository)
+	productChangeHandlers = append(productChangeHandlers, productEventFeed)
+	go productChangeListener.Listen(ctx, productChangeHandlers...)
+
+	trashPurgeJob := service.NewTrashPurgeJob(productService, configurationManager.TrashConfig.Retention, configurationManager.TrashConfig.PurgeInterval)
+	go trashPurgeJob.Run(ctx)
+
+	variantRepository := persistence.NewVariantRepository(dbPool)
+	variantService := service.NewVariantService(variantRepository, productRepository)
+	variantController := controller.NewVariantController(variantService)
+
+	idempotencyConfig := configurationManager.IdempotencyConfig
+	idempotencyService := service.NewIdempotencyService(persistence.NewIdempotencyRepository(dbPool), idempotencyConfig.Window,
+		idempotencyConfig.InFlightTimeout)
+	go service.NewIdempotencyPurgeJob(idempotencyService, idempotencyConfig.PurgeInterval).Run(ctx)
+
+	productController := controller.NewProductController(productService, variantService, idempotencyService,
+		configurationManager.HttpCacheConfig.ProductCacheControl)
+
+	productImportService := service.NewProductImportService(productService)
+	importController := controller.NewImportController(productImportService)
+
+	storeRepository := persistence.NewStoreRepository(dbPool)
+	storeService := service.NewStoreService(storeRepository, productRepository)
+	storeController := controller.NewStoreController(storeService)
+
+	graphqlController := controller.NewGraphqlController(graph.NewExecutor(productService, storeService))
+
+	categoryRepository := persistence.NewCategoryRepository(dbPool)
+	categoryService := service.NewCategoryService(categoryRepository, productRepository)
+	categoryController := controller.NewCategoryController(categoryService)
+
+	tagRepository := persistence.NewTagRepository(dbPool)
+	tagService := service.NewTagService(tagRepository, productRepository)
+	tagController := controller.NewTagController(tagService)
+
+	attributeService := service.NewAttributeService(productRepository, categoryRepository)
+	attributeController := controller.NewAttributeController(attributeService)
+
+	inventoryRepository := persistence.NewInventoryRepository(dbPool)
+	inventoryService := service.NewInventoryService(inventoryRepository, productRepository)
+	inventoryController := controller.NewInventoryController(inventoryService)
+
+	promotionRepository := persistence.NewPromotionRepository(dbPool)
+	promotionService := service.NewPromotionService(promotionRepository, productRepository, storeRepository)
+	promotionController := controller.NewPromotionController(promotionService)
 
 	productController.RegisterRoutes(e)
+	productEventController.RegisterRoutes(e)
+	websocketController.RegisterRoutes(e)
+	importController.RegisterRoutes(e)
+	variantController.RegisterRoutes(e)
+	storeController.RegisterRoutes(e)
+	categoryController.RegisterRoutes(e)
+	attributeController.RegisterRoutes(e)
+	tagController.RegisterRoutes(e)
+	inventoryController.RegisterRoutes(e)
+	promotionController.RegisterRoutes(e)
+	graphqlController.RegisterRoutes(e)
+	webhookController.RegisterRoutes(e)
 
-	err := e.Start("localhost:8080")
+	serverConfig := configurationManager.ServerConfig
+
+	grpcListener, err := net.Listen("tcp", serverConfig.GrpcAddress)
 
 	if err != nil {
+		log.Errorf("Could not listen on %s %v", serverConfig.GrpcAddress, err)
 		return
 	}
+
+	grpcServer := rpc.NewServer(productService)
+
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Errorf("gRPC server stopped %v", err)
+			stop()
+		}
+	}()
+
+	go func() {
+		if err := e.Start(serverConfig.HttpAddress); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("HTTP server stopped %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+
+	// Both servers stop together: new requests are refused while in-flight
+	// ones get until the shutdown timeout to finish.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("Error while shutting down HTTP server %v", err)
+	}
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
 }
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS promotions
+(
+    id            BIGSERIAL PRIMARY KEY,
+    code          VARCHAR(64)    NOT NULL UNIQUE,
+    product_ids   BIGINT[]       NOT NULL DEFAULT '{}',
+    stores        VARCHAR(255)[] NOT NULL DEFAULT '{}',
+    discount_type VARCHAR(16)    NOT NULL CHECK (discount_type IN ('percent', 'amount')),
+    value         REAL           NOT NULL CHECK (value > 0),
+    valid_from    TIMESTAMPTZ    NOT NULL,
+    valid_until   TIMESTAMPTZ    NOT NULL,
+    usage_limit   INTEGER        NOT NULL DEFAULT 0,
+    usage_count   INTEGER        NOT NULL DEFAULT 0,
+    CHECK (valid_until > valid_from)
+);
@@ -0,0 +1,55 @@
+-- Moves the free-text products.store column into a stores table. Store names
+-- are trimmed, inner whitespace collapsed and compared case-insensitively, so
+-- 'ABC TECH' and 'ABC Tech ' end up as the same store. The spelling of the
+-- oldest product wins as the display name.
+--
+-- Promotions scoped to stores refer to them by id from now on, so renaming a
+-- store keeps its coupons working. Stores only named by a promotion are
+-- created as well, as dropping them would widen the promotion to all stores.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS stores
+(
+    id   BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS stores_lower_name_idx ON stores (lower(name));
+
+INSERT INTO stores(name)
+SELECT DISTINCT ON (lower(normalized)) normalized
+FROM (SELECT id, regexp_replace(btrim(store), '\s+', ' ', 'g') AS normalized FROM products) AS product_stores
+ORDER BY lower(normalized), id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO stores(name)
+SELECT DISTINCT ON (lower(normalized)) normalized
+FROM (SELECT regexp_replace(btrim(store), '\s+', ' ', 'g') AS normalized FROM promotions, unnest(promotions.stores) AS store) AS promotion_stores
+ORDER BY lower(normalized)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE products ADD COLUMN store_id BIGINT;
+
+UPDATE products
+SET store_id = stores.id
+FROM stores
+WHERE lower(stores.name) = lower(regexp_replace(btrim(products.store), '\s+', ' ', 'g'));
+
+ALTER TABLE products ALTER COLUMN store_id SET NOT NULL;
+ALTER TABLE products ADD CONSTRAINT products_store_id_fkey FOREIGN KEY (store_id) REFERENCES stores (id);
+CREATE INDEX IF NOT EXISTS products_store_id_idx ON products (store_id);
+
+ALTER TABLE products DROP COLUMN store;
+
+ALTER TABLE promotions ADD COLUMN store_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+UPDATE promotions
+SET store_ids = ARRAY(SELECT DISTINCT stores.id
+                      FROM unnest(promotions.stores) AS store
+                               JOIN stores ON lower(stores.name) = lower(regexp_replace(btrim(store), '\s+', ' ', 'g'))
+                      ORDER BY stores.id)
+WHERE cardinality(promotions.stores) > 0;
+
+ALTER TABLE promotions DROP COLUMN stores;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS categories
+(
+    id        BIGSERIAL PRIMARY KEY,
+    name      VARCHAR(255) NOT NULL,
+    parent_id BIGINT REFERENCES categories (id),
+    CHECK (parent_id <> id)
+);
+
+-- Sibling names are unique; root categories share the 0 bucket.
+CREATE UNIQUE INDEX IF NOT EXISTS categories_parent_lower_name_idx ON categories (COALESCE(parent_id, 0), lower(name));
+
+CREATE TABLE IF NOT EXISTS product_categories
+(
+    product_id  BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories (category_id);
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS inventory
+(
+    product_id BIGINT  NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    store_id   BIGINT  NOT NULL REFERENCES stores (id),
+    on_hand    INTEGER NOT NULL DEFAULT 0 CHECK (on_hand >= 0),
+    reserved   INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+    PRIMARY KEY (product_id, store_id),
+    CHECK (reserved <= on_hand)
+);
+
+CREATE INDEX IF NOT EXISTS inventory_available_idx ON inventory (product_id) WHERE on_hand > reserved;
+
+COMMIT;
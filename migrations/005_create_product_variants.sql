@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    id             BIGSERIAL PRIMARY KEY,
+    product_id     BIGINT       NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku            VARCHAR(64)  NOT NULL UNIQUE,
+    attributes     JSONB        NOT NULL DEFAULT '{}',
+    price_override REAL CHECK (price_override > 0),
+    barcode        VARCHAR(14)
+);
+
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx ON product_variants (product_id);
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_barcode_idx ON product_variants (barcode) WHERE barcode IS NOT NULL;
+
+COMMIT;
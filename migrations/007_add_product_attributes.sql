@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS products_attributes_idx ON products USING GIN (attributes);
+
+-- Optional JSON Schema that the attributes of every product in the category
+-- must satisfy.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS attribute_schema JSONB;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX products_deleted_at_idx ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id               BIGSERIAL PRIMARY KEY,
+    event_type       VARCHAR(50) NOT NULL,
+    product_id       BIGINT      NOT NULL,
+    payload          JSONB       NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts         INT         NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_sinks  TEXT[]      NOT NULL DEFAULT '{}',
+    last_error       TEXT,
+    dead_lettered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at, id) WHERE dead_lettered_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,39 @@
+-- Every update of a product row, whichever statement makes it, bumps its
+-- version and updated_at. The HTTP layer derives ETag and Last-Modified from
+-- them. A store rename touches the products of the store, as their
+-- representation carries the store name.
+BEGIN;
+
+ALTER TABLE products
+    ADD COLUMN version    BIGINT      NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp();
+
+CREATE OR REPLACE FUNCTION products_touch() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.version := OLD.version + 1;
+    NEW.updated_at := clock_timestamp();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_touch
+    BEFORE UPDATE ON products
+    FOR EACH ROW
+EXECUTE FUNCTION products_touch();
+
+CREATE OR REPLACE FUNCTION stores_touch_products() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE products SET version = version WHERE store_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stores_touch_products
+    AFTER UPDATE OF name ON stores
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION stores_touch_products();
+
+COMMIT;
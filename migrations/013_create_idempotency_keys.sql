@@ -0,0 +1,21 @@
+-- A row is in flight while status_code is NULL and holds the first response
+-- once the request completed. Keys are scoped to the method and path they
+-- were sent to.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    idempotency_key VARCHAR(255) NOT NULL,
+    route           TEXT         NOT NULL,
+    request_hash    VARCHAR(64)  NOT NULL,
+    status_code     INT,
+    headers         JSONB,
+    body            BYTEA,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    expires_at      TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (idempotency_key, route)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+COMMIT;
@@ -0,0 +1,33 @@
+-- A store can not have two products with the same name. Names are compared
+-- like store names: case-insensitively, trimmed and with inner whitespace
+-- collapsed. Products in the trash do not count.
+--
+-- Existing duplicates are not resolved here, since which of them to keep is
+-- not ours to guess. The migration fails and lists them instead; rename or
+-- delete all but one of each and run it again.
+BEGIN;
+
+DO
+$$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('store %s, %L: products %s', store_id, name, ids), E'\n' ORDER BY store_id, name)
+    INTO conflicts
+    FROM (SELECT store_id, min(name) AS name, string_agg(id::text, ', ' ORDER BY id) AS ids
+          FROM products
+          WHERE deleted_at IS NULL
+          GROUP BY store_id, lower(regexp_replace(btrim(name), '\s+', ' ', 'g'))
+          HAVING count(*) > 1) AS duplicates;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION E'Products with the same name in a store must be renamed or deleted first:\n%', conflicts;
+    END IF;
+END;
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_store_name_idx
+    ON products (store_id, lower(regexp_replace(btrim(name), '\s+', ' ', 'g')))
+    WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Products synced from the ERP carry its reference, which the sync upserts
+-- on. References are unique across the trash as well, so a product sent
+-- again after it was deleted is brought back instead of created twice.
+-- Products with a reference that a full feed no longer contains are marked
+-- discontinued rather than deleted.
+BEGIN;
+
+ALTER TABLE products
+    ADD COLUMN external_ref    TEXT,
+    ADD COLUMN discontinued_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_external_ref_idx ON products (external_ref);
+
+COMMIT;
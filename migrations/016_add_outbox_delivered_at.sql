@@ -0,0 +1,14 @@
+-- Delivered events stay in the outbox for a while instead of being deleted
+-- right away, so an instance whose change listener lost its connection can
+-- replay the changes it missed onto its event streams. The relay removes
+-- them once the retention period has passed.
+BEGIN;
+
+ALTER TABLE outbox
+    ADD COLUMN delivered_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at, id) WHERE dead_lettered_at IS NULL AND delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_delivered_at_idx ON outbox (delivered_at) WHERE delivered_at IS NOT NULL;
+
+COMMIT;
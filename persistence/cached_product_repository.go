@@ -0,0 +1,247 @@
+package persistence
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"fmt"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProductCacheConfig sizes the caches of the CachedProductRepository. Size
+// is the number of entries of each cache, and NegativeTtl how long a product
+// that was not found is remembered as missing.
+type ProductCacheConfig struct {
+	Size        int
+	Ttl         time.Duration
+	NegativeTtl time.Duration
+}
+
+// CachedProductRepository serves GetById and GetAllByStore from in-memory LRU
+// caches and passes everything else to the wrapped repository.
+//
+// Concurrent misses of the same key share one query. Writes made through the
+// repository invalidate the products they touch and every store listing; the
+// changes of other instances arrive through ProductChanged once it is
+// registered with the ProductChangeListener, and store renames of any
+// instance through StoreRenamed.
+//
+// Cached products are shared between callers and must not be modified.
+type CachedProductRepository struct {
+	IProductRepository
+	products    *expirable.LRU[int64, domain.Product]
+	notFound    *expirable.LRU[int64, error]
+	stores      *expirable.LRU[string, []domain.Product]
+	loads       singleflight.Group
+	mutex       sync.Mutex
+	generation  uint64
+	hits        atomic.Int64
+	misses      atomic.Int64
+	invalidated atomic.Int64
+}
+
+func NewCachedProductRepository(productRepository IProductRepository, config ProductCacheConfig) *CachedProductRepository {
+	return &CachedProductRepository{
+		IProductRepository: productRepository,
+		products:           expirable.NewLRU[int64, domain.Product](config.Size, nil, config.Ttl),
+		notFound:           expirable.NewLRU[int64, error](config.Size, nil, config.NegativeTtl),
+		stores:             expirable.NewLRU[string, []domain.Product](config.Size, nil, config.Ttl),
+	}
+}
+
+type productLoad struct {
+	product domain.Product
+	err     error
+}
+
+func (cachedProductRepository *CachedProductRepository) GetById(productId int64) (domain.Product, error) {
+	if product, ok := cachedProductRepository.products.Get(productId); ok {
+		cachedProductRepository.hits.Add(1)
+		return product, nil
+	}
+
+	if err, ok := cachedProductRepository.notFound.Get(productId); ok {
+		cachedProductRepository.hits.Add(1)
+		return domain.Product{}, err
+	}
+
+	cachedProductRepository.misses.Add(1)
+
+	generation := cachedProductRepository.currentGeneration()
+
+	loaded, _, _ := cachedProductRepository.loads.Do(loadKey("product", productId, generation), func() (interface{}, error) {
+		product, err := cachedProductRepository.IProductRepository.GetById(productId)
+
+		// A write that happened while the query ran may not be part of its
+		// result, so the result is only cached when there was none. Callers
+		// arriving after such a write use a key of the next generation and
+		// do not wait for this query.
+		cachedProductRepository.addIfCurrent(generation, func() {
+			switch {
+			case err == nil:
+				cachedProductRepository.products.Add(productId, product)
+			case errors.Is(err, ErrProductNotFound):
+				cachedProductRepository.notFound.Add(productId, err)
+			}
+		})
+
+		return productLoad{product: product, err: err}, nil
+	})
+
+	load := loaded.(productLoad)
+
+	return load.product, load.err
+}
+
+func (cachedProductRepository *CachedProductRepository) GetAllByStore(storeName string) []domain.Product {
+	key := strings.ToLower(domain.NormalizeStoreName(storeName))
+
+	if products, ok := cachedProductRepository.stores.Get(key); ok {
+		cachedProductRepository.hits.Add(1)
+		return append([]domain.Product{}, products...)
+	}
+
+	cachedProductRepository.misses.Add(1)
+
+	generation := cachedProductRepository.currentGeneration()
+
+	loaded, _, _ := cachedProductRepository.loads.Do(loadKey("store", key, generation), func() (interface{}, error) {
+		products := cachedProductRepository.IProductRepository.GetAllByStore(storeName)
+
+		cachedProductRepository.addIfCurrent(generation, func() {
+			cachedProductRepository.stores.Add(key, products)
+		})
+
+		return products, nil
+	})
+
+	return append([]domain.Product{}, loaded.([]domain.Product)...)
+}
+
+func (cachedProductRepository *CachedProductRepository) Add(product domain.Product) (domain.Product, error) {
+	added, err := cachedProductRepository.IProductRepository.Add(product)
+	cachedProductRepository.invalidate(added.Id)
+	return added, err
+}
+
+func (cachedProductRepository *CachedProductRepository) UpdatePrice(productId int64, newPrice float32) error {
+	err := cachedProductRepository.IProductRepository.UpdatePrice(productId, newPrice)
+	cachedProductRepository.invalidate(productId)
+	return err
+}
+
+func (cachedProductRepository *CachedProductRepository) UpdateAttributes(productId int64, attributes map[string]interface{}) error {
+	err := cachedProductRepository.IProductRepository.UpdateAttributes(productId, attributes)
+	cachedProductRepository.invalidate(productId)
+	return err
+}
+
+func (cachedProductRepository *CachedProductRepository) DeleteById(productId int64) error {
+	err := cachedProductRepository.IProductRepository.DeleteById(productId)
+	cachedProductRepository.invalidate(productId)
+	return err
+}
+
+func (cachedProductRepository *CachedProductRepository) Restore(productId int64) error {
+	err := cachedProductRepository.IProductRepository.Restore(productId)
+	cachedProductRepository.invalidate(productId)
+	return err
+}
+
+func (cachedProductRepository *CachedProductRepository) ApplyBulk(operations []domain.BulkOperation, atomic bool) ([]domain.BulkResult, error) {
+	results, err := cachedProductRepository.IProductRepository.ApplyBulk(operations, atomic)
+	cachedProductRepository.Purge()
+	return results, err
+}
+
+func (cachedProductRepository *CachedProductRepository) UpsertByExternalRef(product domain.Product) (domain.ProductUpsert, error) {
+	upsert, err := cachedProductRepository.IProductRepository.UpsertByExternalRef(product)
+	cachedProductRepository.invalidate(upsert.Product.Id)
+	return upsert, err
+}
+
+func (cachedProductRepository *CachedProductRepository) SyncByExternalRef(products []domain.Product, full bool) (domain.ProductSyncReport, error) {
+	report, err := cachedProductRepository.IProductRepository.SyncByExternalRef(products, full)
+	cachedProductRepository.Purge()
+	return report, err
+}
+
+// ProductChanged drops the changed product, which lets the cache follow the
+// writes of other instances.
+func (cachedProductRepository *CachedProductRepository) ProductChanged(event domain.ProductEvent) {
+	cachedProductRepository.invalidate(event.Product.Id)
+}
+
+// StoreRenamed drops everything, as the products of the store may be cached
+// under any id and their listings under the old name.
+func (cachedProductRepository *CachedProductRepository) StoreRenamed(storeId int64) {
+	cachedProductRepository.Purge()
+}
+
+// Resync drops everything, as the changes missed while the listener was not
+// listening are unknown.
+func (cachedProductRepository *CachedProductRepository) Resync() {
+	cachedProductRepository.Purge()
+}
+
+func (cachedProductRepository *CachedProductRepository) Purge() {
+	cachedProductRepository.mutex.Lock()
+	defer cachedProductRepository.mutex.Unlock()
+
+	cachedProductRepository.generation++
+	cachedProductRepository.products.Purge()
+	cachedProductRepository.notFound.Purge()
+	cachedProductRepository.stores.Purge()
+	cachedProductRepository.invalidated.Add(1)
+}
+
+// invalidate drops the product and every store listing, since the store of
+// the product is not known here and may even have changed.
+func (cachedProductRepository *CachedProductRepository) invalidate(productId int64) {
+	cachedProductRepository.mutex.Lock()
+	defer cachedProductRepository.mutex.Unlock()
+
+	cachedProductRepository.generation++
+	cachedProductRepository.products.Remove(productId)
+	cachedProductRepository.notFound.Remove(productId)
+	cachedProductRepository.stores.Purge()
+	cachedProductRepository.invalidated.Add(1)
+}
+
+func (cachedProductRepository *CachedProductRepository) currentGeneration() uint64 {
+	cachedProductRepository.mutex.Lock()
+	defer cachedProductRepository.mutex.Unlock()
+
+	return cachedProductRepository.generation
+}
+
+// addIfCurrent runs add unless the cache was invalidated since generation.
+// It holds the lock that invalidation takes, so an invalidation can not slip
+// in between the check and add.
+func (cachedProductRepository *CachedProductRepository) addIfCurrent(generation uint64, add func()) {
+	cachedProductRepository.mutex.Lock()
+	defer cachedProductRepository.mutex.Unlock()
+
+	if generation == cachedProductRepository.generation {
+		add()
+	}
+}
+
+func (cachedProductRepository *CachedProductRepository) Stats() domain.ProductCacheStats {
+	return domain.ProductCacheStats{
+		Hits:          cachedProductRepository.hits.Load(),
+		Misses:        cachedProductRepository.misses.Load(),
+		Invalidations: cachedProductRepository.invalidated.Load(),
+		Products:      cachedProductRepository.products.Len(),
+		NotFound:      cachedProductRepository.notFound.Len(),
+		Stores:        cachedProductRepository.stores.Len(),
+	}
+}
+
+func loadKey(kind string, key interface{}, generation uint64) string {
+	return fmt.Sprintf("%s:%v:%d", kind, key, generation)
+}
@@ -0,0 +1,233 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+type ICategoryRepository interface {
+	GetAll() []domain.Category
+	GetById(categoryId int64) (domain.Category, error)
+	GetChildren(categoryId int64) []domain.Category
+	GetAllByProductId(productId int64) []domain.Category
+	IsDescendant(categoryId int64, ancestorId int64) bool
+	Add(category domain.Category) error
+	Update(category domain.Category) error
+	DeleteById(categoryId int64) error
+	AddProduct(categoryId int64, productId int64) error
+	RemoveProduct(categoryId int64, productId int64) error
+}
+
+type CategoryRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewCategoryRepository(dbPool *pgxpool.Pool) ICategoryRepository {
+	return &CategoryRepository{dbPool: dbPool}
+}
+
+const selectCategoriesSql = `Select categories.id, categories.name, COALESCE(categories.parent_id, 0), categories.attribute_schema from categories`
+
+func (categoryRepository *CategoryRepository) GetAll() []domain.Category {
+	ctx := context.Background()
+	categoryRows, err := categoryRepository.dbPool.Query(ctx, selectCategoriesSql+" order by categories.id")
+
+	if err != nil {
+		log.Errorf("Couldn't get categories %v", err)
+		return []domain.Category{}
+	}
+
+	return extractCategoriesFromRows(categoryRows)
+}
+
+func (categoryRepository *CategoryRepository) GetById(categoryId int64) (domain.Category, error) {
+	ctx := context.Background()
+	getByIdSql := selectCategoriesSql + ` where categories.id = $1`
+	queryRow := categoryRepository.dbPool.QueryRow(ctx, getByIdSql, categoryId)
+
+	var category domain.Category
+	scanErr := queryRow.Scan(&category.Id, &category.Name, &category.ParentId, &category.AttributeSchema)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Category{}, errors.New(fmt.Sprintf("Category not found with id %d", categoryId))
+	}
+
+	if scanErr != nil {
+		return domain.Category{}, errors.New(fmt.Sprintf("Error while getting category with id %d", categoryId))
+	}
+
+	return category, nil
+}
+
+func (categoryRepository *CategoryRepository) GetChildren(categoryId int64) []domain.Category {
+	ctx := context.Background()
+	getChildrenSql := selectCategoriesSql + ` where categories.parent_id = $1 order by categories.id`
+	categoryRows, err := categoryRepository.dbPool.Query(ctx, getChildrenSql, categoryId)
+
+	if err != nil {
+		log.Errorf("Error while getting children of category %d %v", categoryId, err)
+		return []domain.Category{}
+	}
+
+	return extractCategoriesFromRows(categoryRows)
+}
+
+func (categoryRepository *CategoryRepository) GetAllByProductId(productId int64) []domain.Category {
+	ctx := context.Background()
+	getByProductSql := selectCategoriesSql + `
+join product_categories on product_categories.category_id = categories.id
+where product_categories.product_id = $1 order by categories.id`
+	categoryRows, err := categoryRepository.dbPool.Query(ctx, getByProductSql, productId)
+
+	if err != nil {
+		log.Errorf("Error while getting categories of product %d %v", productId, err)
+		return []domain.Category{}
+	}
+
+	return extractCategoriesFromRows(categoryRows)
+}
+
+// IsDescendant reports whether categoryId sits anywhere below ancestorId.
+func (categoryRepository *CategoryRepository) IsDescendant(categoryId int64, ancestorId int64) bool {
+	ctx := context.Background()
+	isDescendantSql := `WITH RECURSIVE category_tree AS (
+    SELECT id FROM categories WHERE parent_id = $1
+    UNION
+    SELECT categories.id FROM categories JOIN category_tree ON categories.parent_id = category_tree.id
+)
+SELECT EXISTS(SELECT 1 FROM category_tree WHERE id = $2)`
+
+	var isDescendant bool
+	err := categoryRepository.dbPool.QueryRow(ctx, isDescendantSql, ancestorId, categoryId).Scan(&isDescendant)
+
+	if err != nil {
+		log.Errorf("Error while walking category tree of %d %v", ancestorId, err)
+		return false
+	}
+
+	return isDescendant
+}
+
+func (categoryRepository *CategoryRepository) Add(category domain.Category) error {
+	ctx := context.Background()
+	insertSql := `INSERT INTO categories(name, parent_id, attribute_schema) VALUES($1, NULLIF($2::bigint, 0), $3)`
+	_, err := categoryRepository.dbPool.Exec(ctx, insertSql, category.Name, category.ParentId, category.AttributeSchema)
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return errors.New(fmt.Sprintf("Category already exists with name %s", category.Name))
+	}
+
+	if err != nil {
+		log.Errorf("Error while inserting category %v", err)
+		return err
+	}
+
+	log.Infof("Category added with name %s", category.Name)
+
+	return nil
+}
+
+func (categoryRepository *CategoryRepository) Update(category domain.Category) error {
+	ctx := context.Background()
+	updateSql := `Update categories set name = $1, parent_id = NULLIF($2::bigint, 0), attribute_schema = $3 where id = $4`
+	commandTag, err := categoryRepository.dbPool.Exec(ctx, updateSql, category.Name, category.ParentId, category.AttributeSchema, category.Id)
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return errors.New(fmt.Sprintf("Category already exists with name %s", category.Name))
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while updating category with id: %d", category.Id))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Category not found with id %d", category.Id))
+	}
+
+	log.Infof("Category %d updated", category.Id)
+
+	return nil
+}
+
+func (categoryRepository *CategoryRepository) DeleteById(categoryId int64) error {
+	ctx := context.Background()
+	deleteSql := `Delete from categories where id = $1`
+	commandTag, err := categoryRepository.dbPool.Exec(ctx, deleteSql, categoryId)
+
+	if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+		return errors.New(fmt.Sprintf("Category %d still has child categories", categoryId))
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while deleting category with id %d", categoryId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Category not found with id %d", categoryId))
+	}
+
+	log.Info("Category deleted")
+
+	return nil
+}
+
+func (categoryRepository *CategoryRepository) AddProduct(categoryId int64, productId int64) error {
+	ctx := context.Background()
+	insertSql := `INSERT INTO product_categories(product_id, category_id) VALUES($1, $2) ON CONFLICT DO NOTHING`
+	_, err := categoryRepository.dbPool.Exec(ctx, insertSql, productId, categoryId)
+
+	if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+		return errors.New(fmt.Sprintf("Product %d or category %d not found", productId, categoryId))
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while adding product %d to category %d", productId, categoryId))
+	}
+
+	log.Infof("Product %d added to category %d", productId, categoryId)
+
+	return nil
+}
+
+func (categoryRepository *CategoryRepository) RemoveProduct(categoryId int64, productId int64) error {
+	ctx := context.Background()
+	deleteSql := `Delete from product_categories where product_id = $1 and category_id = $2`
+	commandTag, err := categoryRepository.dbPool.Exec(ctx, deleteSql, productId, categoryId)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while removing product %d from category %d", productId, categoryId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Product %d is not in category %d", productId, categoryId))
+	}
+
+	log.Infof("Product %d removed from category %d", productId, categoryId)
+
+	return nil
+}
+
+func extractCategoriesFromRows(categoryRows pgx.Rows) []domain.Category {
+	var categories = []domain.Category{}
+
+	for categoryRows.Next() {
+		var category domain.Category
+
+		err := categoryRows.Scan(&category.Id, &category.Name, &category.ParentId, &category.AttributeSchema)
+
+		if err != nil {
+			log.Errorf("Error while scanning category %v", err)
+			continue
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories
+}
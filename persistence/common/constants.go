@@ -1,3 +1,6 @@
 package common
 
 var NOT_FOUND = "no rows in result set"
+
+var UNIQUE_VIOLATION = "23505"
+var FOREIGN_KEY_VIOLATION = "23503"
@@ -0,0 +1,140 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"time"
+)
+
+type IIdempotencyRepository interface {
+	Claim(key string, route string, requestHash string, window time.Duration, inFlightTimeout time.Duration) (domain.IdempotencyRecord, bool, error)
+	Complete(claim domain.IdempotencyRecord, response domain.StoredResponse) error
+	Release(claim domain.IdempotencyRecord) error
+	PurgeExpired() (int64, error)
+}
+
+type IdempotencyRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(dbPool *pgxpool.Pool) IIdempotencyRepository {
+	return &IdempotencyRepository{dbPool: dbPool}
+}
+
+// claimIdempotencyKeySql takes the key over when it is new, expired, or was
+// left in flight for longer than $5 by a request that never completed.
+const claimIdempotencyKeySql = `INSERT INTO idempotency_keys(idempotency_key, route, request_hash, expires_at) VALUES($1, $2, $3, now() + $4 * interval '1 millisecond')
+ON CONFLICT (idempotency_key, route) DO UPDATE SET request_hash = EXCLUDED.request_hash, expires_at = EXCLUDED.expires_at,
+created_at = now(), status_code = NULL, headers = NULL, body = NULL
+WHERE idempotency_keys.expires_at <= now()
+OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= now() - $5 * interval '1 millisecond')
+RETURNING created_at, expires_at`
+
+// Claim stores the key as in flight for window unless another request holds
+// it. It returns true when the caller got the key, and otherwise the record
+// of the request that holds it.
+func (idempotencyRepository *IdempotencyRepository) Claim(key string, route string, requestHash string, window time.Duration, inFlightTimeout time.Duration) (domain.IdempotencyRecord, bool, error) {
+	ctx := context.Background()
+	record := domain.IdempotencyRecord{Key: key, Route: route, RequestHash: requestHash}
+
+	// The holder may complete and expire, or release the key, between both
+	// statements, in which case claiming is tried again.
+	for attempt := 0; attempt < 3; attempt++ {
+		err := idempotencyRepository.dbPool.QueryRow(ctx, claimIdempotencyKeySql, key, route, requestHash,
+			window.Milliseconds(), inFlightTimeout.Milliseconds()).Scan(&record.CreatedAt, &record.ExpiresAt)
+
+		if err == nil {
+			return record, true, nil
+		}
+
+		if err.Error() != common.NOT_FOUND {
+			log.Errorf("Error while claiming idempotency key %s %v", key, err)
+			return domain.IdempotencyRecord{}, false, errors.New("Error while claiming idempotency key")
+		}
+
+		existing, err := idempotencyRepository.get(ctx, key, route)
+
+		if err == nil {
+			return existing, false, nil
+		}
+
+		if err.Error() != common.NOT_FOUND {
+			log.Errorf("Error while getting idempotency key %s %v", key, err)
+			return domain.IdempotencyRecord{}, false, errors.New("Error while claiming idempotency key")
+		}
+	}
+
+	return domain.IdempotencyRecord{}, false, errors.New("Error while claiming idempotency key")
+}
+
+func (idempotencyRepository *IdempotencyRepository) get(ctx context.Context, key string, route string) (domain.IdempotencyRecord, error) {
+	getSql := `Select idempotency_key, route, request_hash, status_code, headers, body, created_at, expires_at
+from idempotency_keys where idempotency_key = $1 and route = $2`
+
+	var record domain.IdempotencyRecord
+	var statusCode *int
+	var headers map[string][]string
+	var body []byte
+
+	err := idempotencyRepository.dbPool.QueryRow(ctx, getSql, key, route).Scan(&record.Key, &record.Route, &record.RequestHash,
+		&statusCode, &headers, &body, &record.CreatedAt, &record.ExpiresAt)
+
+	if err != nil {
+		return domain.IdempotencyRecord{}, err
+	}
+
+	if statusCode != nil {
+		record.Response = &domain.StoredResponse{StatusCode: *statusCode, Headers: headers, Body: body}
+	}
+
+	return record, nil
+}
+
+// whereClaimSql matches the key only while it is still held by the claim,
+// not when it was taken over after the in flight timeout.
+const whereClaimSql = ` where idempotency_key = $1 and route = $2 and created_at = $3 and status_code IS NULL`
+
+func (idempotencyRepository *IdempotencyRepository) Complete(claim domain.IdempotencyRecord, response domain.StoredResponse) error {
+	ctx := context.Background()
+	completeSql := `Update idempotency_keys set status_code = $4, headers = $5, body = $6` + whereClaimSql
+
+	_, err := idempotencyRepository.dbPool.Exec(ctx, completeSql, claim.Key, claim.Route, claim.CreatedAt, response.StatusCode,
+		response.Headers, response.Body)
+
+	if err != nil {
+		log.Errorf("Error while storing response of idempotency key %s %v", claim.Key, err)
+		return errors.New("Error while storing response of idempotency key")
+	}
+
+	return nil
+}
+
+// Release forgets an in flight key, so that the request can be retried.
+func (idempotencyRepository *IdempotencyRepository) Release(claim domain.IdempotencyRecord) error {
+	ctx := context.Background()
+
+	_, err := idempotencyRepository.dbPool.Exec(ctx, `Delete from idempotency_keys`+whereClaimSql, claim.Key, claim.Route, claim.CreatedAt)
+
+	if err != nil {
+		log.Errorf("Error while releasing idempotency key %s %v", claim.Key, err)
+		return errors.New("Error while releasing idempotency key")
+	}
+
+	return nil
+}
+
+func (idempotencyRepository *IdempotencyRepository) PurgeExpired() (int64, error) {
+	ctx := context.Background()
+	commandTag, err := idempotencyRepository.dbPool.Exec(ctx, `Delete from idempotency_keys where expires_at <= now()`)
+
+	if err != nil {
+		log.Errorf("Error while purging idempotency keys %v", err)
+		return 0, errors.New("Error while purging idempotency keys")
+	}
+
+	return commandTag.RowsAffected(), nil
+}
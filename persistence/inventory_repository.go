@@ -0,0 +1,152 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+// IInventoryRepository keeps stock per product and store. Reserve, Release,
+// Commit and Adjust are single conditional updates, so two requests racing
+// for the last unit can not both succeed; the boolean result is false when
+// the condition did not hold and nothing was changed.
+type IInventoryRepository interface {
+	GetAllByProductId(productId int64) []domain.Inventory
+	Get(productId int64, storeId int64) (domain.Inventory, error)
+	Adjust(productId int64, storeId int64, delta int32) (bool, error)
+	Reserve(productId int64, storeId int64, quantity int32) (bool, error)
+	Release(productId int64, storeId int64, quantity int32) (bool, error)
+	Commit(productId int64, storeId int64, quantity int32) (bool, error)
+}
+
+type InventoryRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewInventoryRepository(dbPool *pgxpool.Pool) IInventoryRepository {
+	return &InventoryRepository{dbPool: dbPool}
+}
+
+const selectInventorySql = `Select inventory.product_id, inventory.store_id, stores.name, inventory.on_hand, inventory.reserved
+from inventory join stores on stores.id = inventory.store_id`
+
+func (inventoryRepository *InventoryRepository) GetAllByProductId(productId int64) []domain.Inventory {
+	ctx := context.Background()
+	inventoryRows, err := inventoryRepository.dbPool.Query(ctx, selectInventorySql+" where inventory.product_id = $1 order by inventory.store_id", productId)
+
+	if err != nil {
+		log.Errorf("Error while getting inventory of product %d %v", productId, err)
+		return []domain.Inventory{}
+	}
+
+	var inventories = []domain.Inventory{}
+
+	for inventoryRows.Next() {
+		var inventory domain.Inventory
+
+		scanErr := inventoryRows.Scan(&inventory.ProductId, &inventory.StoreId, &inventory.Store, &inventory.OnHand, &inventory.Reserved)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning inventory %v", scanErr)
+			continue
+		}
+
+		inventories = append(inventories, inventory)
+	}
+
+	return inventories
+}
+
+func (inventoryRepository *InventoryRepository) Get(productId int64, storeId int64) (domain.Inventory, error) {
+	ctx := context.Background()
+	getSql := selectInventorySql + ` where inventory.product_id = $1 and inventory.store_id = $2`
+	queryRow := inventoryRepository.dbPool.QueryRow(ctx, getSql, productId, storeId)
+
+	var inventory domain.Inventory
+	scanErr := queryRow.Scan(&inventory.ProductId, &inventory.StoreId, &inventory.Store, &inventory.OnHand, &inventory.Reserved)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Inventory{}, errors.New(fmt.Sprintf("Inventory not found for product %d in store %d", productId, storeId))
+	}
+
+	if scanErr != nil {
+		return domain.Inventory{}, errors.New(fmt.Sprintf("Error while getting inventory for product %d in store %d", productId, storeId))
+	}
+
+	return inventory, nil
+}
+
+// Adjust adds delta to the quantity on hand, creating the row on first use.
+// A negative delta that would drop on hand below what is already reserved
+// leaves the row untouched.
+func (inventoryRepository *InventoryRepository) Adjust(productId int64, storeId int64, delta int32) (bool, error) {
+	ctx := context.Background()
+	adjustSql := `INSERT INTO inventory(product_id, store_id, on_hand) VALUES($1, $2, $3)
+ON CONFLICT (product_id, store_id) DO UPDATE SET on_hand = inventory.on_hand + EXCLUDED.on_hand`
+
+	if delta < 0 {
+		adjustSql = `UPDATE inventory SET on_hand = on_hand + $3
+WHERE product_id = $1 AND store_id = $2 AND on_hand + $3 >= reserved`
+	}
+
+	commandTag, err := inventoryRepository.dbPool.Exec(ctx, adjustSql, productId, storeId, delta)
+
+	if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+		return false, errors.New(fmt.Sprintf("Product %d or store %d not found", productId, storeId))
+	}
+
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("Error while adjusting inventory for product %d in store %d", productId, storeId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	log.Infof("Inventory of product %d in store %d adjusted by %d", productId, storeId, delta)
+
+	return true, nil
+}
+
+func (inventoryRepository *InventoryRepository) Reserve(productId int64, storeId int64, quantity int32) (bool, error) {
+	reserveSql := `UPDATE inventory SET reserved = reserved + $3
+WHERE product_id = $1 AND store_id = $2 AND on_hand - reserved >= $3`
+
+	return inventoryRepository.conditionalUpdate("reserving", reserveSql, productId, storeId, quantity)
+}
+
+func (inventoryRepository *InventoryRepository) Release(productId int64, storeId int64, quantity int32) (bool, error) {
+	releaseSql := `UPDATE inventory SET reserved = reserved - $3
+WHERE product_id = $1 AND store_id = $2 AND reserved >= $3`
+
+	return inventoryRepository.conditionalUpdate("releasing", releaseSql, productId, storeId, quantity)
+}
+
+func (inventoryRepository *InventoryRepository) Commit(productId int64, storeId int64, quantity int32) (bool, error) {
+	commitSql := `UPDATE inventory SET reserved = reserved - $3, on_hand = on_hand - $3
+WHERE product_id = $1 AND store_id = $2 AND reserved >= $3`
+
+	return inventoryRepository.conditionalUpdate("committing", commitSql, productId, storeId, quantity)
+}
+
+func (inventoryRepository *InventoryRepository) conditionalUpdate(action string, updateSql string, productId int64, storeId int64, quantity int32) (bool, error) {
+	ctx := context.Background()
+	commandTag, err := inventoryRepository.dbPool.Exec(ctx, updateSql, productId, storeId, quantity)
+
+	if err != nil {
+		log.Errorf("Error while %s inventory %v", action, err)
+		return false, errors.New(fmt.Sprintf("Error while %s inventory for product %d in store %d", action, productId, storeId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	log.Infof("Inventory of product %d in store %d: %s %d", productId, storeId, action, quantity)
+
+	return true, nil
+}
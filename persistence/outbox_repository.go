@@ -0,0 +1,287 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example.com/product-api/domain"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"sort"
+	"time"
+)
+
+type IOutboxRepository interface {
+	ProcessBatch(limit int, lease time.Duration, process func(messages []domain.OutboxMessage) []domain.OutboxOutcome) (int, error)
+	GetLastId() (int64, error)
+	GetAfter(afterId int64, limit int) ([]domain.ProductEvent, error)
+	DeleteDeliveredBefore(cutoff time.Time) (int64, error)
+}
+
+type OutboxRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewOutboxRepository(dbPool *pgxpool.Pool) IOutboxRepository {
+	return &OutboxRepository{dbPool: dbPool}
+}
+
+// outboxPayload is how a product event is stored in the outbox.
+type outboxPayload struct {
+	Id            int64                  `json:"id"`
+	Name          string                 `json:"name,omitempty"`
+	Price         float32                `json:"price,omitempty"`
+	Discount      float32                `json:"discount,omitempty"`
+	StoreId       int64                  `json:"storeId"`
+	Store         string                 `json:"store"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	PreviousPrice float32                `json:"previousPrice,omitempty"`
+}
+
+// insertOutboxSql stores the event and announces it on the product changes
+// channel, with the id of the outbox row put in front of the fields of the
+// notification $5. Postgres only delivers the notification when the
+// transaction commits.
+const insertOutboxSql = `WITH outbox_row AS (
+    INSERT INTO outbox(event_type, product_id, payload) VALUES($1, $2, $3) RETURNING id
+)
+SELECT pg_notify($4, '{"outboxId":' || id || ',' || substr($5, 2)) FROM outbox_row`
+
+// insertOutboxEvent records the event in the transaction of the change it
+// describes, so the event exists if and only if the change was committed.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, event domain.ProductEvent) error {
+	args, err := outboxEventArgs(event)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, insertOutboxSql, args...)
+
+	if err != nil {
+		log.Errorf("Error while writing %s event of product %d to outbox %v", event.Type, event.Product.Id, err)
+	}
+
+	return err
+}
+
+func outboxEventArgs(event domain.ProductEvent) ([]interface{}, error) {
+	payload, err := marshalOutboxPayload(event)
+
+	if err != nil {
+		return nil, err
+	}
+
+	notification, err := marshalProductNotification(event)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{event.Type, event.Product.Id, string(payload), PRODUCT_CHANGES_CHANNEL, string(notification)}, nil
+}
+
+func marshalOutboxPayload(event domain.ProductEvent) ([]byte, error) {
+	return json.Marshal(toOutboxPayload(event))
+}
+
+func toOutboxPayload(event domain.ProductEvent) outboxPayload {
+	return outboxPayload{
+		Id:            event.Product.Id,
+		Name:          event.Product.Name,
+		Price:         event.Product.Price,
+		Discount:      event.Product.Discount,
+		StoreId:       event.Product.StoreId,
+		Store:         event.Product.Store,
+		Attributes:    event.Product.Attributes,
+		PreviousPrice: event.PreviousPrice,
+	}
+}
+
+// leaseOutboxMessagesSql claims up to $1 due messages by moving their next
+// attempt past the lease of $2 milliseconds. SKIP LOCKED keeps concurrent
+// relays, in this instance or others, from claiming the same messages.
+const leaseOutboxMessagesSql = `UPDATE outbox SET next_attempt_at = now() + $2 * interval '1 millisecond'
+WHERE id IN (SELECT id FROM outbox WHERE dead_lettered_at IS NULL AND delivered_at IS NULL AND next_attempt_at <= now() ORDER BY id LIMIT $1
+    FOR UPDATE SKIP LOCKED)
+RETURNING id, event_type, payload, created_at, attempts, delivered_sinks, next_attempt_at`
+
+// ProcessBatch leases up to limit due messages, hands them to process and
+// stores its outcomes. Delivered messages are kept for GetAfter until
+// DeleteDeliveredBefore removes them. The lease is committed before process runs, so slow
+// sinks hold neither a connection nor row locks; the messages of a relay
+// that dies mid-batch are picked up again once their lease ran out. An
+// outcome is only stored while the message is still under this lease, as a
+// lease that ran out may already have passed it to another relay. It returns
+// how many messages were processed.
+func (outboxRepository *OutboxRepository) ProcessBatch(limit int, lease time.Duration, process func(messages []domain.OutboxMessage) []domain.OutboxOutcome) (int, error) {
+	ctx := context.Background()
+	messages, leasedUntil, err := leaseOutboxMessages(ctx, outboxRepository.dbPool, limit, lease)
+
+	if err != nil {
+		log.Errorf("Error while leasing outbox messages %v", err)
+		return 0, errors.New("Error while relaying outbox")
+	}
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, outcome := range process(messages) {
+		if outcome.DeliveredSinks == nil {
+			outcome.DeliveredSinks = []string{}
+		}
+
+		switch {
+		case outcome.Delivered:
+			batch.Queue(`UPDATE outbox SET attempts = attempts + 1, delivered_sinks = $2, last_error = NULL, delivered_at = now() WHERE id = $1`,
+				outcome.Id, outcome.DeliveredSinks)
+		case outcome.DeadLetter:
+			batch.Queue(`UPDATE outbox SET attempts = attempts + 1, delivered_sinks = $2, last_error = $3, dead_lettered_at = now()
+WHERE id = $1 AND next_attempt_at = $4`, outcome.Id, outcome.DeliveredSinks, outcome.Error, leasedUntil[outcome.Id])
+		default:
+			batch.Queue(`UPDATE outbox SET attempts = attempts + 1, delivered_sinks = $2, last_error = $3, next_attempt_at = $4
+WHERE id = $1 AND next_attempt_at = $5`, outcome.Id, outcome.DeliveredSinks, outcome.Error, outcome.NextAttemptAt, leasedUntil[outcome.Id])
+		}
+	}
+
+	if batch.Len() == 0 {
+		return len(messages), nil
+	}
+
+	err = outboxRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+
+	if err != nil {
+		log.Errorf("Error while storing outbox outcomes %v", err)
+		return 0, errors.New("Error while relaying outbox")
+	}
+
+	return len(messages), nil
+}
+
+// leaseOutboxMessages returns the leased messages in id order together with
+// the end of each lease.
+func leaseOutboxMessages(ctx context.Context, dbPool *pgxpool.Pool, limit int, lease time.Duration) ([]domain.OutboxMessage, map[int64]time.Time, error) {
+	rows, err := dbPool.Query(ctx, leaseOutboxMessagesSql, limit, lease.Milliseconds())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	var messages []domain.OutboxMessage
+	leasedUntil := map[int64]time.Time{}
+
+	for rows.Next() {
+		var message domain.OutboxMessage
+		var payload outboxPayload
+		var createdAt, until time.Time
+
+		err = rows.Scan(&message.Id, &message.Event.Type, &payload, &createdAt, &message.Attempts, &message.DeliveredSinks, &until)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		message.Event = payload.toProductEvent(message.Event.Type, createdAt)
+
+		messages = append(messages, message)
+		leasedUntil[message.Id] = until
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Id < messages[j].Id
+	})
+
+	return messages, leasedUntil, rows.Err()
+}
+
+func (outboxRepository *OutboxRepository) GetLastId() (int64, error) {
+	ctx := context.Background()
+
+	var lastId int64
+	err := outboxRepository.dbPool.QueryRow(ctx, `SELECT COALESCE(max(id), 0) FROM outbox`).Scan(&lastId)
+
+	if err != nil {
+		log.Errorf("Error while reading last outbox id %v", err)
+		return 0, errors.New("Error while reading outbox")
+	}
+
+	return lastId, nil
+}
+
+// GetAfter returns the latest limit events with an id above afterId in id
+// order, whether they were delivered yet or not. Their Id is the outbox id.
+func (outboxRepository *OutboxRepository) GetAfter(afterId int64, limit int) ([]domain.ProductEvent, error) {
+	ctx := context.Background()
+	getAfterSql := `SELECT id, event_type, payload, created_at FROM outbox WHERE id > $1 ORDER BY id DESC LIMIT $2`
+	rows, err := outboxRepository.dbPool.Query(ctx, getAfterSql, afterId, limit)
+
+	if err != nil {
+		log.Errorf("Error while reading outbox after %d %v", afterId, err)
+		return nil, errors.New("Error while reading outbox")
+	}
+
+	defer rows.Close()
+
+	events := []domain.ProductEvent{}
+
+	for rows.Next() {
+		var id int64
+		var eventType string
+		var payload outboxPayload
+		var createdAt time.Time
+
+		if err := rows.Scan(&id, &eventType, &payload, &createdAt); err != nil {
+			log.Errorf("Error while reading outbox after %d %v", afterId, err)
+			return nil, errors.New("Error while reading outbox")
+		}
+
+		event := payload.toProductEvent(eventType, createdAt)
+		event.Id = uint64(id)
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Id < events[j].Id
+	})
+
+	return events, rows.Err()
+}
+
+// DeleteDeliveredBefore removes the messages delivered before cutoff and
+// returns how many there were.
+func (outboxRepository *OutboxRepository) DeleteDeliveredBefore(cutoff time.Time) (int64, error) {
+	ctx := context.Background()
+	commandTag, err := outboxRepository.dbPool.Exec(ctx, `DELETE FROM outbox WHERE delivered_at < $1`, cutoff)
+
+	if err != nil {
+		log.Errorf("Error while deleting delivered outbox messages %v", err)
+		return 0, errors.New("Error while deleting delivered outbox messages")
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+func (payload outboxPayload) toProductEvent(eventType string, occurredAt time.Time) domain.ProductEvent {
+	return domain.ProductEvent{
+		Type: eventType,
+		Product: domain.Product{
+			Id:         payload.Id,
+			Name:       payload.Name,
+			Price:      payload.Price,
+			Discount:   payload.Discount,
+			StoreId:    payload.StoreId,
+			Store:      payload.Store,
+			Attributes: payload.Attributes,
+		},
+		PreviousPrice: payload.PreviousPrice,
+		OccurredAt:    occurredAt,
+	}
+}
@@ -0,0 +1,253 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// returningProductSql returns the id and store of the written product. A
+// store inserted by the same statement is not visible to the subquery, so
+// callers creating stores fall back to the name they inserted.
+const returningProductSql = ` RETURNING products.id, products.store_id, (SELECT name FROM stores WHERE stores.id = products.store_id)`
+
+// returningProductRowSql returns the whole updated product in the column
+// order of scanProduct, so events for updates and deletes carry the product
+// as it is stored rather than only what the request sent.
+const returningProductRowSql = ` RETURNING products.id, products.name, products.price, products.discount, products.store_id,
+(SELECT name FROM stores WHERE stores.id = products.store_id), products.attributes, products.version, products.updated_at,
+COALESCE(products.external_ref, ''), products.discontinued_at IS NOT NULL`
+
+const (
+	bulkCreateByStoreIdSql = `INSERT INTO products(name, price, discount, store_id, attributes)
+SELECT $1, $2, $3, stores.id, $5 FROM stores WHERE stores.id = $4` + returningProductSql
+	bulkCreateByStoreNameSql = `WITH new_store AS (
+    INSERT INTO stores(name) VALUES($4) ON CONFLICT ((lower(name))) DO NOTHING RETURNING id
+)
+INSERT INTO products(name, price, discount, store_id, attributes)
+VALUES($1, $2, $3, COALESCE((SELECT id FROM new_store), (SELECT id FROM stores WHERE lower(name) = lower($4))), $5)` + returningProductSql
+	bulkUpdatePriceSql = `Update products set price = $1 where id = $2 and deleted_at IS NULL` + returningProductRowSql
+	bulkDeleteSql      = `Update products set deleted_at = now() where id = $1 and deleted_at IS NULL` + returningProductRowSql
+)
+
+// ApplyBulk sends the operations in one batch inside a single transaction.
+// In atomic mode any failed item rolls back the whole transaction. Otherwise
+// every item runs under its own savepoint: a failing item is rolled back to
+// its savepoint and the remaining items are sent again in a new batch, so a
+// request costs one round trip plus one per failed item.
+func (productRepository *ProductRepository) ApplyBulk(operations []domain.BulkOperation, atomic bool) ([]domain.BulkResult, error) {
+	ctx := context.Background()
+	results := make([]domain.BulkResult, len(operations))
+	products := make([]domain.Product, len(operations))
+
+	for i, operation := range operations {
+		results[i] = domain.BulkResult{Action: operation.Action, Index: operation.Index}
+	}
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+
+	if err != nil {
+		log.Errorf("Error while starting bulk transaction %v", err)
+		return nil, errors.New("Error while applying bulk operations")
+	}
+
+	defer tx.Rollback(ctx)
+
+	failed := false
+
+	for start := 0; start < len(operations); {
+		next, batchErr := sendBulkBatch(ctx, tx, operations[start:], results[start:], products[start:], !atomic)
+
+		for _, result := range results[start : start+next] {
+			if len(result.Error) != 0 {
+				failed = true
+			}
+		}
+
+		if batchErr == nil {
+			break
+		}
+
+		if atomic {
+			return results, errors.New("Bulk operations rolled back")
+		}
+
+		_, err = tx.Exec(ctx, "ROLLBACK TO SAVEPOINT bulk_item; RELEASE SAVEPOINT bulk_item")
+
+		if err != nil {
+			log.Errorf("Error while rolling back bulk item %v", err)
+			return results, errors.New("Error while applying bulk operations")
+		}
+
+		start += next
+	}
+
+	if atomic && failed {
+		return results, errors.New("Bulk operations rolled back")
+	}
+
+	err = insertBulkOutboxEvents(ctx, tx, results, products)
+
+	if err != nil {
+		log.Errorf("Error while writing bulk events to outbox %v", err)
+		return results, errors.New("Error while applying bulk operations")
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		log.Errorf("Error while committing bulk transaction %v", err)
+		return results, errors.New("Error while applying bulk operations")
+	}
+
+	log.Infof("%d bulk product operations applied", len(operations))
+
+	return results, nil
+}
+
+// sendBulkBatch queues the operations and reads their results and written
+// products until the first database error. It returns how many results it
+// filled in and that error; later operations were discarded by the server
+// and must be resent.
+func sendBulkBatch(ctx context.Context, tx pgx.Tx, operations []domain.BulkOperation, results []domain.BulkResult, products []domain.Product,
+	savepoints bool) (int, error) {
+	batch := &pgx.Batch{}
+
+	for _, operation := range operations {
+		if savepoints {
+			batch.Queue("SAVEPOINT bulk_item")
+		}
+
+		queueBulkOperation(batch, operation)
+
+		if savepoints {
+			batch.Queue("RELEASE SAVEPOINT bulk_item")
+		}
+	}
+
+	batchResults := tx.SendBatch(ctx, batch)
+	defer batchResults.Close()
+
+	for i, operation := range operations {
+		if savepoints {
+			if _, err := batchResults.Exec(); err != nil {
+				return i, err
+			}
+		}
+
+		product, err := scanBulkProduct(batchResults.QueryRow(), operation)
+
+		if err == nil {
+			products[i] = product
+			results[i].Id, results[i].StoreId, results[i].Store = product.Id, product.StoreId, product.Store
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			results[i].Error = bulkNotFoundMessage(operation)
+		} else if isPgError(err, common.UNIQUE_VIOLATION) {
+			results[i].Error = ErrDuplicateProduct.Error()
+			return i + 1, err
+		} else if err != nil {
+			log.Errorf("Error while applying bulk %s %v", operation.Action, err)
+			results[i].Error = fmt.Sprintf("Error while applying %s", operation.Action)
+			return i + 1, err
+		}
+
+		if savepoints {
+			if _, err := batchResults.Exec(); err != nil {
+				results[i].Id, results[i].StoreId, results[i].Store = 0, 0, ""
+				products[i] = domain.Product{}
+				results[i].Error = fmt.Sprintf("Error while applying %s", operation.Action)
+				return i + 1, err
+			}
+		}
+	}
+
+	return len(operations), nil
+}
+
+var bulkEventTypes = map[string]string{
+	domain.BULK_CREATE: domain.PRODUCT_CREATED,
+	domain.BULK_UPDATE: domain.PRODUCT_PRICE_CHANGED,
+	domain.BULK_DELETE: domain.PRODUCT_DELETED,
+}
+
+// scanBulkProduct reads the product written by the operation. Creates only
+// return the id and store, so the rest is taken from the request; a store
+// created by the same statement falls back to the name that was inserted.
+func scanBulkProduct(row pgx.Row, operation domain.BulkOperation) (domain.Product, error) {
+	if operation.Action != domain.BULK_CREATE {
+		return scanProduct(row)
+	}
+
+	product := operation.Product
+
+	var store *string
+	err := row.Scan(&product.Id, &product.StoreId, &store)
+
+	if store != nil {
+		product.Store = *store
+	} else {
+		product.Store = domain.NormalizeStoreName(product.Store)
+	}
+
+	return product, err
+}
+
+// insertBulkOutboxEvents records one event per applied operation in a single
+// batch. Bulk price updates do not read the previous price, so their events
+// carry only the new one.
+func insertBulkOutboxEvents(ctx context.Context, tx pgx.Tx, results []domain.BulkResult, products []domain.Product) error {
+	batch := &pgx.Batch{}
+
+	for i, result := range results {
+		if len(result.Error) != 0 {
+			continue
+		}
+
+		args, err := outboxEventArgs(domain.ProductEvent{Type: bulkEventTypes[result.Action], Product: products[i]})
+
+		if err != nil {
+			return err
+		}
+
+		batch.Queue(insertOutboxSql, args...)
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+func queueBulkOperation(batch *pgx.Batch, operation domain.BulkOperation) {
+	product := operation.Product
+
+	switch operation.Action {
+	case domain.BULK_CREATE:
+		if product.StoreId != 0 {
+			batch.Queue(bulkCreateByStoreIdSql, product.Name, product.Price, product.Discount, product.StoreId,
+				nonNilProductAttributes(product.Attributes))
+		} else {
+			batch.Queue(bulkCreateByStoreNameSql, product.Name, product.Price, product.Discount, domain.NormalizeStoreName(product.Store),
+				nonNilProductAttributes(product.Attributes))
+		}
+	case domain.BULK_UPDATE:
+		batch.Queue(bulkUpdatePriceSql, product.Price, product.Id)
+	case domain.BULK_DELETE:
+		batch.Queue(bulkDeleteSql, product.Id)
+	}
+}
+
+func bulkNotFoundMessage(operation domain.BulkOperation) string {
+	if operation.Action == domain.BULK_CREATE {
+		return fmt.Sprintf("Store not found with id %d", operation.Product.StoreId)
+	}
+
+	return fmt.Sprintf("Product not found with id %d", operation.Product.Id)
+}
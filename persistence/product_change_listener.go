@@ -0,0 +1,206 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"example.com/product-api/domain"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"time"
+)
+
+const (
+	// PRODUCT_CHANGES_CHANNEL is the channel every committed product event
+	// is announced on.
+	PRODUCT_CHANGES_CHANNEL = "product_changes"
+	// PRODUCT_CHANGE_LISTENER_NAME is the application_name of the listening
+	// connection, which makes it easy to find in pg_stat_activity.
+	PRODUCT_CHANGE_LISTENER_NAME = "product-change-listener"
+	// STORE_RENAMED_NOTIFICATION is the type of the notification sent on the
+	// product changes channel when a store is renamed, which changes the
+	// store name of all of its products.
+	STORE_RENAMED_NOTIFICATION = "store_renamed"
+
+	// maxNotificationPayload stays under the 8000 byte limit of NOTIFY,
+	// leaving room for the outbox id added by insertOutboxSql.
+	maxNotificationPayload = 7900
+	minReconnectDelay      = 500 * time.Millisecond
+	maxReconnectDelay      = 30 * time.Second
+)
+
+// IProductChangeHandler receives the product changes committed by any
+// instance. Resync is called whenever the listener starts listening, on the
+// first connect as well as after a reconnect: changes made before were not
+// received, so whatever the handler derived from the database until then
+// has to be rebuilt or dropped.
+type IProductChangeHandler interface {
+	ProductChanged(event domain.ProductEvent)
+	Resync()
+}
+
+// IStoreChangeHandler is implemented by handlers that also keep the store
+// name of products, and have to drop it when the store is renamed.
+type IStoreChangeHandler interface {
+	StoreRenamed(storeId int64)
+}
+
+// productNotification is the payload of a product changes notification.
+// Large products do not fit into a notification; their name and attributes
+// are left out and Truncated is set, and the listener reads them instead.
+// OutboxId is only known once the outbox row is inserted, so insertOutboxSql
+// adds it to the marshalled notification.
+type productNotification struct {
+	OutboxId int64  `json:"outboxId,omitempty"`
+	Type     string `json:"type"`
+	outboxPayload
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func marshalProductNotification(event domain.ProductEvent) ([]byte, error) {
+	notification := productNotification{Type: event.Type, outboxPayload: toOutboxPayload(event)}
+
+	payload, err := json.Marshal(notification)
+
+	if err != nil || len(payload) <= maxNotificationPayload {
+		return payload, err
+	}
+
+	notification.Name = ""
+	notification.Attributes = nil
+	notification.Truncated = true
+
+	return json.Marshal(notification)
+}
+
+// ProductChangeListener holds a connection of its own, outside the pool,
+// that LISTENs on the product changes channel. A pooled connection would
+// go back to the pool and stop receiving notifications.
+type ProductChangeListener struct {
+	connConfig        *pgx.ConnConfig
+	productRepository IProductRepository
+}
+
+func NewProductChangeListener(dbPool *pgxpool.Pool, productRepository IProductRepository) *ProductChangeListener {
+	connConfig := dbPool.Config().ConnConfig.Copy()
+	connConfig.RuntimeParams["application_name"] = PRODUCT_CHANGE_LISTENER_NAME
+
+	return &ProductChangeListener{
+		connConfig:        connConfig,
+		productRepository: productRepository,
+	}
+}
+
+// Listen passes every notification to the handlers until ctx is done. When
+// the connection is lost it reconnects with a growing delay. Every time it
+// listens, the first time included, it calls Resync on the handlers.
+func (productChangeListener *ProductChangeListener) Listen(ctx context.Context, handlers ...IProductChangeHandler) {
+	reconnectDelay := minReconnectDelay
+
+	for ctx.Err() == nil {
+		err := productChangeListener.listenOnce(ctx, func() {
+			log.Info("Product change listener is listening, resyncing")
+			for _, handler := range handlers {
+				handler.Resync()
+			}
+			reconnectDelay = minReconnectDelay
+		}, handlers)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Errorf("Product change listener lost its connection, retrying in %v %v", reconnectDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+
+		reconnectDelay = exponentialDelay(reconnectDelay)
+	}
+}
+
+func (productChangeListener *ProductChangeListener) listenOnce(ctx context.Context, onListening func(), handlers []IProductChangeHandler) error {
+	conn, err := pgx.ConnectConfig(ctx, productChangeListener.connConfig)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+PRODUCT_CHANGES_CHANNEL); err != nil {
+		return err
+	}
+
+	onListening()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		event, ok := productChangeListener.toProductEvent(notification.Payload)
+
+		if !ok {
+			continue
+		}
+
+		if event.Type == STORE_RENAMED_NOTIFICATION {
+			for _, handler := range handlers {
+				if storeChangeHandler, ok := handler.(IStoreChangeHandler); ok {
+					storeChangeHandler.StoreRenamed(event.Product.StoreId)
+				}
+			}
+			continue
+		}
+
+		for _, handler := range handlers {
+			handler.ProductChanged(event)
+		}
+	}
+}
+
+func (productChangeListener *ProductChangeListener) toProductEvent(payload string) (domain.ProductEvent, bool) {
+	var notification productNotification
+
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		log.Errorf("Error while reading product change notification %v", err)
+		return domain.ProductEvent{}, false
+	}
+
+	event := domain.ProductEvent{
+		Id:   uint64(notification.OutboxId),
+		Type: notification.Type,
+		Product: domain.Product{
+			Id:         notification.Id,
+			Name:       notification.Name,
+			Price:      notification.Price,
+			Discount:   notification.Discount,
+			StoreId:    notification.StoreId,
+			Store:      notification.Store,
+			Attributes: notification.Attributes,
+		},
+		PreviousPrice: notification.PreviousPrice,
+		OccurredAt:    time.Now(),
+	}
+
+	if notification.Truncated {
+		if product, err := productChangeListener.productRepository.GetById(notification.Id); err == nil {
+			event.Product.Name, event.Product.Attributes = product.Name, product.Attributes
+		}
+	}
+
+	return event, true
+}
+
+func exponentialDelay(delay time.Duration) time.Duration {
+	if delay*2 > maxReconnectDelay {
+		return maxReconnectDelay
+	}
+	return delay * 2
+}
@@ -9,17 +9,43 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/labstack/gommon/log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type IProductRepository interface {
 	GetAll() []domain.Product
 	GetById(productId int64) (domain.Product, error)
 	GetAllByStore(storeName string) []domain.Product
-	Add(product domain.Product) error
+	GetAllByStoreId(storeId int64) []domain.Product
+	GetAllByFilter(filter domain.ProductFilter) []domain.Product
+	GetPageByFilter(filter domain.ProductFilter, afterId int64, limit int) []domain.Product
+	StreamAllByFilter(filter domain.ProductFilter, consume func(domain.Product) error) error
+	Search(query string, filter domain.ProductFilter, limit int) []domain.ProductSearchResult
+	GetFacets(filter domain.ProductFilter) domain.ProductFacets
+	FindDuplicates(storeName string, threshold float32, limit int) []domain.ProductDuplicate
+	Add(product domain.Product) (domain.Product, error)
 	UpdatePrice(productId int64, newPrice float32) error
+	UpdateAttributes(productId int64, attributes map[string]interface{}) error
 	DeleteById(productId int64) error
+	GetAllDeleted() []domain.DeletedProduct
+	Restore(productId int64) error
+	PurgeDeletedBefore(cutoff time.Time) (int64, error)
+	ApplyBulk(operations []domain.BulkOperation, atomic bool) ([]domain.BulkResult, error)
+	UpsertByExternalRef(product domain.Product) (domain.ProductUpsert, error)
+	SyncByExternalRef(products []domain.Product, full bool) (domain.ProductSyncReport, error)
 }
 
+// ErrDuplicateProduct is returned when the store already has a product with
+// the same name, compared like store names.
+var ErrDuplicateProduct = errors.New("Store already has a product with this name")
+
+// ErrProductNotFound is wrapped by the errors returned for a product id that
+// does not exist.
+var ErrProductNotFound = errors.New("Product not found")
+
 type ProductRepository struct {
 	dbPool *pgxpool.Pool
 }
@@ -28,9 +54,19 @@ func NewProductRepository(dbPool *pgxpool.Pool) IProductRepository {
 	return &ProductRepository{dbPool: dbPool}
 }
 
+const productColumns = `products.id, products.name, products.price, products.discount, products.store_id, stores.name, products.attributes,
+products.version, products.updated_at, COALESCE(products.external_ref, ''), products.discontinued_at IS NOT NULL`
+
+const selectProductsSql = `Select ` + productColumns + `
+from products join stores on stores.id = products.store_id`
+
+// notDeletedSql keeps soft deleted products out of every query except the
+// trash listing.
+const notDeletedSql = `products.deleted_at IS NULL`
+
 func (productRepository *ProductRepository) GetAll() []domain.Product {
 	ctx := context.Background()
-	productRows, err := productRepository.dbPool.Query(ctx, "Select * from products")
+	productRows, err := productRepository.dbPool.Query(ctx, selectProductsSql+" where "+notDeletedSql+" order by products.id")
 
 	if err != nil {
 		log.Error("Couldn't get products")
@@ -42,88 +78,481 @@ func (productRepository *ProductRepository) GetAll() []domain.Product {
 
 func (productRepository *ProductRepository) GetById(productId int64) (domain.Product, error) {
 	ctx := context.Background()
-	getByIdSql := `Select * from products where id = $1`
+	getByIdSql := selectProductsSql + ` where products.id = $1 and ` + notDeletedSql
 	queryRow := productRepository.dbPool.QueryRow(ctx, getByIdSql, productId)
 
-	var id int64
-	var name string
-	var price float32
-	var discount float32
-	var store string
-
-	scanErr := queryRow.Scan(&id, &name, &price, &discount, &store)
+	product, scanErr := scanProduct(queryRow)
 
 	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
-		return domain.Product{}, errors.New(fmt.Sprintf("Product not found with id %d", productId))
+		return domain.Product{}, productNotFound(productId)
 	}
 
 	if scanErr != nil {
 		return domain.Product{}, errors.New(fmt.Sprintf("Error while getting product with id %d", productId))
 	}
 
-	return domain.Product{
-		Id:       id,
-		Name:     name,
-		Price:    price,
-		Discount: discount,
-		Store:    store,
-	}, nil
+	return product, nil
 }
 
 func (productRepository *ProductRepository) GetAllByStore(storeName string) []domain.Product {
 	ctx := context.Background()
 
-	getProductsByStoreNameSql := `Select * from products where store = $1`
+	getProductsByStoreNameSql := selectProductsSql + ` where lower(stores.name) = lower($1) and ` + notDeletedSql + ` order by products.id`
 
-	productRows, err := productRepository.dbPool.Query(ctx, getProductsByStoreNameSql, storeName)
+	productRows, err := productRepository.dbPool.Query(ctx, getProductsByStoreNameSql, domain.NormalizeStoreName(storeName))
 
 	if err != nil {
-		log.Error("Error while getting all products %v", err)
+		log.Errorf("Error while getting all products %v", err)
 		return []domain.Product{}
 	}
 
 	return extractProductsFromRows(productRows)
 }
 
-func (productRepository *ProductRepository) Add(product domain.Product) error {
+func (productRepository *ProductRepository) GetAllByStoreId(storeId int64) []domain.Product {
 	ctx := context.Background()
-	insert_sql := `INSERT INTO products(name, price, discount, store) VALUES($1, $2, $3, $4)`
-	newProduct, err := productRepository.dbPool.Exec(ctx, insert_sql, product.Name, product.Price, product.Discount, product.Store)
+
+	getProductsByStoreIdSql := selectProductsSql + ` where products.store_id = $1 and ` + notDeletedSql + ` order by products.id`
+
+	productRows, err := productRepository.dbPool.Query(ctx, getProductsByStoreIdSql, storeId)
 
 	if err != nil {
-		log.Error("Error while inserting product %v", err)
-		return err
+		log.Errorf("Error while getting products of store %d %v", storeId, err)
+		return []domain.Product{}
 	}
 
-	log.Info(fmt.Printf("Product added with %v", newProduct))
+	return extractProductsFromRows(productRows)
+}
 
-	return nil
+func (productRepository *ProductRepository) GetAllByFilter(filter domain.ProductFilter) []domain.Product {
+	ctx := context.Background()
+
+	whereSql, args := buildProductFilterSql(filter)
+
+	productRows, err := productRepository.dbPool.Query(ctx, selectProductsSql+whereSql+" order by products.id", args...)
+
+	if err != nil {
+		log.Errorf("Error while filtering products %v", err)
+		return []domain.Product{}
+	}
+
+	return extractProductsFromRows(productRows)
+}
+
+// GetPageByFilter returns up to limit products with an id greater than
+// afterId. Paging on the id keeps later pages as cheap as the first one.
+func (productRepository *ProductRepository) GetPageByFilter(filter domain.ProductFilter, afterId int64, limit int) []domain.Product {
+	ctx := context.Background()
+
+	conditions, args := buildProductFilterConditions(filter, nil)
+	args = append(args, afterId, limit)
+	pageSql := selectProductsSql + " where " + strings.Join(conditions, " and ") +
+		fmt.Sprintf(" and products.id > $%d order by products.id limit $%d", len(args)-1, len(args))
+
+	productRows, err := productRepository.dbPool.Query(ctx, pageSql, args...)
+
+	if err != nil {
+		log.Errorf("Error while getting product page %v", err)
+		return []domain.Product{}
+	}
+
+	return extractProductsFromRows(productRows)
+}
+
+// exportFetchSize is how many rows StreamAllByFilter fetches from the cursor
+// at a time.
+const exportFetchSize = 1000
+
+// StreamAllByFilter hands the matching products to consume one at a time.
+// Rows are fetched from a server side cursor in a read only transaction, so
+// memory stays constant however large the catalog is. An error returned by
+// consume stops the stream and is returned as is.
+func (productRepository *ProductRepository) StreamAllByFilter(filter domain.ProductFilter, consume func(domain.Product) error) error {
+	ctx := context.Background()
+
+	tx, err := productRepository.dbPool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+
+	if err != nil {
+		log.Errorf("Error while starting export transaction %v", err)
+		return errors.New("Error while exporting products")
+	}
+
+	defer tx.Rollback(ctx)
+
+	whereSql, args := buildProductFilterSql(filter)
+	_, err = tx.Exec(ctx, "DECLARE product_export NO SCROLL CURSOR FOR "+selectProductsSql+whereSql+" order by products.id", args...)
+
+	if err != nil {
+		log.Errorf("Error while declaring export cursor %v", err)
+		return errors.New("Error while exporting products")
+	}
+
+	for {
+		productRows, err := tx.Query(ctx, fmt.Sprintf("FETCH %d FROM product_export", exportFetchSize))
+
+		if err != nil {
+			log.Errorf("Error while fetching exported products %v", err)
+			return errors.New("Error while exporting products")
+		}
+
+		fetched := 0
+
+		for productRows.Next() {
+			fetched++
+			product, scanErr := scanProduct(productRows)
+
+			if scanErr != nil {
+				productRows.Close()
+				log.Errorf("Error while scanning exported product %v", scanErr)
+				return errors.New("Error while exporting products")
+			}
+
+			if err = consume(product); err != nil {
+				productRows.Close()
+				return err
+			}
+		}
+
+		productRows.Close()
+
+		if productRows.Err() != nil {
+			log.Errorf("Error while reading exported products %v", productRows.Err())
+			return errors.New("Error while exporting products")
+		}
+
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
+
+// Search matches every term of the query as a prefix against the search
+// vector, and also accepts names that are merely similar to the query so that
+// typos like "airfyer" still find "AirFryer". Results are ranked by text rank
+// plus trigram similarity, and the matched terms are wrapped in <mark> tags.
+func (productRepository *ProductRepository) Search(query string, filter domain.ProductFilter, limit int) []domain.ProductSearchResult {
+	ctx := context.Background()
+
+	terms := searchTerms(query)
+
+	if len(terms) == 0 {
+		return []domain.ProductSearchResult{}
+	}
+
+	prefixQuery := strings.Join(terms, ":* & ") + ":*"
+	args := []interface{}{prefixQuery, strings.Join(terms, " ")}
+
+	conditions, args := buildProductFilterConditions(filter, args)
+	conditions = append([]string{"(products.search_vector @@ to_tsquery('simple', $1) OR products.name % $2)"}, conditions...)
+
+	args = append(args, limit)
+	searchSql := `Select ` + productColumns + `,
+ts_rank(products.search_vector, to_tsquery('simple', $1)) + similarity(products.name, $2) AS rank,
+ts_headline('simple', products.name, to_tsquery('simple', $1), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+from products join stores on stores.id = products.store_id
+where ` + strings.Join(conditions, " and ") + fmt.Sprintf(" order by rank desc, products.id limit $%d", len(args))
+
+	resultRows, err := productRepository.dbPool.Query(ctx, searchSql, args...)
+
+	if err != nil {
+		log.Errorf("Error while searching products %v", err)
+		return []domain.ProductSearchResult{}
+	}
+
+	var results = []domain.ProductSearchResult{}
+
+	for resultRows.Next() {
+		var result domain.ProductSearchResult
+		product := &result.Product
+
+		scanErr := resultRows.Scan(&product.Id, &product.Name, &product.Price, &product.Discount, &product.StoreId, &product.Store,
+			&product.Attributes, &product.Version, &product.UpdatedAt, &product.ExternalRef, &product.Discontinued, &result.Rank, &result.Highlight)
+		product.Attributes = nilIfEmpty(product.Attributes)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning search result %v", scanErr)
+			continue
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// GetFacets counts the products matching the filter per tag, per store and
+// per price bucket in a single round trip.
+func (productRepository *ProductRepository) GetFacets(filter domain.ProductFilter) domain.ProductFacets {
+	ctx := context.Background()
+
+	whereSql, args := buildProductFilterSql(filter)
+	args = append(args, domain.PRICE_BUCKET_BOUNDS)
+
+	facetsSql := `WITH filtered AS (
+    Select products.id, products.price, stores.name AS store from products join stores on stores.id = products.store_id` + whereSql + `
+)
+SELECT 'tag', tags.name, count(*) FROM filtered
+    JOIN product_tags ON product_tags.product_id = filtered.id
+    JOIN tags ON tags.id = product_tags.tag_id
+    GROUP BY tags.name
+UNION ALL
+SELECT 'store', store, count(*) FROM filtered GROUP BY store
+UNION ALL
+SELECT 'price', width_bucket(price, ` + fmt.Sprintf("$%d", len(args)) + `::real[])::text, count(*) FROM filtered GROUP BY 2
+ORDER BY 1, 3 DESC, 2`
+
+	facetRows, err := productRepository.dbPool.Query(ctx, facetsSql, args...)
+
+	if err != nil {
+		log.Errorf("Error while counting product facets %v", err)
+		return domain.ProductFacets{}
+	}
+
+	facets := domain.ProductFacets{
+		Tags:         []domain.FacetCount{},
+		Stores:       []domain.FacetCount{},
+		PriceBuckets: []domain.PriceBucketCount{},
+	}
+	priceCounts := map[int]int64{}
+
+	for facetRows.Next() {
+		var facet string
+		var facetCount domain.FacetCount
+
+		scanErr := facetRows.Scan(&facet, &facetCount.Value, &facetCount.Count)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning product facet %v", scanErr)
+			continue
+		}
+
+		switch facet {
+		case "tag":
+			facets.Tags = append(facets.Tags, facetCount)
+		case "store":
+			facets.Stores = append(facets.Stores, facetCount)
+		case "price":
+			bucket, _ := strconv.Atoi(facetCount.Value)
+			priceCounts[bucket] = facetCount.Count
+		}
+	}
+
+	for bucket := 0; bucket <= len(domain.PRICE_BUCKET_BOUNDS); bucket++ {
+		priceBucket := domain.PriceBucketCount{Count: priceCounts[bucket]}
+		if bucket > 0 {
+			priceBucket.From = domain.PRICE_BUCKET_BOUNDS[bucket-1]
+		}
+		if bucket < len(domain.PRICE_BUCKET_BOUNDS) {
+			priceBucket.To = domain.PRICE_BUCKET_BOUNDS[bucket]
+		}
+		facets.PriceBuckets = append(facets.PriceBuckets, priceBucket)
+	}
+
+	return facets
+}
+
+// FindDuplicates pairs the products of a store, or of every store when
+// storeName is empty, whose names have a trigram similarity of at least
+// threshold, most similar first. Trigrams ignore case and punctuation, so
+// "Air-Fryer XL" and "airfryer xl" are found as well.
+func (productRepository *ProductRepository) FindDuplicates(storeName string, threshold float32, limit int) []domain.ProductDuplicate {
+	ctx := context.Background()
+	duplicates := []domain.ProductDuplicate{}
+
+	// The % operator uses the trigram index, with the threshold set for this
+	// transaction only.
+	duplicatesSql := `Select a.id, a.name, a.price, a.discount, a.store_id, stores.name, a.attributes, a.version, a.updated_at,
+b.id, b.name, b.price, b.discount, b.attributes, b.version, b.updated_at, similarity(a.name, b.name) AS similarity
+from products a
+join products b on b.store_id = a.store_id and b.id > a.id and a.name % b.name
+join stores on stores.id = a.store_id
+where a.deleted_at IS NULL and b.deleted_at IS NULL and ($1 = '' or lower(stores.name) = lower($1))
+order by similarity desc, a.id, b.id limit $2`
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`, strconv.FormatFloat(float64(threshold), 'f', -1, 32)); err != nil {
+			return err
+		}
+
+		duplicateRows, err := tx.Query(ctx, duplicatesSql, domain.NormalizeStoreName(storeName), limit)
+
+		if err != nil {
+			return err
+		}
+
+		defer duplicateRows.Close()
+
+		for duplicateRows.Next() {
+			var duplicate domain.ProductDuplicate
+			product, other := &duplicate.Product, &duplicate.Duplicate
+
+			err = duplicateRows.Scan(&product.Id, &product.Name, &product.Price, &product.Discount, &product.StoreId, &product.Store,
+				&product.Attributes, &product.Version, &product.UpdatedAt, &other.Id, &other.Name, &other.Price, &other.Discount,
+				&other.Attributes, &other.Version, &other.UpdatedAt, &duplicate.Similarity)
+
+			if err != nil {
+				return err
+			}
+
+			product.Attributes, other.Attributes = nilIfEmpty(product.Attributes), nilIfEmpty(other.Attributes)
+			other.StoreId, other.Store = product.StoreId, product.Store
+
+			duplicates = append(duplicates, duplicate)
+		}
+
+		return duplicateRows.Err()
+	})
+
+	if err != nil {
+		log.Errorf("Error while finding duplicate products %v", err)
+		return []domain.ProductDuplicate{}
+	}
+
+	return duplicates
 }
 
+// Add stores the product under product.StoreId when it is set. Otherwise the
+// store is looked up by name and created on first use, so callers that only
+// know the store name keep working. The stored product is returned with its
+// id and store filled in.
+func (productRepository *ProductRepository) Add(product domain.Product) (domain.Product, error) {
+	ctx := context.Background()
+
+	insertSql := `INSERT INTO products(name, price, discount, store_id, attributes) VALUES($1, $2, $3, $4, $5)` + returningProductSql
+	args := []interface{}{product.Name, product.Price, product.Discount, product.StoreId, nonNilProductAttributes(product.Attributes)}
+
+	if product.StoreId == 0 {
+		product.Store = domain.NormalizeStoreName(product.Store)
+
+		insertSql = `WITH new_store AS (
+    INSERT INTO stores(name) VALUES($4) ON CONFLICT ((lower(name))) DO NOTHING RETURNING id
+)
+INSERT INTO products(name, price, discount, store_id, attributes)
+VALUES($1, $2, $3, COALESCE((SELECT id FROM new_store), (SELECT id FROM stores WHERE lower(name) = lower($4))), $5)` + returningProductSql
+		args[3] = product.Store
+	}
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var store *string
+		err := tx.QueryRow(ctx, insertSql, args...).Scan(&product.Id, &product.StoreId, &store)
+
+		if err != nil {
+			return err
+		}
+
+		if store != nil {
+			product.Store = *store
+		}
+
+		return insertOutboxEvent(ctx, tx, domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: product})
+	})
+
+	if err != nil {
+		if isPgError(err, common.UNIQUE_VIOLATION) {
+			return domain.Product{}, ErrDuplicateProduct
+		}
+
+		if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+			return domain.Product{}, storeNotFound(product.StoreId)
+		}
+
+		log.Errorf("Error while inserting product %v", err)
+		return domain.Product{}, err
+	}
+
+	log.Infof("Product added to store %s", product.Store)
+
+	return product, nil
+}
+
+// UpdatePrice records a price_changed event only when the price actually
+// changes. The row is locked first so the previous price in the event is the
+// one this update replaced.
 func (productRepository *ProductRepository) UpdatePrice(productId int64, newPrice float32) error {
 	ctx := context.Background()
-	updateSql := `Update products set price = $1 where id = $2`
-	_, err := productRepository.dbPool.Exec(ctx, updateSql, newPrice, productId)
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		lockSql := selectProductsSql + ` where products.id = $1 and ` + notDeletedSql + ` for update of products`
+		product, err := scanProduct(tx.QueryRow(ctx, lockSql, productId))
+
+		if err != nil || product.Price == newPrice {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `Update products set price = $1 where id = $2`, newPrice, productId)
+
+		if err != nil {
+			return err
+		}
+
+		previousPrice := product.Price
+		product.Price = newPrice
+
+		return insertOutboxEvent(ctx, tx, domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: product, PreviousPrice: previousPrice})
+	})
+
+	if err != nil && err.Error() == common.NOT_FOUND {
+		return productNotFound(productId)
+	}
 
 	if err != nil {
 		return errors.New(fmt.Sprintf("Error while updating product with id: %d", productId))
 	}
 
-	log.Info("Product %d price updated with new price %v", productId, newPrice)
+	log.Infof("Product %d price updated with new price %v", productId, newPrice)
 
 	return nil
 }
 
-func (productRepository *ProductRepository) DeleteById(productId int64) error {
+func (productRepository *ProductRepository) UpdateAttributes(productId int64, attributes map[string]interface{}) error {
 	ctx := context.Background()
-	_, getErr := productRepository.GetById(productId)
+	found := false
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		updateSql := `Update products set attributes = $1 where id = $2 and deleted_at IS NULL`
+		commandTag, err := tx.Exec(ctx, updateSql, nonNilProductAttributes(attributes), productId)
+
+		if err != nil || commandTag.RowsAffected() == 0 {
+			return err
+		}
 
-	if getErr != nil {
-		return errors.New("product not found")
+		found = true
+
+		return insertUpdatedEvent(ctx, tx, productId)
+	})
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while updating attributes of product with id: %d", productId))
 	}
 
-	deleteSql := `Delete from products where id = $1`
-	_, err := productRepository.dbPool.Exec(ctx, deleteSql, productId)
+	if !found {
+		return productNotFound(productId)
+	}
+
+	log.Infof("Product %d attributes updated", productId)
+
+	return nil
+}
+
+// DeleteById only moves the product to the trash; it stays restorable until
+// PurgeDeletedBefore removes it for good.
+func (productRepository *ProductRepository) DeleteById(productId int64) error {
+	ctx := context.Background()
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		deleteSql := `Update products set deleted_at = now() where id = $1 and deleted_at IS NULL` + returningProductRowSql
+		product, err := scanProduct(tx.QueryRow(ctx, deleteSql, productId))
+
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx, domain.ProductEvent{Type: domain.PRODUCT_DELETED, Product: product})
+	})
+
+	if err != nil && err.Error() == common.NOT_FOUND {
+		return productNotFound(productId)
+	}
 
 	if err != nil {
 		return errors.New(fmt.Sprintf("Error while deleting product with id %d", productId))
@@ -134,24 +563,235 @@ func (productRepository *ProductRepository) DeleteById(productId int64) error {
 	return nil
 }
 
+func (productRepository *ProductRepository) GetAllDeleted() []domain.DeletedProduct {
+	ctx := context.Background()
+	getDeletedSql := `Select ` + productColumns + `, products.deleted_at
+from products join stores on stores.id = products.store_id
+where products.deleted_at IS NOT NULL order by products.deleted_at desc, products.id`
+	productRows, err := productRepository.dbPool.Query(ctx, getDeletedSql)
+
+	if err != nil {
+		log.Errorf("Error while getting deleted products %v", err)
+		return []domain.DeletedProduct{}
+	}
+
+	var deletedProducts = []domain.DeletedProduct{}
+
+	for productRows.Next() {
+		var deletedProduct domain.DeletedProduct
+		product := &deletedProduct.Product
+
+		scanErr := productRows.Scan(&product.Id, &product.Name, &product.Price, &product.Discount, &product.StoreId, &product.Store,
+			&product.Attributes, &product.Version, &product.UpdatedAt, &product.ExternalRef, &product.Discontinued, &deletedProduct.DeletedAt)
+		product.Attributes = nilIfEmpty(product.Attributes)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning deleted product %v", scanErr)
+			continue
+		}
+
+		deletedProducts = append(deletedProducts, deletedProduct)
+	}
+
+	return deletedProducts
+}
+
+// Restore records an updated event, as the product was already known to
+// consumers before it was deleted.
+func (productRepository *ProductRepository) Restore(productId int64) error {
+	ctx := context.Background()
+	found := false
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		restoreSql := `Update products set deleted_at = NULL where id = $1 and deleted_at IS NOT NULL`
+		commandTag, err := tx.Exec(ctx, restoreSql, productId)
+
+		if err != nil || commandTag.RowsAffected() == 0 {
+			return err
+		}
+
+		found = true
+
+		return insertUpdatedEvent(ctx, tx, productId)
+	})
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return ErrDuplicateProduct
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while restoring product with id %d", productId))
+	}
+
+	if !found {
+		return errors.New(fmt.Sprintf("Deleted product not found with id %d", productId))
+	}
+
+	log.Infof("Product %d restored", productId)
+
+	return nil
+}
+
+// insertUpdatedEvent records an updated event with the product as the
+// transaction sees it after the change.
+func insertUpdatedEvent(ctx context.Context, tx pgx.Tx, productId int64) error {
+	product, err := scanProduct(tx.QueryRow(ctx, selectProductsSql+` where products.id = $1`, productId))
+
+	if err != nil {
+		return err
+	}
+
+	return insertOutboxEvent(ctx, tx, domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: product})
+}
+
+// PurgeDeletedBefore permanently removes the products deleted before cutoff
+// together with their variants, inventory, tags and category links.
+func (productRepository *ProductRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	ctx := context.Background()
+	purgeSql := `Delete from products where deleted_at < $1`
+	commandTag, err := productRepository.dbPool.Exec(ctx, purgeSql, cutoff)
+
+	if err != nil {
+		log.Errorf("Error while purging deleted products %v", err)
+		return 0, errors.New("Error while purging deleted products")
+	}
+
+	log.Infof("%d deleted products purged", commandTag.RowsAffected())
+
+	return commandTag.RowsAffected(), nil
+}
+
+// buildProductFilterSql turns the filter into a where clause with positional
+// parameters. Values never end up in the SQL text itself.
+func buildProductFilterSql(filter domain.ProductFilter) (string, []interface{}) {
+	conditions, args := buildProductFilterConditions(filter, nil)
+
+	return " where " + strings.Join(conditions, " and "), args
+}
+
+// buildProductFilterConditions appends the filter values to args and returns
+// conditions whose placeholders continue after the ones already in args.
+func buildProductFilterConditions(filter domain.ProductFilter, args []interface{}) ([]string, []interface{}) {
+	conditions := []string{notDeletedSql}
+
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Store) > 0 {
+		conditions = append(conditions, "lower(stores.name) = lower("+addArg(domain.NormalizeStoreName(filter.Store))+")")
+	}
+
+	if filter.CategoryId != 0 && filter.IncludeDescendants {
+		conditions = append(conditions, `products.id IN (
+    WITH RECURSIVE category_tree AS (
+        SELECT id FROM categories WHERE id = `+addArg(filter.CategoryId)+`
+        UNION
+        SELECT categories.id FROM categories JOIN category_tree ON categories.parent_id = category_tree.id
+    )
+    SELECT product_id FROM product_categories WHERE category_id IN (SELECT id FROM category_tree))`)
+	} else if filter.CategoryId != 0 {
+		conditions = append(conditions, "products.id IN (SELECT product_id FROM product_categories WHERE category_id = "+addArg(filter.CategoryId)+")")
+	}
+
+	if filter.InStock {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM inventory WHERE inventory.product_id = products.id AND inventory.on_hand > inventory.reserved)")
+	}
+
+	if len(filter.Tags) > 0 && filter.MatchAllTags {
+		tags := addArg(filter.Tags)
+		conditions = append(conditions, `products.id IN (
+    SELECT product_tags.product_id FROM product_tags JOIN tags ON tags.id = product_tags.tag_id
+    WHERE tags.name = ANY(`+tags+`) GROUP BY product_tags.product_id HAVING count(*) = cardinality(`+tags+`::text[]))`)
+	} else if len(filter.Tags) > 0 {
+		conditions = append(conditions, `products.id IN (
+    SELECT product_tags.product_id FROM product_tags JOIN tags ON tags.id = product_tags.tag_id WHERE tags.name = ANY(`+addArg(filter.Tags)+`))`)
+	}
+
+	for _, attribute := range filter.Attributes {
+		conditions = append(conditions, attributeConditionSql(attribute, addArg))
+	}
+
+	return conditions, args
+}
+
+// attributeConditionSql compares one attribute key. Ordering operators only
+// match attributes stored as JSON numbers; the CASE keeps the numeric cast
+// away from strings like "large" that would otherwise fail the whole query.
+func attributeConditionSql(attribute domain.AttributeCondition, addArg func(interface{}) string) string {
+	key := addArg(attribute.Key)
+
+	switch attribute.Operator {
+	case domain.ATTRIBUTE_EXISTS:
+		return "products.attributes ? " + key
+	case domain.ATTRIBUTE_NE:
+		return "products.attributes ->> " + key + " <> " + addArg(attribute.Value)
+	case domain.ATTRIBUTE_GT, domain.ATTRIBUTE_GTE, domain.ATTRIBUTE_LT, domain.ATTRIBUTE_LTE:
+		operators := map[string]string{
+			domain.ATTRIBUTE_GT:  ">",
+			domain.ATTRIBUTE_GTE: ">=",
+			domain.ATTRIBUTE_LT:  "<",
+			domain.ATTRIBUTE_LTE: "<=",
+		}
+		return "CASE WHEN jsonb_typeof(products.attributes -> " + key + ") = 'number' THEN (products.attributes ->> " + key + ")::numeric " +
+			operators[attribute.Operator] + " " + addArg(attribute.Number) + "::numeric ELSE false END"
+	default:
+		return "products.attributes ->> " + key + " = " + addArg(attribute.Value)
+	}
+}
+
+var searchTermPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchTerms keeps only letters and digits so that user input can never
+// carry tsquery operators into to_tsquery.
+func searchTerms(query string) []string {
+	return searchTermPattern.FindAllString(strings.ToLower(query), -1)
+}
+
+func scanProduct(row pgx.Row) (domain.Product, error) {
+	var product domain.Product
+
+	err := row.Scan(&product.Id, &product.Name, &product.Price, &product.Discount, &product.StoreId, &product.Store, &product.Attributes,
+		&product.Version, &product.UpdatedAt, &product.ExternalRef, &product.Discontinued)
+	product.Attributes = nilIfEmpty(product.Attributes)
+
+	return product, err
+}
+
+// nilIfEmpty treats the column default '{}' the same as a product that never
+// had attributes.
+func nilIfEmpty(attributes map[string]interface{}) map[string]interface{} {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+func nonNilProductAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return map[string]interface{}{}
+	}
+	return attributes
+}
+
 func extractProductsFromRows(productRows pgx.Rows) []domain.Product {
 	var products = []domain.Product{}
-	var id int64
-	var name string
-	var price float32
-	var discount float32
-	var store string
 
 	for productRows.Next() {
-		productRows.Scan(&id, &name, &price, &discount, &store)
-		products = append(products, domain.Product{
-			Id:       id,
-			Name:     name,
-			Price:    price,
-			Discount: discount,
-			Store:    store,
-		})
+		product, err := scanProduct(productRows)
+
+		if err != nil {
+			log.Errorf("Error while scanning product %v", err)
+			continue
+		}
+
+		products = append(products, product)
 	}
 
 	return products
 }
+
+func productNotFound(productId int64) error {
+	return fmt.Errorf("%w with id %d", ErrProductNotFound, productId)
+}
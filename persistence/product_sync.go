@@ -0,0 +1,241 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// upsertByExternalRefSql writes the product under its external reference and
+// returns its id with created true for an insert and false for an update.
+// A product that already is as sent is left alone, so that resending an
+// unchanged catalog bumps no versions; the second branch returns it with a
+// NULL created. Storing a product brings it back from the trash and from
+// being discontinued. A store given by name is created on first use.
+const upsertByExternalRefSql = `WITH new_store AS (
+    INSERT INTO stores(name) SELECT $6::text WHERE $5::bigint = 0 ON CONFLICT ((lower(name))) DO NOTHING RETURNING id
+), upserted AS (
+    INSERT INTO products(external_ref, name, price, discount, store_id, attributes)
+    VALUES($1, $2, $3, $4, COALESCE(NULLIF($5::bigint, 0), (SELECT id FROM new_store), (SELECT id FROM stores WHERE lower(name) = lower($6::text))), $7)
+    ON CONFLICT (external_ref) DO UPDATE
+    SET name = EXCLUDED.name, price = EXCLUDED.price, discount = EXCLUDED.discount, store_id = EXCLUDED.store_id,
+        attributes = EXCLUDED.attributes, deleted_at = NULL, discontinued_at = NULL
+    WHERE (products.name, products.price, products.discount, products.store_id, products.attributes, products.deleted_at, products.discontinued_at)
+        IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.price, EXCLUDED.discount, EXCLUDED.store_id, EXCLUDED.attributes, NULL::timestamptz, NULL::timestamptz)
+    RETURNING products.id, xmax = 0 AS created
+)
+SELECT id, created FROM upserted
+UNION ALL
+SELECT id, NULL FROM products WHERE external_ref = $1 AND NOT EXISTS (SELECT 1 FROM upserted)`
+
+// discontinueMissingSql marks the synced products that are not in the feed.
+// Products without a reference were never synced and are left alone.
+const discontinueMissingSql = `Update products set discontinued_at = now()
+where external_ref IS NOT NULL and external_ref <> ALL($1::text[]) and discontinued_at IS NULL and deleted_at IS NULL
+RETURNING id`
+
+// UpsertByExternalRef creates or updates the product with
+// product.ExternalRef and reports which of the two it did.
+func (productRepository *ProductRepository) UpsertByExternalRef(product domain.Product) (domain.ProductUpsert, error) {
+	report, _, err := productRepository.syncByExternalRef([]domain.Product{product}, false)
+
+	if err != nil {
+		return domain.ProductUpsert{}, err
+	}
+
+	log.Infof("Product %s %s", product.ExternalRef, report.Upserts[0].Outcome)
+
+	return report.Upserts[0], nil
+}
+
+// SyncByExternalRef upserts the products of a feed in a single transaction,
+// so a feed is applied as a whole or not at all. A full feed is the whole
+// catalog: synced products missing from it are marked discontinued.
+func (productRepository *ProductRepository) SyncByExternalRef(products []domain.Product, full bool) (domain.ProductSyncReport, error) {
+	report, failed, err := productRepository.syncByExternalRef(products, full)
+
+	if err != nil && failed >= 0 {
+		return domain.ProductSyncReport{}, errors.New(fmt.Sprintf("Product %s: %s", products[failed].ExternalRef, err.Error()))
+	}
+
+	if err != nil {
+		return domain.ProductSyncReport{}, err
+	}
+
+	log.Infof("%d products synced, %d discontinued", len(report.Upserts), len(report.Discontinued))
+
+	return report, nil
+}
+
+// syncByExternalRef sends the upserts in one batch and then loads the
+// written products in one query. It returns the index of the product that
+// failed, or -1 when none did.
+func (productRepository *ProductRepository) syncByExternalRef(products []domain.Product, full bool) (domain.ProductSyncReport, int, error) {
+	ctx := context.Background()
+	report := domain.ProductSyncReport{Upserts: make([]domain.ProductUpsert, len(products)), Discontinued: []domain.Product{}}
+	failed := -1
+
+	err := productRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+
+		for _, product := range products {
+			storeName := ""
+
+			if product.StoreId == 0 {
+				storeName = domain.NormalizeStoreName(product.Store)
+			}
+
+			batch.Queue(upsertByExternalRefSql, product.ExternalRef, product.Name, product.Price, product.Discount, product.StoreId,
+				storeName, nonNilProductAttributes(product.Attributes))
+		}
+
+		batchResults := tx.SendBatch(ctx, batch)
+		ids := make([]int64, 0, len(products))
+
+		for i := range products {
+			var created *bool
+
+			if err := batchResults.QueryRow().Scan(&report.Upserts[i].Product.Id, &created); err != nil {
+				batchResults.Close()
+				failed = i
+				return err
+			}
+
+			report.Upserts[i].Outcome = upsertOutcome(created)
+			ids = append(ids, report.Upserts[i].Product.Id)
+		}
+
+		if err := batchResults.Close(); err != nil {
+			return err
+		}
+
+		discontinuedIds, err := discontinueMissing(ctx, tx, products, full)
+
+		if err != nil {
+			return err
+		}
+
+		productRows, err := tx.Query(ctx, selectProductsSql+` where products.id = ANY($1)`, append(ids, discontinuedIds...))
+
+		if err != nil {
+			return err
+		}
+
+		productsById := map[int64]domain.Product{}
+
+		for _, product := range extractProductsFromRows(productRows) {
+			productsById[product.Id] = product
+		}
+
+		for i := range report.Upserts {
+			report.Upserts[i].Product = productsById[report.Upserts[i].Product.Id]
+		}
+
+		for _, discontinuedId := range discontinuedIds {
+			report.Discontinued = append(report.Discontinued, productsById[discontinuedId])
+		}
+
+		return insertSyncOutboxEvents(ctx, tx, report)
+	})
+
+	switch {
+	case err == nil:
+		return report, -1, nil
+	case failed >= 0 && isPgError(err, common.UNIQUE_VIOLATION):
+		return report, failed, ErrDuplicateProduct
+	case failed >= 0 && isPgError(err, common.FOREIGN_KEY_VIOLATION):
+		return report, failed, errors.New(fmt.Sprintf("Store not found with id %d", products[failed].StoreId))
+	}
+
+	log.Errorf("Error while syncing products %v", err)
+
+	return report, -1, errors.New("Error while syncing products")
+}
+
+// discontinueMissing returns the ids of the products a full feed
+// discontinued; other feeds discontinue nothing.
+func discontinueMissing(ctx context.Context, tx pgx.Tx, products []domain.Product, full bool) ([]int64, error) {
+	if !full {
+		return nil, nil
+	}
+
+	refs := make([]string, 0, len(products))
+
+	for _, product := range products {
+		refs = append(refs, product.ExternalRef)
+	}
+
+	rows, err := tx.Query(ctx, discontinueMissingSql, refs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func upsertOutcome(created *bool) string {
+	switch {
+	case created == nil:
+		return domain.UPSERT_UNCHANGED
+	case *created:
+		return domain.UPSERT_CREATED
+	default:
+		return domain.UPSERT_UPDATED
+	}
+}
+
+// insertSyncOutboxEvents records a created or updated event for every product
+// the sync wrote and an updated event for every product it discontinued.
+func insertSyncOutboxEvents(ctx context.Context, tx pgx.Tx, report domain.ProductSyncReport) error {
+	var events []domain.ProductEvent
+
+	for _, upsert := range report.Upserts {
+		switch upsert.Outcome {
+		case domain.UPSERT_CREATED:
+			events = append(events, domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: upsert.Product})
+		case domain.UPSERT_UPDATED:
+			events = append(events, domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: upsert.Product})
+		}
+	}
+
+	for _, product := range report.Discontinued {
+		events = append(events, domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: product})
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, event := range events {
+		args, err := outboxEventArgs(event)
+
+		if err != nil {
+			return err
+		}
+
+		batch.Queue(insertOutboxSql, args...)
+	}
+
+	return tx.SendBatch(ctx, batch).Close()
+}
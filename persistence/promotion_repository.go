@@ -0,0 +1,223 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+type IPromotionRepository interface {
+	GetAll() []domain.Promotion
+	GetById(promotionId int64) (domain.Promotion, error)
+	GetByCode(code string) (domain.Promotion, error)
+	Add(promotion domain.Promotion) error
+	Update(promotion domain.Promotion) error
+	DeleteById(promotionId int64) error
+	Redeem(promotionId int64) error
+}
+
+var ErrPromotionExhausted = errors.New("Promotion usage limit reached")
+
+type PromotionRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewPromotionRepository(dbPool *pgxpool.Pool) IPromotionRepository {
+	return &PromotionRepository{dbPool: dbPool}
+}
+
+const promotionColumns = `id, code, product_ids, store_ids, discount_type, value, valid_from, valid_until, usage_limit, usage_count`
+
+func (promotionRepository *PromotionRepository) GetAll() []domain.Promotion {
+	ctx := context.Background()
+	promotionRows, err := promotionRepository.dbPool.Query(ctx, "Select "+promotionColumns+" from promotions order by id")
+
+	if err != nil {
+		log.Errorf("Couldn't get promotions %v", err)
+		return []domain.Promotion{}
+	}
+
+	return extractPromotionsFromRows(promotionRows)
+}
+
+func (promotionRepository *PromotionRepository) GetById(promotionId int64) (domain.Promotion, error) {
+	ctx := context.Background()
+	getByIdSql := `Select ` + promotionColumns + ` from promotions where id = $1`
+	queryRow := promotionRepository.dbPool.QueryRow(ctx, getByIdSql, promotionId)
+
+	promotion, scanErr := scanPromotion(queryRow)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Promotion{}, errors.New(fmt.Sprintf("Promotion not found with id %d", promotionId))
+	}
+
+	if scanErr != nil {
+		return domain.Promotion{}, errors.New(fmt.Sprintf("Error while getting promotion with id %d", promotionId))
+	}
+
+	return promotion, nil
+}
+
+func (promotionRepository *PromotionRepository) GetByCode(code string) (domain.Promotion, error) {
+	ctx := context.Background()
+	getByCodeSql := `Select ` + promotionColumns + ` from promotions where code = $1`
+	queryRow := promotionRepository.dbPool.QueryRow(ctx, getByCodeSql, code)
+
+	promotion, scanErr := scanPromotion(queryRow)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Promotion{}, errors.New(fmt.Sprintf("Promotion not found with code %s", code))
+	}
+
+	if scanErr != nil {
+		return domain.Promotion{}, errors.New(fmt.Sprintf("Error while getting promotion with code %s", code))
+	}
+
+	return promotion, nil
+}
+
+func (promotionRepository *PromotionRepository) Add(promotion domain.Promotion) error {
+	ctx := context.Background()
+	insertSql := `INSERT INTO promotions(code, product_ids, store_ids, discount_type, value, valid_from, valid_until, usage_limit)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := promotionRepository.dbPool.Exec(ctx, insertSql,
+		promotion.Code,
+		nonNilInt64s(promotion.ProductIds),
+		nonNilInt64s(promotion.StoreIds),
+		promotion.DiscountType,
+		promotion.Value,
+		promotion.ValidFrom,
+		promotion.ValidUntil,
+		promotion.UsageLimit)
+
+	if err != nil {
+		log.Errorf("Error while inserting promotion %v", err)
+		return err
+	}
+
+	log.Infof("Promotion added with code %s", promotion.Code)
+
+	return nil
+}
+
+func (promotionRepository *PromotionRepository) Update(promotion domain.Promotion) error {
+	ctx := context.Background()
+	updateSql := `Update promotions set code = $1, product_ids = $2, store_ids = $3, discount_type = $4, value = $5,
+valid_from = $6, valid_until = $7, usage_limit = $8 where id = $9`
+	commandTag, err := promotionRepository.dbPool.Exec(ctx, updateSql,
+		promotion.Code,
+		nonNilInt64s(promotion.ProductIds),
+		nonNilInt64s(promotion.StoreIds),
+		promotion.DiscountType,
+		promotion.Value,
+		promotion.ValidFrom,
+		promotion.ValidUntil,
+		promotion.UsageLimit,
+		promotion.Id)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while updating promotion with id: %d", promotion.Id))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Promotion not found with id %d", promotion.Id))
+	}
+
+	log.Infof("Promotion %d updated", promotion.Id)
+
+	return nil
+}
+
+func (promotionRepository *PromotionRepository) DeleteById(promotionId int64) error {
+	ctx := context.Background()
+	deleteSql := `Delete from promotions where id = $1`
+	commandTag, err := promotionRepository.dbPool.Exec(ctx, deleteSql, promotionId)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while deleting promotion with id %d", promotionId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Promotion not found with id %d", promotionId))
+	}
+
+	log.Info("Promotion deleted")
+
+	return nil
+}
+
+// Redeem counts a use of the promotion. The limit is checked by the update
+// itself, so concurrent redemptions can not use a promotion more often than
+// its limit allows; a limit of 0 means unlimited.
+func (promotionRepository *PromotionRepository) Redeem(promotionId int64) error {
+	ctx := context.Background()
+	redeemSql := `Update promotions set usage_count = usage_count + 1
+where id = $1 and (usage_limit = 0 or usage_count < usage_limit)`
+	commandTag, err := promotionRepository.dbPool.Exec(ctx, redeemSql, promotionId)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while redeeming promotion with id %d", promotionId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrPromotionExhausted
+	}
+
+	log.Infof("Promotion %d redeemed", promotionId)
+
+	return nil
+}
+
+func scanPromotion(row pgx.Row) (domain.Promotion, error) {
+	var promotion domain.Promotion
+
+	err := row.Scan(
+		&promotion.Id,
+		&promotion.Code,
+		&promotion.ProductIds,
+		&promotion.StoreIds,
+		&promotion.DiscountType,
+		&promotion.Value,
+		&promotion.ValidFrom,
+		&promotion.ValidUntil,
+		&promotion.UsageLimit,
+		&promotion.UsageCount)
+
+	return promotion, err
+}
+
+func extractPromotionsFromRows(promotionRows pgx.Rows) []domain.Promotion {
+	var promotions = []domain.Promotion{}
+
+	for promotionRows.Next() {
+		promotion, err := scanPromotion(promotionRows)
+
+		if err != nil {
+			log.Errorf("Error while scanning promotion %v", err)
+			continue
+		}
+
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions
+}
+
+func nonNilInt64s(values []int64) []int64 {
+	if values == nil {
+		return []int64{}
+	}
+	return values
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,202 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"strings"
+)
+
+type IStoreRepository interface {
+	GetAll() []domain.Store
+	GetById(storeId int64) (domain.Store, error)
+	GetByName(storeName string) (domain.Store, error)
+	GetAllByNames(storeNames []string) []domain.Store
+	Add(store domain.Store) error
+	UpdateName(storeId int64, newName string) error
+	DeleteById(storeId int64) error
+}
+
+// ErrStoreNotFound is wrapped by the errors returned for a store id that
+// does not exist.
+var ErrStoreNotFound = errors.New("Store not found")
+
+type StoreRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewStoreRepository(dbPool *pgxpool.Pool) IStoreRepository {
+	return &StoreRepository{dbPool: dbPool}
+}
+
+func (storeRepository *StoreRepository) GetAll() []domain.Store {
+	ctx := context.Background()
+	storeRows, err := storeRepository.dbPool.Query(ctx, "Select id, name from stores order by id")
+
+	if err != nil {
+		log.Errorf("Couldn't get stores %v", err)
+		return []domain.Store{}
+	}
+
+	return extractStoresFromRows(storeRows)
+}
+
+func (storeRepository *StoreRepository) GetById(storeId int64) (domain.Store, error) {
+	ctx := context.Background()
+	getByIdSql := `Select id, name from stores where id = $1`
+	queryRow := storeRepository.dbPool.QueryRow(ctx, getByIdSql, storeId)
+
+	var store domain.Store
+	scanErr := queryRow.Scan(&store.Id, &store.Name)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Store{}, storeNotFound(storeId)
+	}
+
+	if scanErr != nil {
+		return domain.Store{}, errors.New(fmt.Sprintf("Error while getting store with id %d", storeId))
+	}
+
+	return store, nil
+}
+
+func (storeRepository *StoreRepository) GetByName(storeName string) (domain.Store, error) {
+	ctx := context.Background()
+	getByNameSql := `Select id, name from stores where lower(name) = lower($1)`
+	queryRow := storeRepository.dbPool.QueryRow(ctx, getByNameSql, domain.NormalizeStoreName(storeName))
+
+	var store domain.Store
+	scanErr := queryRow.Scan(&store.Id, &store.Name)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Store{}, errors.New(fmt.Sprintf("Store not found with name %s", storeName))
+	}
+
+	if scanErr != nil {
+		return domain.Store{}, errors.New(fmt.Sprintf("Error while getting store with name %s", storeName))
+	}
+
+	return store, nil
+}
+
+// GetAllByNames looks up several stores in one query. Names match case
+// insensitively and names without a store are left out of the result.
+func (storeRepository *StoreRepository) GetAllByNames(storeNames []string) []domain.Store {
+	ctx := context.Background()
+
+	lowerNames := make([]string, 0, len(storeNames))
+	for _, storeName := range storeNames {
+		lowerNames = append(lowerNames, strings.ToLower(domain.NormalizeStoreName(storeName)))
+	}
+
+	storeRows, err := storeRepository.dbPool.Query(ctx, "Select id, name from stores where lower(name) = ANY($1) order by id", lowerNames)
+
+	if err != nil {
+		log.Errorf("Couldn't get stores by name %v", err)
+		return []domain.Store{}
+	}
+
+	return extractStoresFromRows(storeRows)
+}
+
+func (storeRepository *StoreRepository) Add(store domain.Store) error {
+	ctx := context.Background()
+	insertSql := `INSERT INTO stores(name) VALUES($1)`
+	_, err := storeRepository.dbPool.Exec(ctx, insertSql, store.Name)
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return errors.New(fmt.Sprintf("Store already exists with name %s", store.Name))
+	}
+
+	if err != nil {
+		log.Errorf("Error while inserting store %v", err)
+		return err
+	}
+
+	log.Infof("Store added with name %s", store.Name)
+
+	return nil
+}
+
+// UpdateName announces the rename on the product changes channel, so that
+// every instance drops the products it cached with the old store name.
+func (storeRepository *StoreRepository) UpdateName(storeId int64, newName string) error {
+	ctx := context.Background()
+	updateSql := `WITH renamed AS (
+    Update stores set name = $1 where id = $2 RETURNING id
+)
+SELECT pg_notify($3, json_build_object('type', $4::text, 'storeId', id)::text) FROM renamed`
+	commandTag, err := storeRepository.dbPool.Exec(ctx, updateSql, newName, storeId, PRODUCT_CHANGES_CHANNEL, STORE_RENAMED_NOTIFICATION)
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return errors.New(fmt.Sprintf("Store already exists with name %s", newName))
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while updating store with id: %d", storeId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return storeNotFound(storeId)
+	}
+
+	log.Infof("Store %d renamed to %s", storeId, newName)
+
+	return nil
+}
+
+func (storeRepository *StoreRepository) DeleteById(storeId int64) error {
+	ctx := context.Background()
+	deleteSql := `Delete from stores where id = $1`
+	commandTag, err := storeRepository.dbPool.Exec(ctx, deleteSql, storeId)
+
+	if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+		return errors.New(fmt.Sprintf("Store %d still has products", storeId))
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while deleting store with id %d", storeId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return storeNotFound(storeId)
+	}
+
+	log.Info("Store deleted")
+
+	return nil
+}
+
+func extractStoresFromRows(storeRows pgx.Rows) []domain.Store {
+	var stores = []domain.Store{}
+
+	for storeRows.Next() {
+		var store domain.Store
+
+		err := storeRows.Scan(&store.Id, &store.Name)
+
+		if err != nil {
+			log.Errorf("Error while scanning store %v", err)
+			continue
+		}
+
+		stores = append(stores, store)
+	}
+
+	return stores
+}
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+func storeNotFound(storeId int64) error {
+	return fmt.Errorf("%w with id %d", ErrStoreNotFound, storeId)
+}
@@ -0,0 +1,96 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+type ITagRepository interface {
+	GetAllByProductId(productId int64) []string
+	AddToProduct(productId int64, tags []string) error
+	RemoveFromProduct(productId int64, tags []string) error
+}
+
+type TagRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewTagRepository(dbPool *pgxpool.Pool) ITagRepository {
+	return &TagRepository{dbPool: dbPool}
+}
+
+func (tagRepository *TagRepository) GetAllByProductId(productId int64) []string {
+	ctx := context.Background()
+	getByProductSql := `Select tags.name from tags join product_tags on product_tags.tag_id = tags.id
+where product_tags.product_id = $1 order by tags.name`
+	tagRows, err := tagRepository.dbPool.Query(ctx, getByProductSql, productId)
+
+	if err != nil {
+		log.Errorf("Error while getting tags of product %d %v", productId, err)
+		return []string{}
+	}
+
+	var tags = []string{}
+
+	for tagRows.Next() {
+		var tag string
+
+		scanErr := tagRows.Scan(&tag)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning tag %v", scanErr)
+			continue
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// AddToProduct creates tags that do not exist yet and links all of them to
+// the product. Tags the product already has are left as they are.
+func (tagRepository *TagRepository) AddToProduct(productId int64, tags []string) error {
+	ctx := context.Background()
+	addSql := `WITH new_tags AS (
+    INSERT INTO tags(name) SELECT unnest($2::text[]) ON CONFLICT (name) DO NOTHING RETURNING id
+)
+INSERT INTO product_tags(product_id, tag_id)
+SELECT $1, id FROM new_tags
+UNION
+SELECT $1, id FROM tags WHERE name = ANY($2)
+ON CONFLICT DO NOTHING`
+	_, err := tagRepository.dbPool.Exec(ctx, addSql, productId, tags)
+
+	if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+		return productNotFound(productId)
+	}
+
+	if err != nil {
+		log.Errorf("Error while tagging product %v", err)
+		return errors.New(fmt.Sprintf("Error while tagging product with id %d", productId))
+	}
+
+	log.Infof("Product %d tagged with %v", productId, tags)
+
+	return nil
+}
+
+func (tagRepository *TagRepository) RemoveFromProduct(productId int64, tags []string) error {
+	ctx := context.Background()
+	removeSql := `Delete from product_tags using tags
+where product_tags.tag_id = tags.id and product_tags.product_id = $1 and tags.name = ANY($2)`
+	_, err := tagRepository.dbPool.Exec(ctx, removeSql, productId, tags)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while removing tags from product with id %d", productId))
+	}
+
+	log.Infof("Tags %v removed from product %d", tags, productId)
+
+	return nil
+}
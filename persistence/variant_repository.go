@@ -0,0 +1,167 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+type IVariantRepository interface {
+	GetAllByProductId(productId int64) []domain.Variant
+	GetAllByProductIds(productIds []int64) []domain.Variant
+	GetById(productId int64, variantId int64) (domain.Variant, error)
+	Add(variant domain.Variant) error
+	Update(variant domain.Variant) error
+	DeleteById(productId int64, variantId int64) error
+}
+
+type VariantRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewVariantRepository(dbPool *pgxpool.Pool) IVariantRepository {
+	return &VariantRepository{dbPool: dbPool}
+}
+
+const selectVariantsSql = `Select id, product_id, sku, attributes, price_override, COALESCE(barcode, '') from product_variants`
+
+func (variantRepository *VariantRepository) GetAllByProductId(productId int64) []domain.Variant {
+	return variantRepository.GetAllByProductIds([]int64{productId})
+}
+
+// GetAllByProductIds loads the variants of several products in one query so
+// list endpoints can expand variants without a query per product.
+func (variantRepository *VariantRepository) GetAllByProductIds(productIds []int64) []domain.Variant {
+	ctx := context.Background()
+	variantRows, err := variantRepository.dbPool.Query(ctx, selectVariantsSql+" where product_id = ANY($1) order by product_id, id", productIds)
+
+	if err != nil {
+		log.Errorf("Error while getting variants %v", err)
+		return []domain.Variant{}
+	}
+
+	var variants = []domain.Variant{}
+
+	for variantRows.Next() {
+		variant, scanErr := scanVariant(variantRows)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning variant %v", scanErr)
+			continue
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants
+}
+
+func (variantRepository *VariantRepository) GetById(productId int64, variantId int64) (domain.Variant, error) {
+	ctx := context.Background()
+	getByIdSql := selectVariantsSql + ` where product_id = $1 and id = $2`
+	queryRow := variantRepository.dbPool.QueryRow(ctx, getByIdSql, productId, variantId)
+
+	variant, scanErr := scanVariant(queryRow)
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.Variant{}, errors.New(fmt.Sprintf("Variant %d not found for product %d", variantId, productId))
+	}
+
+	if scanErr != nil {
+		return domain.Variant{}, errors.New(fmt.Sprintf("Error while getting variant %d of product %d", variantId, productId))
+	}
+
+	return variant, nil
+}
+
+func (variantRepository *VariantRepository) Add(variant domain.Variant) error {
+	ctx := context.Background()
+	insertSql := `INSERT INTO product_variants(product_id, sku, attributes, price_override, barcode)
+VALUES($1, $2, $3, $4, NULLIF($5, ''))`
+	_, err := variantRepository.dbPool.Exec(ctx, insertSql,
+		variant.ProductId,
+		variant.Sku,
+		nonNilAttributes(variant.Attributes),
+		variant.PriceOverride,
+		variant.Barcode)
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return errors.New(fmt.Sprintf("Variant already exists with sku %s or barcode %s", variant.Sku, variant.Barcode))
+	}
+
+	if err != nil {
+		log.Errorf("Error while inserting variant %v", err)
+		return err
+	}
+
+	log.Infof("Variant %s added to product %d", variant.Sku, variant.ProductId)
+
+	return nil
+}
+
+func (variantRepository *VariantRepository) Update(variant domain.Variant) error {
+	ctx := context.Background()
+	updateSql := `Update product_variants set sku = $1, attributes = $2, price_override = $3, barcode = NULLIF($4, '')
+where product_id = $5 and id = $6`
+	commandTag, err := variantRepository.dbPool.Exec(ctx, updateSql,
+		variant.Sku,
+		nonNilAttributes(variant.Attributes),
+		variant.PriceOverride,
+		variant.Barcode,
+		variant.ProductId,
+		variant.Id)
+
+	if isPgError(err, common.UNIQUE_VIOLATION) {
+		return errors.New(fmt.Sprintf("Variant already exists with sku %s or barcode %s", variant.Sku, variant.Barcode))
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while updating variant with id: %d", variant.Id))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Variant %d not found for product %d", variant.Id, variant.ProductId))
+	}
+
+	log.Infof("Variant %d updated", variant.Id)
+
+	return nil
+}
+
+func (variantRepository *VariantRepository) DeleteById(productId int64, variantId int64) error {
+	ctx := context.Background()
+	deleteSql := `Delete from product_variants where product_id = $1 and id = $2`
+	commandTag, err := variantRepository.dbPool.Exec(ctx, deleteSql, productId, variantId)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while deleting variant with id %d", variantId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Variant %d not found for product %d", variantId, productId))
+	}
+
+	log.Info("Variant deleted")
+
+	return nil
+}
+
+func scanVariant(row pgx.Row) (domain.Variant, error) {
+	var variant domain.Variant
+
+	err := row.Scan(&variant.Id, &variant.ProductId, &variant.Sku, &variant.Attributes, &variant.PriceOverride, &variant.Barcode)
+
+	return variant, err
+}
+
+func nonNilAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return map[string]string{}
+	}
+	return attributes
+}
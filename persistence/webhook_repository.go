@@ -0,0 +1,314 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence/common"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"time"
+)
+
+type IWebhookRepository interface {
+	GetAll() []domain.WebhookSubscription
+	GetById(subscriptionId int64) (domain.WebhookSubscription, error)
+	Add(subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	DeleteById(subscriptionId int64) error
+	EnqueueDeliveries(eventId int64, eventType string, storeId int64, payload []byte) (int64, error)
+	GetDeliveries(subscriptionId int64, status string, limit int) []domain.WebhookDelivery
+	Redeliver(subscriptionId int64, deliveryId int64) error
+	ProcessDueDeliveries(limit int, lease time.Duration, process func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome) (int, error)
+}
+
+type WebhookRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewWebhookRepository(dbPool *pgxpool.Pool) IWebhookRepository {
+	return &WebhookRepository{dbPool: dbPool}
+}
+
+const webhookSubscriptionColumns = `id, url, event_types, COALESCE(store_id, 0), secret, created_at`
+
+const webhookDeliveryColumns = `webhook_deliveries.id, webhook_deliveries.subscription_id, webhook_deliveries.event_id,
+webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.status, webhook_deliveries.attempts,
+webhook_deliveries.next_attempt_at, COALESCE(webhook_deliveries.last_status_code, 0), COALESCE(webhook_deliveries.last_error, ''),
+webhook_deliveries.created_at, webhook_deliveries.delivered_at`
+
+func (webhookRepository *WebhookRepository) GetAll() []domain.WebhookSubscription {
+	ctx := context.Background()
+	subscriptionRows, err := webhookRepository.dbPool.Query(ctx, "Select "+webhookSubscriptionColumns+" from webhook_subscriptions order by id")
+
+	if err != nil {
+		log.Errorf("Couldn't get webhook subscriptions %v", err)
+		return []domain.WebhookSubscription{}
+	}
+
+	defer subscriptionRows.Close()
+
+	var subscriptions = []domain.WebhookSubscription{}
+
+	for subscriptionRows.Next() {
+		subscription, scanErr := scanWebhookSubscription(subscriptionRows)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning webhook subscription %v", scanErr)
+			continue
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions
+}
+
+func (webhookRepository *WebhookRepository) GetById(subscriptionId int64) (domain.WebhookSubscription, error) {
+	ctx := context.Background()
+	getByIdSql := `Select ` + webhookSubscriptionColumns + ` from webhook_subscriptions where id = $1`
+
+	subscription, scanErr := scanWebhookSubscription(webhookRepository.dbPool.QueryRow(ctx, getByIdSql, subscriptionId))
+
+	if scanErr != nil && scanErr.Error() == common.NOT_FOUND {
+		return domain.WebhookSubscription{}, errors.New(fmt.Sprintf("Webhook not found with id %d", subscriptionId))
+	}
+
+	if scanErr != nil {
+		return domain.WebhookSubscription{}, errors.New(fmt.Sprintf("Error while getting webhook with id %d", subscriptionId))
+	}
+
+	return subscription, nil
+}
+
+func (webhookRepository *WebhookRepository) Add(subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	ctx := context.Background()
+	insertSql := `INSERT INTO webhook_subscriptions(url, event_types, store_id, secret) VALUES($1, $2, NULLIF($3, 0), $4)
+RETURNING id, created_at`
+
+	err := webhookRepository.dbPool.QueryRow(ctx, insertSql, subscription.Url, nonNilStrings(subscription.EventTypes),
+		subscription.StoreId, subscription.Secret).Scan(&subscription.Id, &subscription.CreatedAt)
+
+	if isPgError(err, common.FOREIGN_KEY_VIOLATION) {
+		return domain.WebhookSubscription{}, errors.New(fmt.Sprintf("Store not found with id %d", subscription.StoreId))
+	}
+
+	if err != nil {
+		log.Errorf("Error while inserting webhook subscription %v", err)
+		return domain.WebhookSubscription{}, errors.New("Error while adding webhook")
+	}
+
+	log.Infof("Webhook %d added for %s", subscription.Id, subscription.Url)
+
+	return subscription, nil
+}
+
+// DeleteById also removes the delivery log of the subscription.
+func (webhookRepository *WebhookRepository) DeleteById(subscriptionId int64) error {
+	ctx := context.Background()
+	commandTag, err := webhookRepository.dbPool.Exec(ctx, `Delete from webhook_subscriptions where id = $1`, subscriptionId)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while deleting webhook with id %d", subscriptionId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Webhook not found with id %d", subscriptionId))
+	}
+
+	log.Infof("Webhook %d deleted", subscriptionId)
+
+	return nil
+}
+
+// EnqueueDeliveries creates a pending delivery of the event for every
+// subscription to its type and store. An event that is enqueued again, as
+// the outbox may do, creates no second delivery. It returns how many
+// deliveries were created.
+func (webhookRepository *WebhookRepository) EnqueueDeliveries(eventId int64, eventType string, storeId int64, payload []byte) (int64, error) {
+	ctx := context.Background()
+	enqueueSql := `INSERT INTO webhook_deliveries(subscription_id, event_id, event_type, payload)
+SELECT id, $1::bigint, $2::text, $4::jsonb FROM webhook_subscriptions
+WHERE $2 = ANY(event_types) AND (store_id IS NULL OR store_id = $3)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	commandTag, err := webhookRepository.dbPool.Exec(ctx, enqueueSql, eventId, eventType, storeId, string(payload))
+
+	if err != nil {
+		log.Errorf("Error while enqueueing webhook deliveries of event %d %v", eventId, err)
+		return 0, errors.New(fmt.Sprintf("Error while enqueueing webhook deliveries of event %d", eventId))
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// GetDeliveries returns the latest deliveries of the subscription first,
+// optionally only those with the given status.
+func (webhookRepository *WebhookRepository) GetDeliveries(subscriptionId int64, status string, limit int) []domain.WebhookDelivery {
+	ctx := context.Background()
+	getDeliveriesSql := `Select ` + webhookDeliveryColumns + ` from webhook_deliveries
+where subscription_id = $1 and ($2 = '' or status = $2) order by id desc limit $3`
+
+	deliveryRows, err := webhookRepository.dbPool.Query(ctx, getDeliveriesSql, subscriptionId, status, limit)
+
+	if err != nil {
+		log.Errorf("Error while getting deliveries of webhook %d %v", subscriptionId, err)
+		return []domain.WebhookDelivery{}
+	}
+
+	defer deliveryRows.Close()
+
+	var deliveries = []domain.WebhookDelivery{}
+
+	for deliveryRows.Next() {
+		delivery, scanErr := scanWebhookDelivery(deliveryRows)
+
+		if scanErr != nil {
+			log.Errorf("Error while scanning webhook delivery %v", scanErr)
+			continue
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries
+}
+
+// Redeliver makes the delivery due again with a fresh set of attempts,
+// whatever its status.
+func (webhookRepository *WebhookRepository) Redeliver(subscriptionId int64, deliveryId int64) error {
+	ctx := context.Background()
+	redeliverSql := `Update webhook_deliveries set status = $3, attempts = 0, next_attempt_at = now(), delivered_at = NULL
+where id = $1 and subscription_id = $2`
+
+	commandTag, err := webhookRepository.dbPool.Exec(ctx, redeliverSql, deliveryId, subscriptionId, domain.WEBHOOK_PENDING)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error while redelivering webhook delivery %d", deliveryId))
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Webhook delivery not found with id %d", deliveryId))
+	}
+
+	log.Infof("Webhook delivery %d scheduled for redelivery", deliveryId)
+
+	return nil
+}
+
+// leaseWebhookDeliveriesSql claims up to $1 due deliveries by moving their
+// next attempt past the lease of $2 milliseconds and returns them with the
+// url and secret of their subscription.
+const leaseWebhookDeliveriesSql = `WITH leased AS (
+    UPDATE webhook_deliveries SET next_attempt_at = now() + $2 * interval '1 millisecond'
+    WHERE id IN (SELECT id FROM webhook_deliveries WHERE status IN ('pending', 'failed') AND next_attempt_at <= now()
+                 ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+    RETURNING *
+)
+SELECT ` + webhookDeliveryColumns + `, webhook_subscriptions.url, webhook_subscriptions.secret
+FROM leased AS webhook_deliveries JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id
+ORDER BY webhook_deliveries.id`
+
+// ProcessDueDeliveries works like OutboxRepository.ProcessBatch: the due
+// deliveries are leased in a transaction of their own, process sends them
+// with no transaction open, and the outcomes of the deliveries still under
+// this lease are stored in a second short transaction.
+func (webhookRepository *WebhookRepository) ProcessDueDeliveries(limit int, lease time.Duration, process func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome) (int, error) {
+	ctx := context.Background()
+	deliveries, err := leaseWebhookDeliveries(ctx, webhookRepository.dbPool, limit, lease)
+
+	if err != nil {
+		log.Errorf("Error while leasing webhook deliveries %v", err)
+		return 0, errors.New("Error while dispatching webhook deliveries")
+	}
+
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	leasedUntil := map[int64]time.Time{}
+
+	for _, delivery := range deliveries {
+		leasedUntil[delivery.Id] = delivery.NextAttemptAt
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, outcome := range process(deliveries) {
+		batch.Queue(`UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, last_status_code = NULLIF($3, 0),
+last_error = NULLIF($4, ''), next_attempt_at = COALESCE($5, next_attempt_at),
+delivered_at = CASE WHEN $2 = 'succeeded' THEN now() END
+WHERE id = $1 AND next_attempt_at = $6`, outcome.Id, outcome.Status, outcome.StatusCode, outcome.Error, nullIfZeroTime(outcome.NextAttemptAt),
+			leasedUntil[outcome.Id])
+	}
+
+	if batch.Len() == 0 {
+		return len(deliveries), nil
+	}
+
+	err = webhookRepository.dbPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+
+	if err != nil {
+		log.Errorf("Error while storing webhook delivery outcomes %v", err)
+		return 0, errors.New("Error while dispatching webhook deliveries")
+	}
+
+	return len(deliveries), nil
+}
+
+func leaseWebhookDeliveries(ctx context.Context, dbPool *pgxpool.Pool, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	rows, err := dbPool.Query(ctx, leaseWebhookDeliveriesSql, limit, lease.Milliseconds())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+
+		err = rows.Scan(&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType, &delivery.Payload,
+			&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+			&delivery.CreatedAt, &delivery.DeliveredAt, &delivery.Url, &delivery.Secret)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanWebhookSubscription(row pgx.Row) (domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+
+	err := row.Scan(&subscription.Id, &subscription.Url, &subscription.EventTypes, &subscription.StoreId, &subscription.Secret,
+		&subscription.CreatedAt)
+
+	return subscription, err
+}
+
+func scanWebhookDelivery(row pgx.Row) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+
+	err := row.Scan(&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.CreatedAt, &delivery.DeliveredAt)
+
+	return delivery, err
+}
+
+func nullIfZeroTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
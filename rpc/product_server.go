@@ -0,0 +1,177 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/api/productpb"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ProductServer serves the ProductService of api/product_service.proto.
+type ProductServer struct {
+	productpb.UnimplementedProductServiceServer
+	productService service.IProductService
+}
+
+func NewProductServer(productService service.IProductService) productpb.ProductServiceServer {
+	return &ProductServer{
+		productService: productService,
+	}
+}
+
+// NewServer returns a gRPC server serving the product service.
+func NewServer(productService service.IProductService) *grpc.Server {
+	server := grpc.NewServer()
+	productpb.RegisterProductServiceServer(server, NewProductServer(productService))
+	return server
+}
+
+func (productServer *ProductServer) GetProduct(ctx context.Context, getProductRequest *productpb.GetProductRequest) (*productpb.Product, error) {
+	product, err := productServer.productService.GetById(getProductRequest.Id)
+
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProduct(product)
+}
+
+// ListProducts streams the products straight from the export cursor, so a
+// large catalog is never held in memory.
+func (productServer *ProductServer) ListProducts(listProductsRequest *productpb.ListProductsRequest, stream productpb.ProductService_ListProductsServer) error {
+	filter := domain.ProductFilter{
+		Store:              listProductsRequest.Store,
+		CategoryId:         listProductsRequest.CategoryId,
+		IncludeDescendants: listProductsRequest.IncludeDescendants,
+		InStock:            listProductsRequest.InStock,
+		MatchAllTags:       listProductsRequest.MatchAllTags,
+	}
+
+	if len(listProductsRequest.Tags) != 0 {
+		tags, err := service.NormalizeTags(listProductsRequest.Tags)
+
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		filter.Tags = tags
+	}
+
+	err := productServer.productService.Export(filter, func(product domain.Product) error {
+		message, err := toProduct(product)
+
+		if err != nil {
+			return err
+		}
+
+		return stream.Send(message)
+	})
+
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (productServer *ProductServer) CreateProduct(ctx context.Context, createProductRequest *productpb.CreateProductRequest) (*emptypb.Empty, error) {
+	productCreate := dto.ProductCreate{
+		Name:     createProductRequest.Name,
+		Price:    createProductRequest.Price,
+		Discount: createProductRequest.Discount,
+		StoreId:  createProductRequest.StoreId,
+		Store:    createProductRequest.Store,
+	}
+
+	if createProductRequest.Attributes != nil {
+		productCreate.Attributes = createProductRequest.Attributes.AsMap()
+	}
+
+	err := productServer.productService.Validate(productCreate)
+
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	_, err = productServer.productService.Add(productCreate)
+
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (productServer *ProductServer) UpdatePrice(ctx context.Context, updatePriceRequest *productpb.UpdatePriceRequest) (*emptypb.Empty, error) {
+	if updatePriceRequest.Price <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Price must be greater than 0")
+	}
+
+	err := productServer.productService.UpdatePrice(updatePriceRequest.Id, updatePriceRequest.Price)
+
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (productServer *ProductServer) DeleteProduct(ctx context.Context, deleteProductRequest *productpb.DeleteProductRequest) (*emptypb.Empty, error) {
+	err := productServer.productService.DeleteById(deleteProductRequest.Id)
+
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// toStatusError maps the errors of the product service to status codes.
+// Errors the service does not name are failures of the database and the
+// like, which the client can not fix by changing the request.
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrStoreNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrDuplicateProduct):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+// toProduct fails only for attributes that have no google.protobuf.Value,
+// which products read from the database can not have.
+func toProduct(product domain.Product) (*productpb.Product, error) {
+	message := &productpb.Product{
+		Id:       product.Id,
+		Name:     product.Name,
+		Price:    product.Price,
+		Discount: product.Discount,
+		StoreId:  product.StoreId,
+		Store:    product.Store,
+	}
+
+	if len(product.Attributes) != 0 {
+		attributes, err := structpb.NewStruct(product.Attributes)
+
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		message.Attributes = attributes
+	}
+
+	return message, nil
+}
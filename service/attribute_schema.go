@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"strings"
+)
+
+// attributeSchemaUrl is the resource name every schema is compiled under.
+// Category names can not be used, as a name like "Air#Fryers" is no valid
+// resource URL. Schemas never reference each other, so one name serves all.
+const attributeSchemaUrl = "attributes.schema.json"
+
+func compileAttributeSchema(categoryName string, schema []byte) (*jsonschema.Schema, error) {
+	compiled, err := jsonschema.CompileString(attributeSchemaUrl, string(schema))
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Attribute schema of category %s is invalid: %v", categoryName, err))
+	}
+
+	return compiled, nil
+}
+
+// validateAttributes checks the attributes against the schema of every given
+// category that has one.
+func validateAttributes(categories []domain.Category, attributes map[string]interface{}) error {
+	for _, category := range categories {
+		if len(category.AttributeSchema) == 0 {
+			continue
+		}
+
+		schema, err := compileAttributeSchema(category.Name, category.AttributeSchema)
+
+		if err != nil {
+			return err
+		}
+
+		var document interface{} = map[string]interface{}{}
+		if attributes != nil {
+			document = attributes
+		}
+
+		err = schema.Validate(document)
+
+		if err != nil {
+			return errors.New(fmt.Sprintf("Attributes do not match the schema of category %s: %s", category.Name, validationMessage(err)))
+		}
+	}
+
+	return nil
+}
+
+func validateAttributeKeys(attributes map[string]interface{}) error {
+	for key := range attributes {
+		if !domain.IsAttributeKey(key) {
+			return errors.New(fmt.Sprintf("Attribute key %s may only contain letters, digits and underscores", key))
+		}
+	}
+
+	return nil
+}
+
+func validationMessage(err error) string {
+	var validationError *jsonschema.ValidationError
+
+	if errors.As(err, &validationError) {
+		leaf := validationError
+		for len(leaf.Causes) > 0 {
+			leaf = leaf.Causes[0]
+		}
+		location := strings.TrimPrefix(leaf.InstanceLocation, "/")
+		if len(location) == 0 {
+			return leaf.Message
+		}
+		return location + ": " + leaf.Message
+	}
+
+	return err.Error()
+}
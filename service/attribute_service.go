@@ -0,0 +1,45 @@
+package service
+
+import (
+	"example.com/product-api/persistence"
+)
+
+type IAttributeService interface {
+	UpdateAttributes(productId int64, attributes map[string]interface{}) error
+}
+
+type AttributeService struct {
+	productRepository  persistence.IProductRepository
+	categoryRepository persistence.ICategoryRepository
+}
+
+func NewAttributeService(productRepository persistence.IProductRepository, categoryRepository persistence.ICategoryRepository) IAttributeService {
+	return &AttributeService{
+		productRepository:  productRepository,
+		categoryRepository: categoryRepository,
+	}
+}
+
+// UpdateAttributes replaces the attributes of the product after checking them
+// against the attribute schema of each category the product belongs to.
+func (attributeService *AttributeService) UpdateAttributes(productId int64, attributes map[string]interface{}) error {
+	_, err := attributeService.productRepository.GetById(productId)
+
+	if err != nil {
+		return err
+	}
+
+	err = validateAttributeKeys(attributes)
+
+	if err != nil {
+		return err
+	}
+
+	err = validateAttributes(attributeService.categoryRepository.GetAllByProductId(productId), attributes)
+
+	if err != nil {
+		return err
+	}
+
+	return attributeService.productRepository.UpdateAttributes(productId, attributes)
+}
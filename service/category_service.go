@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service/dto"
+	"fmt"
+	"strings"
+)
+
+type ICategoryService interface {
+	GetAll() []domain.Category
+	GetById(categoryId int64) (domain.Category, error)
+	GetChildren(categoryId int64) ([]domain.Category, error)
+	GetAllByProductId(productId int64) ([]domain.Category, error)
+	Add(categoryCreate dto.CategoryCreate) error
+	Update(categoryId int64, categoryCreate dto.CategoryCreate) error
+	DeleteById(categoryId int64) error
+	AddProduct(categoryId int64, productId int64) error
+	RemoveProduct(categoryId int64, productId int64) error
+}
+
+type CategoryService struct {
+	categoryRepository persistence.ICategoryRepository
+	productRepository  persistence.IProductRepository
+}
+
+func NewCategoryService(categoryRepository persistence.ICategoryRepository, productRepository persistence.IProductRepository) ICategoryService {
+	return &CategoryService{
+		categoryRepository: categoryRepository,
+		productRepository:  productRepository,
+	}
+}
+
+func (categoryService *CategoryService) GetAll() []domain.Category {
+	return categoryService.categoryRepository.GetAll()
+}
+
+func (categoryService *CategoryService) GetById(categoryId int64) (domain.Category, error) {
+	return categoryService.categoryRepository.GetById(categoryId)
+}
+
+func (categoryService *CategoryService) GetChildren(categoryId int64) ([]domain.Category, error) {
+	_, err := categoryService.categoryRepository.GetById(categoryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryService.categoryRepository.GetChildren(categoryId), nil
+}
+
+func (categoryService *CategoryService) GetAllByProductId(productId int64) ([]domain.Category, error) {
+	_, err := categoryService.productRepository.GetById(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryService.categoryRepository.GetAllByProductId(productId), nil
+}
+
+func (categoryService *CategoryService) Add(categoryCreate dto.CategoryCreate) error {
+	validateErr := categoryService.validateCategoryCreate(0, categoryCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	return categoryService.categoryRepository.Add(domain.Category{
+		Name:            strings.TrimSpace(categoryCreate.Name),
+		ParentId:        categoryCreate.ParentId,
+		AttributeSchema: categoryCreate.AttributeSchema,
+	})
+}
+
+func (categoryService *CategoryService) Update(categoryId int64, categoryCreate dto.CategoryCreate) error {
+	validateErr := categoryService.validateCategoryCreate(categoryId, categoryCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	return categoryService.categoryRepository.Update(domain.Category{
+		Id:              categoryId,
+		Name:            strings.TrimSpace(categoryCreate.Name),
+		ParentId:        categoryCreate.ParentId,
+		AttributeSchema: categoryCreate.AttributeSchema,
+	})
+}
+
+func (categoryService *CategoryService) DeleteById(categoryId int64) error {
+	return categoryService.categoryRepository.DeleteById(categoryId)
+}
+
+// AddProduct links the product to the category once its attributes satisfy
+// the category's attribute schema.
+func (categoryService *CategoryService) AddProduct(categoryId int64, productId int64) error {
+	product, err := categoryService.productRepository.GetById(productId)
+
+	if err != nil {
+		return err
+	}
+
+	category, err := categoryService.categoryRepository.GetById(categoryId)
+
+	if err != nil {
+		return err
+	}
+
+	err = validateAttributes([]domain.Category{category}, product.Attributes)
+
+	if err != nil {
+		return err
+	}
+
+	return categoryService.categoryRepository.AddProduct(categoryId, productId)
+}
+
+func (categoryService *CategoryService) RemoveProduct(categoryId int64, productId int64) error {
+	return categoryService.categoryRepository.RemoveProduct(categoryId, productId)
+}
+
+// validateCategoryCreate checks the payload for a new category (categoryId 0)
+// or an existing one. Moving a category under itself or one of its own
+// descendants would cut the subtree off from the root, so that is refused.
+// A changed attribute schema only applies to later attribute writes and
+// links; products already in the category are not re-validated.
+func (categoryService *CategoryService) validateCategoryCreate(categoryId int64, categoryCreate dto.CategoryCreate) error {
+	if len(strings.TrimSpace(categoryCreate.Name)) == 0 {
+		return errors.New("Category name can not be empty")
+	}
+
+	if len(categoryCreate.AttributeSchema) != 0 {
+		_, err := compileAttributeSchema(strings.TrimSpace(categoryCreate.Name), categoryCreate.AttributeSchema)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if categoryCreate.ParentId == 0 {
+		return nil
+	}
+
+	if categoryCreate.ParentId == categoryId {
+		return errors.New("Category can not be its own parent")
+	}
+
+	_, err := categoryService.categoryRepository.GetById(categoryCreate.ParentId)
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("Parent category not found with id %d", categoryCreate.ParentId))
+	}
+
+	if categoryId != 0 && categoryService.categoryRepository.IsDescendant(categoryCreate.ParentId, categoryId) {
+		return errors.New(fmt.Sprintf("Category %d is a descendant of category %d", categoryCreate.ParentId, categoryId))
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package dto
+
+type CategoryCreate struct {
+	Name            string
+	ParentId        int64
+	AttributeSchema []byte
+}
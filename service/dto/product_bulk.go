@@ -0,0 +1,13 @@
+package dto
+
+type ProductBulk struct {
+	Atomic  bool
+	Creates []ProductCreate
+	Updates []ProductPriceUpdate
+	Deletes []int64
+}
+
+type ProductPriceUpdate struct {
+	Id    int64
+	Price float32
+}
@@ -1,8 +1,11 @@
 package dto
 
 type ProductCreate struct {
-	Name     string
-	Price    float32
-	Discount float32
-	Store    string
+	Name        string
+	Price       float32
+	Discount    float32
+	StoreId     int64
+	Store       string
+	Attributes  map[string]interface{}
+	ExternalRef string
 }
@@ -0,0 +1,8 @@
+package dto
+
+// ProductSync is a feed of products keyed by their external reference. A
+// full feed holds the whole catalog.
+type ProductSync struct {
+	Full     bool
+	Products []ProductCreate
+}
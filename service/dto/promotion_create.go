@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+type PromotionCreate struct {
+	Code         string
+	ProductIds   []int64
+	StoreIds     []int64
+	DiscountType string
+	Value        float32
+	ValidFrom    time.Time
+	ValidUntil   time.Time
+	UsageLimit   int32
+}
@@ -0,0 +1,10 @@
+package dto
+
+type Quote struct {
+	ProductId      int64
+	Price          float32
+	Discount       float32
+	CouponCode     string
+	CouponDiscount float32
+	EffectivePrice float32
+}
@@ -0,0 +1,5 @@
+package dto
+
+type StoreCreate struct {
+	Name string
+}
@@ -0,0 +1,8 @@
+package dto
+
+type VariantCreate struct {
+	Sku           string
+	Attributes    map[string]string
+	PriceOverride *float32
+	Barcode       string
+}
@@ -0,0 +1,8 @@
+package dto
+
+type WebhookCreate struct {
+	Url        string
+	EventTypes []string
+	StoreId    int64
+	Secret     string
+}
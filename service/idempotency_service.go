@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"time"
+)
+
+type IIdempotencyService interface {
+	Begin(key string, route string, requestHash string) (domain.IdempotencyRecord, *domain.StoredResponse, error)
+	Complete(claim domain.IdempotencyRecord, response domain.StoredResponse)
+	Release(claim domain.IdempotencyRecord)
+	PurgeExpired() (int64, error)
+}
+
+const MAX_IDEMPOTENCY_KEY_LENGTH = 255
+
+var (
+	ErrInvalidIdempotencyKey  = errors.New(fmt.Sprintf("Idempotency key must be 1 to %d characters", MAX_IDEMPOTENCY_KEY_LENGTH))
+	ErrIdempotencyKeyReused   = errors.New("Idempotency key was already used for a different request")
+	ErrIdempotencyKeyInFlight = errors.New("A request with this idempotency key is still in progress")
+)
+
+// IdempotencyService remembers the first response to a request with an
+// idempotency key for window, so that retries get the same response instead
+// of repeating the request. A request that neither completes nor fails
+// within inFlightTimeout, as when its instance died, gives up the key.
+type IdempotencyService struct {
+	idempotencyRepository persistence.IIdempotencyRepository
+	window                time.Duration
+	inFlightTimeout       time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepository persistence.IIdempotencyRepository, window time.Duration, inFlightTimeout time.Duration) IIdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepository: idempotencyRepository,
+		window:                window,
+		inFlightTimeout:       inFlightTimeout,
+	}
+}
+
+// Begin claims the key for the request. It returns the stored response when
+// the same request already completed, ErrIdempotencyKeyReused when the key
+// belongs to a different request and ErrIdempotencyKeyInFlight while the
+// first request is still running. Otherwise the caller holds the claim and
+// has to Complete or Release it.
+func (idempotencyService *IdempotencyService) Begin(key string, route string, requestHash string) (domain.IdempotencyRecord, *domain.StoredResponse, error) {
+	if len(key) == 0 || len(key) > MAX_IDEMPOTENCY_KEY_LENGTH {
+		return domain.IdempotencyRecord{}, nil, ErrInvalidIdempotencyKey
+	}
+
+	record, claimed, err := idempotencyService.idempotencyRepository.Claim(key, route, requestHash, idempotencyService.window,
+		idempotencyService.inFlightTimeout)
+
+	switch {
+	case err != nil:
+		return domain.IdempotencyRecord{}, nil, err
+	case claimed:
+		return record, nil, nil
+	case record.RequestHash != requestHash:
+		return domain.IdempotencyRecord{}, nil, ErrIdempotencyKeyReused
+	case record.Response == nil:
+		return domain.IdempotencyRecord{}, nil, ErrIdempotencyKeyInFlight
+	}
+
+	return record, record.Response, nil
+}
+
+// Complete stores the response of a claimed request. When that fails the key
+// is released, so that a retry runs the request again rather than waiting
+// for the in flight timeout.
+func (idempotencyService *IdempotencyService) Complete(claim domain.IdempotencyRecord, response domain.StoredResponse) {
+	if err := idempotencyService.idempotencyRepository.Complete(claim, response); err != nil {
+		idempotencyService.Release(claim)
+	}
+}
+
+func (idempotencyService *IdempotencyService) Release(claim domain.IdempotencyRecord) {
+	if err := idempotencyService.idempotencyRepository.Release(claim); err != nil {
+		log.Errorf("Idempotency key %s stays in flight until it times out %v", claim.Key, err)
+	}
+}
+
+func (idempotencyService *IdempotencyService) PurgeExpired() (int64, error) {
+	return idempotencyService.idempotencyRepository.PurgeExpired()
+}
+
+// IdempotencyPurgeJob periodically removes the expired idempotency keys.
+type IdempotencyPurgeJob struct {
+	idempotencyService IIdempotencyService
+	interval           time.Duration
+}
+
+func NewIdempotencyPurgeJob(idempotencyService IIdempotencyService, interval time.Duration) *IdempotencyPurgeJob {
+	return &IdempotencyPurgeJob{
+		idempotencyService: idempotencyService,
+		interval:           interval,
+	}
+}
+
+// Run purges once right away and then on every interval until ctx is done.
+func (idempotencyPurgeJob *IdempotencyPurgeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyPurgeJob.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := idempotencyPurgeJob.idempotencyService.PurgeExpired(); err == nil && purged > 0 {
+			log.Infof("%d expired idempotency keys purged", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+)
+
+type IInventoryService interface {
+	GetAllByProductId(productId int64) ([]domain.Inventory, error)
+	Adjust(productId int64, storeId int64, delta int32) (domain.Inventory, error)
+	Reserve(productId int64, storeId int64, quantity int32) (domain.Inventory, error)
+	Release(productId int64, storeId int64, quantity int32) (domain.Inventory, error)
+	Commit(productId int64, storeId int64, quantity int32) (domain.Inventory, error)
+}
+
+type InventoryConflictError struct {
+	ProductId int64
+	StoreId   int64
+	Reason    string
+}
+
+func (inventoryConflictError *InventoryConflictError) Error() string {
+	return fmt.Sprintf("Inventory of product %d in store %d: %s", inventoryConflictError.ProductId, inventoryConflictError.StoreId, inventoryConflictError.Reason)
+}
+
+type InventoryService struct {
+	inventoryRepository persistence.IInventoryRepository
+	productRepository   persistence.IProductRepository
+}
+
+func NewInventoryService(inventoryRepository persistence.IInventoryRepository, productRepository persistence.IProductRepository) IInventoryService {
+	return &InventoryService{
+		inventoryRepository: inventoryRepository,
+		productRepository:   productRepository,
+	}
+}
+
+func (inventoryService *InventoryService) GetAllByProductId(productId int64) ([]domain.Inventory, error) {
+	_, err := inventoryService.productRepository.GetById(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return inventoryService.inventoryRepository.GetAllByProductId(productId), nil
+}
+
+func (inventoryService *InventoryService) Adjust(productId int64, storeId int64, delta int32) (domain.Inventory, error) {
+	return inventoryService.apply(productId, storeId, "not enough unreserved stock to remove", func() (bool, error) {
+		return inventoryService.inventoryRepository.Adjust(productId, storeId, delta)
+	})
+}
+
+func (inventoryService *InventoryService) Reserve(productId int64, storeId int64, quantity int32) (domain.Inventory, error) {
+	return inventoryService.apply(productId, storeId, "not enough stock available", func() (bool, error) {
+		return inventoryService.inventoryRepository.Reserve(productId, storeId, quantity)
+	})
+}
+
+func (inventoryService *InventoryService) Release(productId int64, storeId int64, quantity int32) (domain.Inventory, error) {
+	return inventoryService.apply(productId, storeId, "not enough stock reserved", func() (bool, error) {
+		return inventoryService.inventoryRepository.Release(productId, storeId, quantity)
+	})
+}
+
+func (inventoryService *InventoryService) Commit(productId int64, storeId int64, quantity int32) (domain.Inventory, error) {
+	return inventoryService.apply(productId, storeId, "not enough stock reserved", func() (bool, error) {
+		return inventoryService.inventoryRepository.Commit(productId, storeId, quantity)
+	})
+}
+
+func (inventoryService *InventoryService) apply(productId int64, storeId int64, conflictReason string, update func() (bool, error)) (domain.Inventory, error) {
+	_, err := inventoryService.productRepository.GetById(productId)
+
+	if err != nil {
+		return domain.Inventory{}, err
+	}
+
+	applied, err := update()
+
+	if err != nil {
+		return domain.Inventory{}, err
+	}
+
+	if !applied {
+		return domain.Inventory{}, &InventoryConflictError{ProductId: productId, StoreId: storeId, Reason: conflictReason}
+	}
+
+	return inventoryService.inventoryRepository.Get(productId, storeId)
+}
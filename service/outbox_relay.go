@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"strings"
+	"time"
+)
+
+// OutboxRelayConfig controls how often the relay polls the outbox, how many
+// messages it claims at a time and how failed deliveries are retried. Lease
+// is how long claimed messages are kept from other relays; it has to cover
+// delivering a whole batch, or messages may be delivered twice. Delivered
+// messages are deleted once they are older than Retention.
+type OutboxRelayConfig struct {
+	PollInterval    time.Duration
+	BatchSize       int
+	Lease           time.Duration
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	Retention       time.Duration
+}
+
+// OUTBOX_PURGE_INTERVAL is how often the relay deletes the delivered
+// messages that are past their retention.
+const OUTBOX_PURGE_INTERVAL = time.Minute
+
+// OutboxRelay delivers the events written to the outbox to every sink, at
+// least once. A message is done only when all sinks accepted it;
+// a failing sink is retried with exponential backoff while the sinks that
+// already succeeded are skipped, and after MaxAttempts the message is dead
+// lettered. Retried messages may therefore reach a sink out of order, or
+// twice when the relay stops between a delivery and storing its outcome or
+// a batch outlasts its lease.
+type OutboxRelay struct {
+	outboxRepository persistence.IOutboxRepository
+	sinks            []IOutboxSink
+	config           OutboxRelayConfig
+}
+
+func NewOutboxRelay(outboxRepository persistence.IOutboxRepository, sinks []IOutboxSink, config OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepository: outboxRepository,
+		sinks:            sinks,
+		config:           config,
+	}
+}
+
+// Run relays on every poll interval until ctx is done. A full batch is
+// followed by the next one right away, so a backlog drains without waiting.
+func (outboxRelay *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxRelay.config.PollInterval)
+	defer ticker.Stop()
+
+	purgeTicker := time.NewTicker(OUTBOX_PURGE_INTERVAL)
+	defer purgeTicker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			if outboxRelay.RelayOnce() < outboxRelay.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-purgeTicker.C:
+			outboxRelay.PurgeOnce()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce deletes the delivered messages older than the retention period
+// and returns how many there were.
+func (outboxRelay *OutboxRelay) PurgeOnce() int64 {
+	purged, err := outboxRelay.outboxRepository.DeleteDeliveredBefore(time.Now().Add(-outboxRelay.config.Retention))
+
+	if err != nil {
+		log.Errorf("Error while purging outbox %v", err)
+	}
+
+	return purged
+}
+
+// RelayOnce processes one batch of due messages and returns its size.
+func (outboxRelay *OutboxRelay) RelayOnce() int {
+	processed, err := outboxRelay.outboxRepository.ProcessBatch(outboxRelay.config.BatchSize, outboxRelay.config.Lease, outboxRelay.deliver)
+
+	if err != nil {
+		log.Errorf("Error while relaying outbox %v", err)
+	}
+
+	return processed
+}
+
+func (outboxRelay *OutboxRelay) deliver(messages []domain.OutboxMessage) []domain.OutboxOutcome {
+	outcomes := make([]domain.OutboxOutcome, 0, len(messages))
+
+	for _, message := range messages {
+		outcomes = append(outcomes, outboxRelay.deliverMessage(message))
+	}
+
+	return outcomes
+}
+
+func (outboxRelay *OutboxRelay) deliverMessage(message domain.OutboxMessage) domain.OutboxOutcome {
+	outcome := domain.OutboxOutcome{Id: message.Id, DeliveredSinks: append([]string{}, message.DeliveredSinks...)}
+	event := message.Event
+	event.Id = uint64(message.Id)
+
+	var failures []string
+
+	for _, sink := range outboxRelay.sinks {
+		if containsString(outcome.DeliveredSinks, sink.Name()) {
+			continue
+		}
+
+		if err := sink.Deliver(event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+			continue
+		}
+
+		outcome.DeliveredSinks = append(outcome.DeliveredSinks, sink.Name())
+	}
+
+	if len(failures) == 0 {
+		outcome.Delivered = true
+		return outcome
+	}
+
+	attempts := message.Attempts + 1
+	outcome.Error = strings.Join(failures, "; ")
+
+	if attempts >= outboxRelay.config.MaxAttempts {
+		log.Errorf("Outbox message %d dead lettered after %d attempts %s", message.Id, attempts, outcome.Error)
+		outcome.DeadLetter = true
+		return outcome
+	}
+
+	outcome.NextAttemptAt = time.Now().Add(exponentialBackoff(outboxRelay.config.RetryBackoff, outboxRelay.config.MaxRetryBackoff, attempts))
+
+	return outcome
+}
+
+// exponentialBackoff doubles the backoff with every failed attempt up to
+// maxBackoff.
+func exponentialBackoff(backoff time.Duration, maxBackoff time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+
+	return backoff
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
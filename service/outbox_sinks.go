@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	OUTBOX_SINK_BUS                   = "bus"
+	OUTBOX_SINK_LOG                   = "log"
+	OUTBOX_SINK_WEBHOOK               = "webhook"
+	OUTBOX_SINK_WEBHOOK_SUBSCRIPTIONS = "webhook_subscriptions"
+)
+
+// IOutboxSink is a destination of the outbox relay. Name identifies the
+// sink in the outbox across restarts, so it must not change once deployed.
+// Deliver may be called again with an event it already accepted.
+type IOutboxSink interface {
+	Name() string
+	Deliver(event domain.ProductEvent) error
+}
+
+// ProductEventBusSink publishes to the in-process event bus behind the SSE
+// and WebSocket endpoints. It only reaches the subscribers of the instance
+// whose relay claimed the message, so with several instances the bus is fed
+// by a ProductEventFeed instead.
+type ProductEventBusSink struct {
+	productEventBus IProductEventBus
+}
+
+func NewProductEventBusSink(productEventBus IProductEventBus) IOutboxSink {
+	return &ProductEventBusSink{productEventBus: productEventBus}
+}
+
+func (productEventBusSink *ProductEventBusSink) Name() string {
+	return OUTBOX_SINK_BUS
+}
+
+func (productEventBusSink *ProductEventBusSink) Deliver(event domain.ProductEvent) error {
+	productEventBusSink.productEventBus.Publish(event)
+	return nil
+}
+
+// LogFileSink appends every event to a file as one JSON object per line.
+type LogFileSink struct {
+	path string
+}
+
+func NewLogFileSink(path string) IOutboxSink {
+	return &LogFileSink{path: path}
+}
+
+func (logFileSink *LogFileSink) Name() string {
+	return OUTBOX_SINK_LOG
+}
+
+// Deliver opens the file for each event, so the file can be rotated while
+// the relay runs.
+func (logFileSink *LogFileSink) Deliver(event domain.ProductEvent) error {
+	line, err := json.Marshal(toOutboxEventMessage(event))
+
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(logFileSink.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// WebhookSink posts every event as JSON to a fixed url. Any status other
+// than 2xx counts as a failed delivery.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) IOutboxSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (webhookSink *WebhookSink) Name() string {
+	return OUTBOX_SINK_WEBHOOK
+}
+
+func (webhookSink *WebhookSink) Deliver(event domain.ProductEvent) error {
+	body, err := json.Marshal(toOutboxEventMessage(event))
+
+	if err != nil {
+		return err
+	}
+
+	res, err := webhookSink.client.Post(webhookSink.url, "application/json", bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.New(fmt.Sprintf("Webhook answered with status %d", res.StatusCode))
+	}
+
+	return nil
+}
+
+// WebhookSubscriptionSink hands events to the webhook subscriptions. It only
+// records a delivery per matching subscription; the WebhookDispatcher sends
+// them, so a slow partner never holds up the outbox.
+type WebhookSubscriptionSink struct {
+	webhookRepository persistence.IWebhookRepository
+}
+
+func NewWebhookSubscriptionSink(webhookRepository persistence.IWebhookRepository) IOutboxSink {
+	return &WebhookSubscriptionSink{webhookRepository: webhookRepository}
+}
+
+func (webhookSubscriptionSink *WebhookSubscriptionSink) Name() string {
+	return OUTBOX_SINK_WEBHOOK_SUBSCRIPTIONS
+}
+
+func (webhookSubscriptionSink *WebhookSubscriptionSink) Deliver(event domain.ProductEvent) error {
+	payload, err := json.Marshal(toOutboxEventMessage(event))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = webhookSubscriptionSink.webhookRepository.EnqueueDeliveries(int64(event.Id), event.Type, event.Product.StoreId, payload)
+
+	return err
+}
+
+// outboxEventMessage is the JSON form of an event written by the log file
+// and webhook sinks and posted to webhook subscriptions. Id is the outbox
+// id, which consumers can use to drop redeliveries.
+type outboxEventMessage struct {
+	Id            uint64                 `json:"id"`
+	Type          string                 `json:"type"`
+	ProductId     int64                  `json:"productId"`
+	Name          string                 `json:"name,omitempty"`
+	Price         float32                `json:"price,omitempty"`
+	Discount      float32                `json:"discount,omitempty"`
+	StoreId       int64                  `json:"storeId"`
+	Store         string                 `json:"store"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	PreviousPrice float32                `json:"previousPrice,omitempty"`
+	OccurredAt    time.Time              `json:"occurredAt"`
+}
+
+func toOutboxEventMessage(event domain.ProductEvent) outboxEventMessage {
+	return outboxEventMessage{
+		Id:            event.Id,
+		Type:          event.Type,
+		ProductId:     event.Product.Id,
+		Name:          event.Product.Name,
+		Price:         event.Product.Price,
+		Discount:      event.Product.Discount,
+		StoreId:       event.Product.StoreId,
+		Store:         event.Product.Store,
+		Attributes:    event.Product.Attributes,
+		PreviousPrice: event.PreviousPrice,
+		OccurredAt:    event.OccurredAt,
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"sync"
+	"time"
+)
+
+const (
+	PRODUCT_EVENT_REPLAY_SIZE = 1000
+	// PRODUCT_EVENT_SUBSCRIBER_BUFFER is how many events a subscriber may
+	// fall behind before it is dropped.
+	PRODUCT_EVENT_SUBSCRIBER_BUFFER = 256
+)
+
+type IProductEventBus interface {
+	Publish(event domain.ProductEvent)
+	Subscribe(lastEventId uint64) *ProductEventSubscription
+}
+
+// ProductEventBus fans product events out to in-process subscribers and
+// keeps the latest ones so a reconnecting subscriber can catch up. Events
+// keep their outbox id, so a subscriber can resume on any instance; events
+// without one are numbered after the latest. Publish never blocks: a
+// subscriber whose buffer is full is dropped and its channel closed, and it
+// resumes from the replay buffer when it subscribes again.
+//
+// ProductService does not publish to the bus itself. The ProductEventFeed
+// publishes the committed changes of every instance as they are notified,
+// so a subscriber sees the same events whichever instance it is connected
+// to, and never one of a write that was rolled back.
+type ProductEventBus struct {
+	mutex       sync.Mutex
+	lastId      uint64
+	replay      []domain.ProductEvent
+	replaySize  int
+	subscribers map[*ProductEventSubscription]struct{}
+}
+
+func NewProductEventBus(replaySize int) IProductEventBus {
+	return &ProductEventBus{
+		replaySize:  replaySize,
+		subscribers: map[*ProductEventSubscription]struct{}{},
+	}
+}
+
+type ProductEventSubscription struct {
+	// Replay holds the buffered events published after the last event id
+	// given to Subscribe, oldest first.
+	Replay []domain.ProductEvent
+	// Events delivers the events published after Subscribe returned. It is
+	// closed when the subscription is closed or dropped.
+	Events <-chan domain.ProductEvent
+
+	events chan domain.ProductEvent
+	bus    *ProductEventBus
+}
+
+func (productEventBus *ProductEventBus) Publish(event domain.ProductEvent) {
+	productEventBus.mutex.Lock()
+	defer productEventBus.mutex.Unlock()
+
+	if event.Id == 0 {
+		event.Id = productEventBus.lastId + 1
+	}
+
+	if event.Id > productEventBus.lastId {
+		productEventBus.lastId = event.Id
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	productEventBus.replay = append(productEventBus.replay, event)
+	if len(productEventBus.replay) > productEventBus.replaySize {
+		productEventBus.replay = productEventBus.replay[len(productEventBus.replay)-productEventBus.replaySize:]
+	}
+
+	for subscription := range productEventBus.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			delete(productEventBus.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. With a lastEventId of 0 nothing is
+// replayed. Otherwise the events buffered after the one with lastEventId are
+// replayed, or all of them when that event is no longer buffered. Events are
+// replayed in the order they were published rather than by id: outbox ids
+// are taken when a change is written but published when it commits, so a
+// lower id can follow a higher one.
+func (productEventBus *ProductEventBus) Subscribe(lastEventId uint64) *ProductEventSubscription {
+	productEventBus.mutex.Lock()
+	defer productEventBus.mutex.Unlock()
+
+	events := make(chan domain.ProductEvent, PRODUCT_EVENT_SUBSCRIBER_BUFFER)
+	subscription := &ProductEventSubscription{Events: events, events: events, bus: productEventBus}
+
+	if lastEventId != 0 {
+		start := 0
+
+		for i, event := range productEventBus.replay {
+			if event.Id == lastEventId {
+				start = i + 1
+				break
+			}
+		}
+
+		subscription.Replay = append(subscription.Replay, productEventBus.replay[start:]...)
+	}
+
+	productEventBus.subscribers[subscription] = struct{}{}
+
+	return subscription
+}
+
+// Close stops delivery. It is safe to call after the subscription has been
+// dropped.
+func (productEventSubscription *ProductEventSubscription) Close() {
+	bus := productEventSubscription.bus
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	if _, found := bus.subscribers[productEventSubscription]; found {
+		delete(bus.subscribers, productEventSubscription)
+		close(productEventSubscription.events)
+	}
+}
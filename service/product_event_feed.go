@@ -0,0 +1,124 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/labstack/gommon/log"
+	"sync"
+)
+
+// ProductEventFeed delivers the product changes of every instance, as
+// received by the ProductChangeListener, to the event bus sink behind the
+// SSE and WebSocket endpoints.
+//
+// Notifications sent while the listener was reconnecting are lost, so on a
+// reconnect the feed replays the outbox events it has not delivered yet.
+// It remembers the outbox ids of the latest events it delivered, as ids are
+// taken when a change is written but notified when it commits: an event
+// with a lower id than the ones already delivered may still be missing.
+// Changes older than the retention of the outbox can not be replayed.
+type ProductEventFeed struct {
+	productEventBusSink IOutboxSink
+	outboxRepository    persistence.IOutboxRepository
+	mutex               sync.Mutex
+	listening           bool
+	// afterId is the outbox id up to which events are no longer replayed.
+	// delivered holds the ids of the latest delivered events, so replays
+	// skip them.
+	afterId   int64
+	delivered map[int64]struct{}
+}
+
+func NewProductEventFeed(productEventBusSink IOutboxSink, outboxRepository persistence.IOutboxRepository) persistence.IProductChangeHandler {
+	return &ProductEventFeed{
+		productEventBusSink: productEventBusSink,
+		outboxRepository:    outboxRepository,
+		delivered:           map[int64]struct{}{},
+	}
+}
+
+func (productEventFeed *ProductEventFeed) ProductChanged(event domain.ProductEvent) {
+	productEventFeed.mutex.Lock()
+	defer productEventFeed.mutex.Unlock()
+
+	productEventFeed.deliver(event)
+}
+
+// Resync notes where the outbox stands when the listener first listens, as
+// earlier changes are not the streams' to deliver. On every later call it
+// replays the events written since that were not delivered.
+func (productEventFeed *ProductEventFeed) Resync() {
+	productEventFeed.mutex.Lock()
+	defer productEventFeed.mutex.Unlock()
+
+	if !productEventFeed.listening {
+		lastId, err := productEventFeed.outboxRepository.GetLastId()
+
+		if err != nil {
+			return
+		}
+
+		productEventFeed.afterId = lastId
+		productEventFeed.listening = true
+		return
+	}
+
+	events, err := productEventFeed.outboxRepository.GetAfter(productEventFeed.afterId, PRODUCT_EVENT_REPLAY_SIZE)
+
+	if err != nil {
+		log.Errorf("Product changes missed while the change listener was reconnecting could not be replayed %v", err)
+		return
+	}
+
+	replayed := 0
+
+	for _, event := range events {
+		if productEventFeed.deliver(event) {
+			replayed++
+		}
+	}
+
+	log.Infof("%d product changes missed while the change listener was reconnecting replayed", replayed)
+}
+
+// deliver hands the event to the sink unless it was delivered before, and
+// reports whether it did.
+func (productEventFeed *ProductEventFeed) deliver(event domain.ProductEvent) bool {
+	id := int64(event.Id)
+
+	if id != 0 {
+		if _, found := productEventFeed.delivered[id]; found {
+			return false
+		}
+
+		productEventFeed.remember(id)
+	}
+
+	productEventFeed.productEventBusSink.Deliver(event)
+
+	return true
+}
+
+// remember keeps the ids of the latest PRODUCT_EVENT_REPLAY_SIZE delivered
+// events, moving afterId up to the lowest one when there are more.
+func (productEventFeed *ProductEventFeed) remember(id int64) {
+	productEventFeed.delivered[id] = struct{}{}
+
+	if len(productEventFeed.delivered) <= PRODUCT_EVENT_REPLAY_SIZE {
+		return
+	}
+
+	oldest := id
+
+	for deliveredId := range productEventFeed.delivered {
+		if deliveredId < oldest {
+			oldest = deliveredId
+		}
+	}
+
+	delete(productEventFeed.delivered, oldest)
+
+	if oldest > productEventFeed.afterId {
+		productEventFeed.afterId = oldest
+	}
+}
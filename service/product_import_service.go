@@ -0,0 +1,419 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service/dto"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	IMPORT_FORMAT_CSV  = "csv"
+	IMPORT_FORMAT_XLSX = "xlsx"
+	IMPORT_BATCH_SIZE  = 500
+
+	// MAX_XLSX_IMPORT_SIZE bounds the xlsx uploads spooled to disk, and
+	// MAX_XLSX_UNZIPPED_SIZE what they may unpack to, which keeps a small zip
+	// bomb from filling the disk.
+	MAX_XLSX_IMPORT_SIZE   = 50 << 20
+	MAX_XLSX_UNZIPPED_SIZE = 1 << 30
+)
+
+// ErrImportTooLarge is returned for an upload over MAX_XLSX_IMPORT_SIZE.
+var ErrImportTooLarge = errors.New(fmt.Sprintf("Xlsx file can not be larger than %d MB", MAX_XLSX_IMPORT_SIZE>>20))
+
+// importFields are the product fields a column can be mapped to. Columns
+// named attr.<key> become attributes and need no mapping.
+var importFields = []string{"name", "price", "discount", "storeId", "store"}
+
+type IProductImportService interface {
+	Import(source io.Reader, format string, mapping map[string]string, dryRun bool) (domain.ImportReport, error)
+}
+
+type ProductImportService struct {
+	productService IProductService
+}
+
+func NewProductImportService(productService IProductService) IProductImportService {
+	return &ProductImportService{
+		productService: productService,
+	}
+}
+
+// Import reads the source row by row and never holds more than one batch of
+// products in memory. The mapping goes from product field to the header of
+// the column holding it; unmapped fields are looked up by their own name.
+// Rows are validated by the product service and written in best-effort
+// batches, so a bad row is reported without stopping the others. A dry run
+// only validates.
+func (productImportService *ProductImportService) Import(source io.Reader, format string, mapping map[string]string, dryRun bool) (domain.ImportReport, error) {
+	reader, err := newImportRowReader(source, format)
+
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	header, err := reader.Read()
+
+	if err == io.EOF {
+		return domain.ImportReport{}, errors.New("Import file is empty")
+	}
+
+	if err != nil {
+		return domain.ImportReport{}, errors.New(fmt.Sprintf("Could not read import header: %v", err))
+	}
+
+	columns, err := newImportColumns(header, mapping)
+
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	report := domain.ImportReport{DryRun: dryRun, Errors: []domain.ImportRowError{}}
+	var pendingCreates []dto.ProductCreate
+	var pendingRows []int
+
+	flush := func() error {
+		if len(pendingCreates) == 0 {
+			return nil
+		}
+
+		results, err := productImportService.productService.ApplyBulk(dto.ProductBulk{Creates: pendingCreates})
+
+		if results == nil {
+			return err
+		}
+
+		for _, result := range results {
+			if len(result.Error) != 0 {
+				report.Errors = append(report.Errors, domain.ImportRowError{Row: pendingRows[result.Index], Error: result.Error})
+			} else {
+				report.Imported++
+			}
+		}
+
+		pendingCreates, pendingRows = nil, nil
+
+		return nil
+	}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Rows++
+			report.Errors = append(report.Errors, domain.ImportRowError{Row: row, Error: parseErr.Err.Error()})
+			continue
+		}
+
+		if err != nil {
+			return report, errors.New(fmt.Sprintf("Could not read import row %d: %v", row, err))
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		report.Rows++
+		productCreate, err := columns.toProductCreate(record)
+
+		if err == nil && dryRun {
+			err = productImportService.productService.Validate(productCreate)
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, domain.ImportRowError{Row: row, Error: err.Error()})
+			continue
+		}
+
+		if dryRun {
+			report.Imported++
+			continue
+		}
+
+		pendingCreates = append(pendingCreates, productCreate)
+		pendingRows = append(pendingRows, row)
+
+		if len(pendingCreates) == IMPORT_BATCH_SIZE {
+			if err = flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, flush()
+}
+
+type importRowReader interface {
+	Read() ([]string, error)
+}
+
+func newImportRowReader(source io.Reader, format string) (importRowReader, error) {
+	switch format {
+	case IMPORT_FORMAT_CSV:
+		csvReader := csv.NewReader(source)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		return csvReader, nil
+	case IMPORT_FORMAT_XLSX:
+		return newXlsxRowReader(source)
+	default:
+		return nil, errors.New("Import format must be csv or xlsx")
+	}
+}
+
+// xlsxRowReader reads the first sheet. An xlsx file is a zip archive that
+// can only be read from its end, so the upload is spooled to a temporary
+// file, whose size is bounded, before excelize opens it. Large sheets are
+// unpacked to temporary files by excelize and their rows decoded one at a
+// time. Close removes the spooled file.
+type xlsxRowReader struct {
+	path string
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func newXlsxRowReader(source io.Reader) (*xlsxRowReader, error) {
+	path, err := spoolXlsx(source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := excelize.OpenFile(path, excelize.Options{UnzipSizeLimit: MAX_XLSX_UNZIPPED_SIZE})
+
+	if err != nil {
+		os.Remove(path)
+		return nil, errors.New("Could not read xlsx file")
+	}
+
+	rows, err := file.Rows(file.GetSheetName(0))
+
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, errors.New("Could not read xlsx sheet")
+	}
+
+	return &xlsxRowReader{path: path, file: file, rows: rows}, nil
+}
+
+// spoolXlsx copies the upload to a temporary file and returns its path. An
+// upload over MAX_XLSX_IMPORT_SIZE is refused without reading the rest.
+func spoolXlsx(source io.Reader) (string, error) {
+	spool, err := os.CreateTemp("", "product-import-*.xlsx")
+
+	if err != nil {
+		return "", errors.New("Could not store xlsx file")
+	}
+
+	written, copyErr := io.Copy(spool, io.LimitReader(source, MAX_XLSX_IMPORT_SIZE+1))
+	closeErr := spool.Close()
+
+	switch {
+	case copyErr != nil:
+		err = errors.New("Could not read xlsx file")
+	case closeErr != nil:
+		err = errors.New("Could not store xlsx file")
+	case written > MAX_XLSX_IMPORT_SIZE:
+		err = ErrImportTooLarge
+	}
+
+	if err != nil {
+		os.Remove(spool.Name())
+		return "", err
+	}
+
+	return spool.Name(), nil
+}
+
+func (xlsxRowReader *xlsxRowReader) Read() ([]string, error) {
+	if xlsxRowReader.rows.Next() {
+		return xlsxRowReader.rows.Columns()
+	}
+
+	if err := xlsxRowReader.rows.Error(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+func (xlsxRowReader *xlsxRowReader) Close() error {
+	xlsxRowReader.rows.Close()
+	xlsxRowReader.file.Close()
+
+	return os.Remove(xlsxRowReader.path)
+}
+
+// importColumns holds the position of each mapped field and attribute
+// column in the header.
+type importColumns struct {
+	fields     map[string]int
+	attributes map[string]int
+}
+
+func newImportColumns(header []string, mapping map[string]string) (importColumns, error) {
+	for field := range mapping {
+		if !isImportField(field) {
+			return importColumns{}, errors.New(fmt.Sprintf("Unknown import field %q", field))
+		}
+	}
+
+	positions := map[string]int{}
+	for i, name := range header {
+		positions[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := importColumns{fields: map[string]int{}, attributes: map[string]int{}}
+
+	for _, field := range importFields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+
+		position, found := positions[strings.ToLower(strings.TrimSpace(name))]
+
+		if found {
+			columns.fields[field] = position
+		} else if mapped {
+			return importColumns{}, errors.New(fmt.Sprintf("Column %q mapped to %s not found", name, field))
+		}
+	}
+
+	for _, field := range []string{"name", "price"} {
+		if _, found := columns.fields[field]; !found {
+			return importColumns{}, errors.New(fmt.Sprintf("Column for %s not found", field))
+		}
+	}
+
+	_, hasStoreId := columns.fields["storeId"]
+	_, hasStore := columns.fields["store"]
+
+	if !hasStoreId && !hasStore {
+		return importColumns{}, errors.New("Column for storeId or store not found")
+	}
+
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+
+		if !strings.HasPrefix(name, "attr.") {
+			continue
+		}
+
+		key := strings.TrimPrefix(name, "attr.")
+
+		if !domain.IsAttributeKey(key) {
+			return importColumns{}, errors.New(fmt.Sprintf("Column %q is not a valid attribute", name))
+		}
+
+		columns.attributes[key] = i
+	}
+
+	return columns, nil
+}
+
+func (columns importColumns) toProductCreate(record []string) (dto.ProductCreate, error) {
+	cell := func(position int) string {
+		if position < len(record) {
+			return strings.TrimSpace(record[position])
+		}
+		return ""
+	}
+
+	field := func(name string) string {
+		position, found := columns.fields[name]
+		if !found {
+			return ""
+		}
+		return cell(position)
+	}
+
+	productCreate := dto.ProductCreate{Name: field("name"), Store: field("store")}
+
+	if len(productCreate.Name) == 0 {
+		return dto.ProductCreate{}, errors.New("Name can not be empty")
+	}
+
+	price, err := strconv.ParseFloat(field("price"), 32)
+
+	if err != nil {
+		return dto.ProductCreate{}, errors.New("Price must be a number")
+	}
+
+	productCreate.Price = float32(price)
+
+	if discount := field("discount"); len(discount) != 0 {
+		value, err := strconv.ParseFloat(discount, 32)
+
+		if err != nil {
+			return dto.ProductCreate{}, errors.New("Discount must be a number")
+		}
+
+		productCreate.Discount = float32(value)
+	}
+
+	if storeId := field("storeId"); len(storeId) != 0 {
+		value, err := strconv.ParseInt(storeId, 10, 64)
+
+		if err != nil {
+			return dto.ProductCreate{}, errors.New("StoreId must be a store id")
+		}
+
+		productCreate.StoreId = value
+	}
+
+	for key, position := range columns.attributes {
+		value := cell(position)
+
+		if len(value) == 0 {
+			continue
+		}
+
+		if productCreate.Attributes == nil {
+			productCreate.Attributes = map[string]interface{}{}
+		}
+
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			productCreate.Attributes[key] = number
+		} else {
+			productCreate.Attributes[key] = value
+		}
+	}
+
+	return productCreate, nil
+}
+
+func isImportField(field string) bool {
+	for _, importField := range importFields {
+		if importField == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if len(strings.TrimSpace(value)) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -5,17 +5,59 @@ import (
 	"example.com/product-api/domain"
 	"example.com/product-api/persistence"
 	"example.com/product-api/service/dto"
+	"fmt"
+	"strings"
+	"time"
 )
 
 type IProductService interface {
 	GetAll() []domain.Product
 	GetById(productId int64) (domain.Product, error)
 	GetAllByStore(storeName string) []domain.Product
-	Add(productCreate dto.ProductCreate) error
+	GetAllByFilter(filter domain.ProductFilter) []domain.Product
+	GetPage(filter domain.ProductFilter, afterId int64, limit int) (domain.ProductPage, error)
+	Export(filter domain.ProductFilter, consume func(domain.Product) error) error
+	Search(query string, filter domain.ProductFilter, limit int) ([]domain.ProductSearchResult, error)
+	GetFacets(filter domain.ProductFilter) domain.ProductFacets
+	FindDuplicates(storeName string, threshold float32, limit int) ([]domain.ProductDuplicate, error)
+	Add(productCreate dto.ProductCreate) (domain.Product, error)
+	Validate(productCreate dto.ProductCreate) error
 	UpdatePrice(productId int64, newPrice float32) error
 	DeleteById(productId int64) error
+	GetAllDeleted() []domain.DeletedProduct
+	Restore(productId int64) error
+	PurgeDeleted(retention time.Duration) (int64, error)
+	ApplyBulk(productBulk dto.ProductBulk) ([]domain.BulkResult, error)
+	UpsertByExternalRef(productCreate dto.ProductCreate) (domain.ProductUpsert, error)
+	SyncByExternalRef(productSync dto.ProductSync) (domain.ProductSyncReport, error)
 }
 
+const (
+	DEFAULT_SEARCH_LIMIT = 20
+	MAX_SEARCH_LIMIT     = 100
+	DEFAULT_PAGE_SIZE    = 20
+	MAX_PAGE_SIZE        = 100
+	MAX_BULK_OPERATIONS  = 10000
+
+	DEFAULT_DUPLICATE_THRESHOLD = 0.6
+	DEFAULT_DUPLICATE_LIMIT     = 100
+	MAX_DUPLICATE_LIMIT         = 1000
+
+	MAX_EXTERNAL_REF_LENGTH = 100
+	MAX_SYNC_PRODUCTS       = 50000
+)
+
+// ErrDuplicateProduct is returned when the store already has a product with
+// the same name.
+var ErrDuplicateProduct = persistence.ErrDuplicateProduct
+
+// ErrProductNotFound and ErrStoreNotFound are wrapped by the errors returned
+// for an id that does not exist.
+var (
+	ErrProductNotFound = persistence.ErrProductNotFound
+	ErrStoreNotFound   = persistence.ErrStoreNotFound
+)
+
 type ProductService struct {
 	productRepository persistence.IProductRepository
 }
@@ -38,19 +80,95 @@ func (productService *ProductService) GetAllByStore(storeName string) []domain.P
 	return productService.productRepository.GetAllByStore(storeName)
 }
 
-func (productService *ProductService) Add(productCreate dto.ProductCreate) error {
+func (productService *ProductService) GetAllByFilter(filter domain.ProductFilter) []domain.Product {
+	return productService.productRepository.GetAllByFilter(filter)
+}
+
+// GetPage returns the page of products after the product with afterId, or
+// the first page when afterId is 0.
+func (productService *ProductService) GetPage(filter domain.ProductFilter, afterId int64, limit int) (domain.ProductPage, error) {
+	if limit == 0 {
+		limit = DEFAULT_PAGE_SIZE
+	}
+
+	if limit < 0 || limit > MAX_PAGE_SIZE {
+		return domain.ProductPage{}, errors.New(fmt.Sprintf("Page size must be between 1 and %d", MAX_PAGE_SIZE))
+	}
+
+	if afterId < 0 {
+		return domain.ProductPage{}, errors.New("Cursor must be a product id")
+	}
+
+	// One extra product tells whether another page follows without a count.
+	products := productService.productRepository.GetPageByFilter(filter, afterId, limit+1)
+
+	if len(products) > limit {
+		return domain.ProductPage{Products: products[:limit], HasNextPage: true}, nil
+	}
+
+	return domain.ProductPage{Products: products}, nil
+}
+
+func (productService *ProductService) Export(filter domain.ProductFilter, consume func(domain.Product) error) error {
+	return productService.productRepository.StreamAllByFilter(filter, consume)
+}
+
+func (productService *ProductService) Search(query string, filter domain.ProductFilter, limit int) ([]domain.ProductSearchResult, error) {
+	if len(strings.TrimSpace(query)) == 0 {
+		return nil, errors.New("Search query can not be empty")
+	}
+
+	if limit == 0 {
+		limit = DEFAULT_SEARCH_LIMIT
+	}
+
+	if limit < 0 || limit > MAX_SEARCH_LIMIT {
+		return nil, errors.New(fmt.Sprintf("Limit must be between 1 and %d", MAX_SEARCH_LIMIT))
+	}
+
+	return productService.productRepository.Search(query, filter, limit), nil
+}
+
+func (productService *ProductService) GetFacets(filter domain.ProductFilter) domain.ProductFacets {
+	return productService.productRepository.GetFacets(filter)
+}
+
+// FindDuplicates reports the likely duplicate products of a store, or of all
+// stores when storeName is empty. Zero threshold and limit take the defaults.
+func (productService *ProductService) FindDuplicates(storeName string, threshold float32, limit int) ([]domain.ProductDuplicate, error) {
+	if threshold == 0 {
+		threshold = DEFAULT_DUPLICATE_THRESHOLD
+	}
+
+	if threshold < 0 || threshold > 1 {
+		return nil, errors.New("Threshold must be between 0 and 1")
+	}
+
+	if limit == 0 {
+		limit = DEFAULT_DUPLICATE_LIMIT
+	}
+
+	if limit < 0 || limit > MAX_DUPLICATE_LIMIT {
+		return nil, errors.New(fmt.Sprintf("Limit must be between 1 and %d", MAX_DUPLICATE_LIMIT))
+	}
+
+	return productService.productRepository.FindDuplicates(storeName, threshold, limit), nil
+}
+
+// Add returns the product as stored, with its id and store.
+func (productService *ProductService) Add(productCreate dto.ProductCreate) (domain.Product, error) {
 	validateErr := validateProductCreate(productCreate)
 
 	if validateErr != nil {
-		return validateErr
+		return domain.Product{}, validateErr
 	}
 
-	return productService.productRepository.Add(domain.Product{
-		Name:     productCreate.Name,
-		Price:    productCreate.Price,
-		Discount: productCreate.Discount,
-		Store:    productCreate.Store,
-	})
+	return productService.productRepository.Add(toProduct(productCreate))
+}
+
+// Validate runs the checks of Add without writing anything.
+func (productService *ProductService) Validate(productCreate dto.ProductCreate) error {
+	return validateProductCreate(productCreate)
 }
 
 func (productService *ProductService) UpdatePrice(productId int64, newPrice float32) error {
@@ -61,9 +179,169 @@ func (productService *ProductService) DeleteById(productId int64) error {
 	return productService.productRepository.DeleteById(productId)
 }
 
+func (productService *ProductService) GetAllDeleted() []domain.DeletedProduct {
+	return productService.productRepository.GetAllDeleted()
+}
+
+func (productService *ProductService) Restore(productId int64) error {
+	return productService.productRepository.Restore(productId)
+}
+
+// PurgeDeleted permanently removes the products that have been in the trash
+// for longer than retention.
+func (productService *ProductService) PurgeDeleted(retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		return 0, errors.New("Retention must be greater than 0")
+	}
+
+	return productService.productRepository.PurgeDeletedBefore(time.Now().Add(-retention))
+}
+
+// ApplyBulk validates every item the same way Add does. Atomic requests are
+// refused as a whole when any item is invalid; otherwise only the valid items
+// are sent to the repository and the invalid ones are reported next to them.
+// The returned results follow the order creates, updates, deletes.
+func (productService *ProductService) ApplyBulk(productBulk dto.ProductBulk) ([]domain.BulkResult, error) {
+	count := len(productBulk.Creates) + len(productBulk.Updates) + len(productBulk.Deletes)
+
+	if count == 0 {
+		return nil, errors.New("Bulk request has no operations")
+	}
+
+	if count > MAX_BULK_OPERATIONS {
+		return nil, errors.New(fmt.Sprintf("Bulk request can not have more than %d operations", MAX_BULK_OPERATIONS))
+	}
+
+	results := make([]domain.BulkResult, 0, count)
+	var operations []domain.BulkOperation
+	var positions []int
+
+	addOperation := func(operation domain.BulkOperation, validationErr error) {
+		if validationErr != nil {
+			results = append(results, domain.BulkResult{Action: operation.Action, Index: operation.Index, Error: validationErr.Error()})
+			return
+		}
+		positions = append(positions, len(results))
+		results = append(results, domain.BulkResult{Action: operation.Action, Index: operation.Index})
+		operations = append(operations, operation)
+	}
+
+	for i, productCreate := range productBulk.Creates {
+		addOperation(domain.BulkOperation{Action: domain.BULK_CREATE, Index: i, Product: toProduct(productCreate)},
+			validateProductCreate(productCreate))
+	}
+
+	for i, priceUpdate := range productBulk.Updates {
+		addOperation(domain.BulkOperation{Action: domain.BULK_UPDATE, Index: i, Product: domain.Product{Id: priceUpdate.Id, Price: priceUpdate.Price}},
+			validatePriceUpdate(priceUpdate))
+	}
+
+	for i, productId := range productBulk.Deletes {
+		addOperation(domain.BulkOperation{Action: domain.BULK_DELETE, Index: i, Product: domain.Product{Id: productId}},
+			validateProductId(productId))
+	}
+
+	if productBulk.Atomic && len(operations) != count {
+		return results, errors.New("Bulk operations rolled back")
+	}
+
+	if len(operations) == 0 {
+		return results, nil
+	}
+
+	appliedResults, err := productService.productRepository.ApplyBulk(operations, productBulk.Atomic)
+
+	if appliedResults == nil {
+		return nil, err
+	}
+
+	for i, appliedResult := range appliedResults {
+		results[positions[i]] = appliedResult
+	}
+
+	return results, err
+}
+
+// UpsertByExternalRef creates the product when no product has its external
+// reference yet and updates that product otherwise.
+func (productService *ProductService) UpsertByExternalRef(productCreate dto.ProductCreate) (domain.ProductUpsert, error) {
+	if err := validateSyncedProduct(productCreate); err != nil {
+		return domain.ProductUpsert{}, err
+	}
+
+	return productService.productRepository.UpsertByExternalRef(toProduct(productCreate))
+}
+
+// SyncByExternalRef upserts every product of the feed, or none of them when
+// any is invalid. A full feed also discontinues the synced products it does
+// not contain; an empty feed is refused, as it would discontinue them all.
+func (productService *ProductService) SyncByExternalRef(productSync dto.ProductSync) (domain.ProductSyncReport, error) {
+	if len(productSync.Products) == 0 {
+		return domain.ProductSyncReport{}, errors.New("Sync feed has no products")
+	}
+
+	if len(productSync.Products) > MAX_SYNC_PRODUCTS {
+		return domain.ProductSyncReport{}, errors.New(fmt.Sprintf("Sync feed can not have more than %d products", MAX_SYNC_PRODUCTS))
+	}
+
+	products := make([]domain.Product, 0, len(productSync.Products))
+	refs := map[string]bool{}
+
+	for i, productCreate := range productSync.Products {
+		if err := validateSyncedProduct(productCreate); err != nil {
+			return domain.ProductSyncReport{}, errors.New(fmt.Sprintf("Product %d: %s", i, err.Error()))
+		}
+
+		if refs[productCreate.ExternalRef] {
+			return domain.ProductSyncReport{}, errors.New(fmt.Sprintf("External reference %s appears more than once", productCreate.ExternalRef))
+		}
+
+		refs[productCreate.ExternalRef] = true
+		products = append(products, toProduct(productCreate))
+	}
+
+	return productService.productRepository.SyncByExternalRef(products, productSync.Full)
+}
+
+func toProduct(productCreate dto.ProductCreate) domain.Product {
+	return domain.Product{
+		Name:        productCreate.Name,
+		Price:       productCreate.Price,
+		Discount:    productCreate.Discount,
+		StoreId:     productCreate.StoreId,
+		Store:       productCreate.Store,
+		Attributes:  productCreate.Attributes,
+		ExternalRef: productCreate.ExternalRef,
+	}
+}
+
+func validateSyncedProduct(productCreate dto.ProductCreate) error {
+	if len(strings.TrimSpace(productCreate.ExternalRef)) == 0 || len(productCreate.ExternalRef) > MAX_EXTERNAL_REF_LENGTH {
+		return errors.New(fmt.Sprintf("External reference must be 1 to %d characters", MAX_EXTERNAL_REF_LENGTH))
+	}
+	return validateProductCreate(productCreate)
+}
+
+func validatePriceUpdate(priceUpdate dto.ProductPriceUpdate) error {
+	if priceUpdate.Price <= 0 {
+		return errors.New("Price must be greater than 0")
+	}
+	return validateProductId(priceUpdate.Id)
+}
+
+func validateProductId(productId int64) error {
+	if productId <= 0 {
+		return errors.New("Product id must be greater than 0")
+	}
+	return nil
+}
+
 func validateProductCreate(productCreate dto.ProductCreate) error {
 	if productCreate.Discount > 70.0 {
 		return errors.New("Discount can not be greater than 70")
 	}
-	return nil
+	if productCreate.StoreId == 0 && len(domain.NormalizeStoreName(productCreate.Store)) == 0 {
+		return errors.New("Store can not be empty")
+	}
+	return validateAttributeKeys(productCreate.Attributes)
 }
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service/dto"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type IPromotionService interface {
+	GetAll() []domain.Promotion
+	GetById(promotionId int64) (domain.Promotion, error)
+	Add(promotionCreate dto.PromotionCreate) error
+	Update(promotionId int64, promotionCreate dto.PromotionCreate) error
+	DeleteById(promotionId int64) error
+	Quote(productId int64, couponCode string) (dto.Quote, error)
+	Redeem(productId int64, couponCode string) (dto.Quote, error)
+}
+
+type CouponRefusedError struct {
+	Code   string
+	Reason string
+}
+
+func (couponRefusedError *CouponRefusedError) Error() string {
+	return fmt.Sprintf("Coupon %s refused: %s", couponRefusedError.Code, couponRefusedError.Reason)
+}
+
+type PromotionService struct {
+	promotionRepository persistence.IPromotionRepository
+	productRepository   persistence.IProductRepository
+	storeRepository     persistence.IStoreRepository
+}
+
+func NewPromotionService(promotionRepository persistence.IPromotionRepository, productRepository persistence.IProductRepository,
+	storeRepository persistence.IStoreRepository) IPromotionService {
+	return &PromotionService{
+		promotionRepository: promotionRepository,
+		productRepository:   productRepository,
+		storeRepository:     storeRepository,
+	}
+}
+
+func (promotionService *PromotionService) GetAll() []domain.Promotion {
+	return promotionService.promotionRepository.GetAll()
+}
+
+func (promotionService *PromotionService) GetById(promotionId int64) (domain.Promotion, error) {
+	return promotionService.promotionRepository.GetById(promotionId)
+}
+
+func (promotionService *PromotionService) Add(promotionCreate dto.PromotionCreate) error {
+	validateErr := promotionService.validate(promotionCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	return promotionService.promotionRepository.Add(toPromotion(promotionCreate))
+}
+
+func (promotionService *PromotionService) Update(promotionId int64, promotionCreate dto.PromotionCreate) error {
+	validateErr := promotionService.validate(promotionCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	promotion := toPromotion(promotionCreate)
+	promotion.Id = promotionId
+
+	return promotionService.promotionRepository.Update(promotion)
+}
+
+func (promotionService *PromotionService) DeleteById(promotionId int64) error {
+	return promotionService.promotionRepository.DeleteById(promotionId)
+}
+
+func (promotionService *PromotionService) Quote(productId int64, couponCode string) (dto.Quote, error) {
+	quote, _, err := promotionService.quote(productId, couponCode)
+
+	return quote, err
+}
+
+// Redeem applies the coupon to the product like Quote does and counts the
+// use. A coupon that reached its usage limit in the meantime is refused.
+func (promotionService *PromotionService) Redeem(productId int64, couponCode string) (dto.Quote, error) {
+	if len(couponCode) == 0 {
+		return dto.Quote{}, errors.New("Coupon code can not be empty")
+	}
+
+	quote, promotion, err := promotionService.quote(productId, couponCode)
+
+	if err != nil {
+		return dto.Quote{}, err
+	}
+
+	err = promotionService.promotionRepository.Redeem(promotion.Id)
+
+	if errors.Is(err, persistence.ErrPromotionExhausted) {
+		return dto.Quote{}, &CouponRefusedError{Code: promotion.Code, Reason: "usage limit reached"}
+	}
+
+	if err != nil {
+		return dto.Quote{}, err
+	}
+
+	return quote, nil
+}
+
+// quote returns the quote together with the promotion of the coupon, which
+// is empty when no coupon was given.
+func (promotionService *PromotionService) quote(productId int64, couponCode string) (dto.Quote, domain.Promotion, error) {
+	product, err := promotionService.productRepository.GetById(productId)
+
+	if err != nil {
+		return dto.Quote{}, domain.Promotion{}, err
+	}
+
+	quote := dto.Quote{
+		ProductId:      product.Id,
+		Price:          product.Price,
+		Discount:       product.Discount,
+		EffectivePrice: product.Price * (1 - product.Discount/100),
+	}
+
+	if len(couponCode) == 0 {
+		return quote, domain.Promotion{}, nil
+	}
+
+	promotion, err := promotionService.promotionRepository.GetByCode(couponCode)
+
+	if err != nil {
+		return dto.Quote{}, domain.Promotion{}, err
+	}
+
+	refuseErr := checkPromotionUsable(promotion, product, time.Now())
+
+	if refuseErr != nil {
+		return dto.Quote{}, domain.Promotion{}, refuseErr
+	}
+
+	couponDiscount := promotion.Value
+	if promotion.DiscountType == domain.PERCENT_DISCOUNT {
+		couponDiscount = quote.EffectivePrice * promotion.Value / 100
+	}
+	if couponDiscount > quote.EffectivePrice {
+		couponDiscount = quote.EffectivePrice
+	}
+
+	quote.CouponCode = promotion.Code
+	quote.CouponDiscount = couponDiscount
+	quote.EffectivePrice = quote.EffectivePrice - couponDiscount
+
+	return quote, promotion, nil
+}
+
+func checkPromotionUsable(promotion domain.Promotion, product domain.Product, now time.Time) error {
+	if now.Before(promotion.ValidFrom) {
+		return &CouponRefusedError{Code: promotion.Code, Reason: "not valid yet"}
+	}
+
+	if !now.Before(promotion.ValidUntil) {
+		return &CouponRefusedError{Code: promotion.Code, Reason: "expired"}
+	}
+
+	if promotion.UsageLimit > 0 && promotion.UsageCount >= promotion.UsageLimit {
+		return &CouponRefusedError{Code: promotion.Code, Reason: "usage limit reached"}
+	}
+
+	if !promotion.AppliesTo(product) {
+		return &CouponRefusedError{Code: promotion.Code, Reason: "not applicable to this product"}
+	}
+
+	return nil
+}
+
+func toPromotion(promotionCreate dto.PromotionCreate) domain.Promotion {
+	return domain.Promotion{
+		Code:         strings.TrimSpace(promotionCreate.Code),
+		ProductIds:   promotionCreate.ProductIds,
+		StoreIds:     promotionCreate.StoreIds,
+		DiscountType: promotionCreate.DiscountType,
+		Value:        promotionCreate.Value,
+		ValidFrom:    promotionCreate.ValidFrom,
+		ValidUntil:   promotionCreate.ValidUntil,
+		UsageLimit:   promotionCreate.UsageLimit,
+	}
+}
+
+// validate also checks that the stores exist, since the database can not
+// check the ids in an array.
+func (promotionService *PromotionService) validate(promotionCreate dto.PromotionCreate) error {
+	validateErr := validatePromotionCreate(promotionCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	for _, storeId := range promotionCreate.StoreIds {
+		if _, err := promotionService.storeRepository.GetById(storeId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePromotionCreate(promotionCreate dto.PromotionCreate) error {
+	if len(strings.TrimSpace(promotionCreate.Code)) == 0 {
+		return errors.New("Code can not be empty")
+	}
+
+	if promotionCreate.DiscountType != domain.PERCENT_DISCOUNT && promotionCreate.DiscountType != domain.AMOUNT_DISCOUNT {
+		return errors.New("Discount type must be percent or amount")
+	}
+
+	if promotionCreate.Value <= 0 {
+		return errors.New("Value must be greater than 0")
+	}
+
+	if promotionCreate.DiscountType == domain.PERCENT_DISCOUNT && promotionCreate.Value > 100 {
+		return errors.New("Percent value can not be greater than 100")
+	}
+
+	if !promotionCreate.ValidUntil.After(promotionCreate.ValidFrom) {
+		return errors.New("Valid until must be after valid from")
+	}
+
+	if promotionCreate.UsageLimit < 0 {
+		return errors.New("Usage limit can not be negative")
+	}
+
+	return nil
+}
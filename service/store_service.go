@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service/dto"
+)
+
+type IStoreService interface {
+	GetAll() []domain.Store
+	GetById(storeId int64) (domain.Store, error)
+	GetAllByNames(storeNames []string) []domain.Store
+	GetProducts(storeId int64) ([]domain.Product, error)
+	Add(storeCreate dto.StoreCreate) error
+	Rename(storeId int64, newName string) error
+	DeleteById(storeId int64) error
+}
+
+type StoreService struct {
+	storeRepository   persistence.IStoreRepository
+	productRepository persistence.IProductRepository
+}
+
+func NewStoreService(storeRepository persistence.IStoreRepository, productRepository persistence.IProductRepository) IStoreService {
+	return &StoreService{
+		storeRepository:   storeRepository,
+		productRepository: productRepository,
+	}
+}
+
+func (storeService *StoreService) GetAll() []domain.Store {
+	return storeService.storeRepository.GetAll()
+}
+
+func (storeService *StoreService) GetById(storeId int64) (domain.Store, error) {
+	return storeService.storeRepository.GetById(storeId)
+}
+
+func (storeService *StoreService) GetAllByNames(storeNames []string) []domain.Store {
+	if len(storeNames) == 0 {
+		return []domain.Store{}
+	}
+
+	return storeService.storeRepository.GetAllByNames(storeNames)
+}
+
+func (storeService *StoreService) GetProducts(storeId int64) ([]domain.Product, error) {
+	_, err := storeService.storeRepository.GetById(storeId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storeService.productRepository.GetAllByStoreId(storeId), nil
+}
+
+func (storeService *StoreService) Add(storeCreate dto.StoreCreate) error {
+	name := domain.NormalizeStoreName(storeCreate.Name)
+
+	validateErr := validateStoreName(name)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	return storeService.storeRepository.Add(domain.Store{Name: name})
+}
+
+func (storeService *StoreService) Rename(storeId int64, newName string) error {
+	name := domain.NormalizeStoreName(newName)
+
+	validateErr := validateStoreName(name)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	return storeService.storeRepository.UpdateName(storeId, name)
+}
+
+func (storeService *StoreService) DeleteById(storeId int64) error {
+	return storeService.storeRepository.DeleteById(storeId)
+}
+
+func validateStoreName(name string) error {
+	if len(name) == 0 {
+		return errors.New("Store name can not be empty")
+	}
+	if len(name) > 255 {
+		return errors.New("Store name can not be longer than 255 characters")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+)
+
+type ITagService interface {
+	GetAllByProductId(productId int64) ([]string, error)
+	AddToProduct(productId int64, tags []string) error
+	RemoveFromProduct(productId int64, tags []string) error
+}
+
+type TagService struct {
+	tagRepository     persistence.ITagRepository
+	productRepository persistence.IProductRepository
+}
+
+func NewTagService(tagRepository persistence.ITagRepository, productRepository persistence.IProductRepository) ITagService {
+	return &TagService{
+		tagRepository:     tagRepository,
+		productRepository: productRepository,
+	}
+}
+
+func (tagService *TagService) GetAllByProductId(productId int64) ([]string, error) {
+	_, err := tagService.productRepository.GetById(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tagService.tagRepository.GetAllByProductId(productId), nil
+}
+
+func (tagService *TagService) AddToProduct(productId int64, tags []string) error {
+	normalizedTags, err := NormalizeTags(tags)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tagService.productRepository.GetById(productId)
+
+	if err != nil {
+		return err
+	}
+
+	return tagService.tagRepository.AddToProduct(productId, normalizedTags)
+}
+
+func (tagService *TagService) RemoveFromProduct(productId int64, tags []string) error {
+	normalizedTags, err := NormalizeTags(tags)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tagService.productRepository.GetById(productId)
+
+	if err != nil {
+		return err
+	}
+
+	return tagService.tagRepository.RemoveFromProduct(productId, normalizedTags)
+}
+
+// NormalizeTags lower-cases and de-duplicates the tags, refusing an empty
+// list or any tag that is not made of letters, digits and dashes.
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalizedTags []string
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		normalizedTag := domain.NormalizeTag(tag)
+
+		if !domain.IsTag(normalizedTag) {
+			return nil, errors.New(fmt.Sprintf("Tag %q may only contain letters, digits and dashes", tag))
+		}
+
+		if !seen[normalizedTag] {
+			seen[normalizedTag] = true
+			normalizedTags = append(normalizedTags, normalizedTag)
+		}
+	}
+
+	if len(normalizedTags) == 0 {
+		return nil, errors.New("At least one tag is required")
+	}
+
+	return normalizedTags, nil
+}
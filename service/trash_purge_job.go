@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"time"
+)
+
+// TrashPurgeJob periodically removes the products that have stayed in the
+// trash for longer than the retention period.
+type TrashPurgeJob struct {
+	productService IProductService
+	retention      time.Duration
+	interval       time.Duration
+}
+
+func NewTrashPurgeJob(productService IProductService, retention time.Duration, interval time.Duration) *TrashPurgeJob {
+	return &TrashPurgeJob{
+		productService: productService,
+		retention:      retention,
+		interval:       interval,
+	}
+}
+
+// Run purges once right away and then on every interval until ctx is done.
+func (trashPurgeJob *TrashPurgeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(trashPurgeJob.interval)
+	defer ticker.Stop()
+
+	for {
+		trashPurgeJob.PurgeOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (trashPurgeJob *TrashPurgeJob) PurgeOnce() int64 {
+	purged, err := trashPurgeJob.productService.PurgeDeleted(trashPurgeJob.retention)
+
+	if err != nil {
+		log.Errorf("Error while purging trash %v", err)
+	}
+
+	return purged
+}
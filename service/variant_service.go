@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service/dto"
+	"strings"
+)
+
+type IVariantService interface {
+	GetAllByProductId(productId int64) ([]domain.Variant, error)
+	GetAllByProductIds(productIds []int64) []domain.Variant
+	GetById(productId int64, variantId int64) (domain.Variant, error)
+	Add(productId int64, variantCreate dto.VariantCreate) error
+	Update(productId int64, variantId int64, variantCreate dto.VariantCreate) error
+	DeleteById(productId int64, variantId int64) error
+}
+
+type VariantService struct {
+	variantRepository persistence.IVariantRepository
+	productRepository persistence.IProductRepository
+}
+
+func NewVariantService(variantRepository persistence.IVariantRepository, productRepository persistence.IProductRepository) IVariantService {
+	return &VariantService{
+		variantRepository: variantRepository,
+		productRepository: productRepository,
+	}
+}
+
+func (variantService *VariantService) GetAllByProductId(productId int64) ([]domain.Variant, error) {
+	_, err := variantService.productRepository.GetById(productId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variantService.variantRepository.GetAllByProductId(productId), nil
+}
+
+func (variantService *VariantService) GetAllByProductIds(productIds []int64) []domain.Variant {
+	if len(productIds) == 0 {
+		return []domain.Variant{}
+	}
+
+	return variantService.variantRepository.GetAllByProductIds(productIds)
+}
+
+func (variantService *VariantService) GetById(productId int64, variantId int64) (domain.Variant, error) {
+	return variantService.variantRepository.GetById(productId, variantId)
+}
+
+func (variantService *VariantService) Add(productId int64, variantCreate dto.VariantCreate) error {
+	validateErr := validateVariantCreate(variantCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	_, err := variantService.productRepository.GetById(productId)
+
+	if err != nil {
+		return err
+	}
+
+	variant := toVariant(variantCreate)
+	variant.ProductId = productId
+
+	return variantService.variantRepository.Add(variant)
+}
+
+func (variantService *VariantService) Update(productId int64, variantId int64, variantCreate dto.VariantCreate) error {
+	validateErr := validateVariantCreate(variantCreate)
+
+	if validateErr != nil {
+		return validateErr
+	}
+
+	variant := toVariant(variantCreate)
+	variant.Id = variantId
+	variant.ProductId = productId
+
+	return variantService.variantRepository.Update(variant)
+}
+
+func (variantService *VariantService) DeleteById(productId int64, variantId int64) error {
+	return variantService.variantRepository.DeleteById(productId, variantId)
+}
+
+func toVariant(variantCreate dto.VariantCreate) domain.Variant {
+	return domain.Variant{
+		Sku:           strings.TrimSpace(variantCreate.Sku),
+		Attributes:    variantCreate.Attributes,
+		PriceOverride: variantCreate.PriceOverride,
+		Barcode:       strings.TrimSpace(variantCreate.Barcode),
+	}
+}
+
+func validateVariantCreate(variantCreate dto.VariantCreate) error {
+	sku := strings.TrimSpace(variantCreate.Sku)
+
+	if len(sku) == 0 {
+		return errors.New("Sku can not be empty")
+	}
+
+	if len(sku) > 64 {
+		return errors.New("Sku can not be longer than 64 characters")
+	}
+
+	if variantCreate.PriceOverride != nil && *variantCreate.PriceOverride <= 0 {
+		return errors.New("Price override must be greater than 0")
+	}
+
+	if barcode := strings.TrimSpace(variantCreate.Barcode); len(barcode) != 0 && !isBarcode(barcode) {
+		return errors.New("Barcode must be 8, 12, 13 or 14 digits")
+	}
+
+	return nil
+}
+
+// isBarcode accepts the EAN-8, UPC-A, EAN-13 and GTIN-14 lengths.
+func isBarcode(barcode string) bool {
+	switch len(barcode) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	for _, digit := range barcode {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+	}
+
+	return true
+}
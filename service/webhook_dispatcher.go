@@ -0,0 +1,206 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	WEBHOOK_ID_HEADER        = "X-Webhook-Id"
+	WEBHOOK_EVENT_HEADER     = "X-Webhook-Event"
+	WEBHOOK_TIMESTAMP_HEADER = "X-Webhook-Timestamp"
+	WEBHOOK_SIGNATURE_HEADER = "X-Webhook-Signature"
+)
+
+// WebhookDispatcherConfig controls how often due deliveries are sent and how
+// failed ones are retried. Lease is how long claimed deliveries are kept from
+// other dispatchers; it has to cover the Timeout of a batch.
+// AllowPrivateAddresses lets deliveries reach loopback and private networks,
+// which is only meant for local receivers in tests.
+type WebhookDispatcherConfig struct {
+	PollInterval          time.Duration
+	BatchSize             int
+	Lease                 time.Duration
+	MaxAttempts           int
+	RetryBackoff          time.Duration
+	MaxRetryBackoff       time.Duration
+	Timeout               time.Duration
+	AllowPrivateAddresses bool
+}
+
+// WebhookDispatcher posts the pending webhook deliveries. Every request is
+// signed with the secret of its subscription: X-Webhook-Signature holds
+// "sha256=" and the hex HMAC-SHA256 of the X-Webhook-Timestamp value, a dot
+// and the body, so receivers can reject both forged and replayed requests.
+// Any status other than 2xx is retried with exponential backoff until
+// MaxAttempts, after which the delivery is dead and only a manual
+// redelivery sends it again.
+type WebhookDispatcher struct {
+	webhookRepository persistence.IWebhookRepository
+	client            *http.Client
+	config            WebhookDispatcherConfig
+}
+
+func NewWebhookDispatcher(webhookRepository persistence.IWebhookRepository, config WebhookDispatcherConfig) *WebhookDispatcher {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+
+	if !config.AllowPrivateAddresses {
+		dialer.Control = publicAddressControl
+	}
+
+	return &WebhookDispatcher{
+		webhookRepository: webhookRepository,
+		client:            &http.Client{Timeout: config.Timeout, Transport: &http.Transport{DialContext: dialer.DialContext}},
+		config:            config,
+	}
+}
+
+// publicAddressControl refuses connections to addresses that are not public.
+// It runs after the host was resolved, so a subscription whose host was
+// pointed at an internal address after it was created is still refused.
+func publicAddressControl(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+		return errors.New(fmt.Sprintf("Webhook address %s is not public", host))
+	}
+
+	return nil
+}
+
+// Run dispatches on every poll interval until ctx is done, and keeps going
+// while batches come back full.
+func (webhookDispatcher *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookDispatcher.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			if webhookDispatcher.DispatchOnce() < webhookDispatcher.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries concurrently and returns
+// its size.
+func (webhookDispatcher *WebhookDispatcher) DispatchOnce() int {
+	processed, err := webhookDispatcher.webhookRepository.ProcessDueDeliveries(webhookDispatcher.config.BatchSize, webhookDispatcher.config.Lease,
+		webhookDispatcher.send)
+
+	if err != nil {
+		log.Errorf("Error while dispatching webhooks %v", err)
+	}
+
+	return processed
+}
+
+func (webhookDispatcher *WebhookDispatcher) send(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome {
+	outcomes := make([]domain.WebhookDeliveryOutcome, len(deliveries))
+	var waitGroup sync.WaitGroup
+
+	for i := range deliveries {
+		waitGroup.Add(1)
+
+		go func(i int) {
+			defer waitGroup.Done()
+			outcomes[i] = webhookDispatcher.sendDelivery(deliveries[i])
+		}(i)
+	}
+
+	waitGroup.Wait()
+
+	return outcomes
+}
+
+func (webhookDispatcher *WebhookDispatcher) sendDelivery(delivery domain.WebhookDelivery) domain.WebhookDeliveryOutcome {
+	outcome := domain.WebhookDeliveryOutcome{Id: delivery.Id, Status: domain.WEBHOOK_SUCCEEDED}
+
+	statusCode, err := webhookDispatcher.post(delivery)
+	outcome.StatusCode = statusCode
+
+	if err == nil {
+		return outcome
+	}
+
+	attempts := delivery.Attempts + 1
+	outcome.Error = err.Error()
+
+	if attempts >= webhookDispatcher.config.MaxAttempts {
+		log.Errorf("Webhook delivery %d to %s is dead after %d attempts %v", delivery.Id, delivery.Url, attempts, err)
+		outcome.Status = domain.WEBHOOK_DEAD
+		return outcome
+	}
+
+	outcome.Status = domain.WEBHOOK_FAILED
+	outcome.NextAttemptAt = time.Now().Add(exponentialBackoff(webhookDispatcher.config.RetryBackoff, webhookDispatcher.config.MaxRetryBackoff, attempts))
+
+	return outcome
+}
+
+func (webhookDispatcher *WebhookDispatcher) post(delivery domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WEBHOOK_ID_HEADER, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(WEBHOOK_EVENT_HEADER, delivery.EventType)
+	req.Header.Set(WEBHOOK_TIMESTAMP_HEADER, timestamp)
+	req.Header.Set(WEBHOOK_SIGNATURE_HEADER, "sha256="+SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	res, err := webhookDispatcher.client.Do(req)
+
+	if err != nil {
+		return 0, err
+	}
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, errors.New(fmt.Sprintf("Webhook answered with status %d", res.StatusCode))
+	}
+
+	return res.StatusCode, nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of timestamp, a dot and
+// payload under secret.
+func SignWebhookPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service/dto"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type IWebhookService interface {
+	GetAll() []domain.WebhookSubscription
+	GetById(subscriptionId int64) (domain.WebhookSubscription, error)
+	Add(webhookCreate dto.WebhookCreate) (domain.WebhookSubscription, error)
+	DeleteById(subscriptionId int64) error
+	GetDeliveries(subscriptionId int64, status string) ([]domain.WebhookDelivery, error)
+	Redeliver(subscriptionId int64, deliveryId int64) error
+}
+
+const (
+	// WEBHOOK_DELIVERY_LOG_SIZE is how many of the latest deliveries the
+	// delivery log returns.
+	WEBHOOK_DELIVERY_LOG_SIZE = 100
+	MIN_WEBHOOK_SECRET_LENGTH = 16
+	// WEBHOOK_LOOKUP_TIMEOUT bounds the lookup of a webhook host when the
+	// subscription is created.
+	WEBHOOK_LOOKUP_TIMEOUT = 5 * time.Second
+)
+
+var ErrWebhookAddressNotPublic = errors.New("Url must not point to a loopback, link-local or private address")
+
+var webhookEventTypes = map[string]bool{
+	domain.PRODUCT_CREATED:       true,
+	domain.PRODUCT_UPDATED:       true,
+	domain.PRODUCT_PRICE_CHANGED: true,
+	domain.PRODUCT_DELETED:       true,
+}
+
+var ErrInvalidDeliveryStatus = errors.New("Status must be one of pending, succeeded, failed or dead")
+
+var webhookStatuses = map[string]bool{
+	domain.WEBHOOK_PENDING:   true,
+	domain.WEBHOOK_SUCCEEDED: true,
+	domain.WEBHOOK_FAILED:    true,
+	domain.WEBHOOK_DEAD:      true,
+}
+
+type WebhookService struct {
+	webhookRepository persistence.IWebhookRepository
+}
+
+func NewWebhookService(webhookRepository persistence.IWebhookRepository) IWebhookService {
+	return &WebhookService{
+		webhookRepository: webhookRepository,
+	}
+}
+
+func (webhookService *WebhookService) GetAll() []domain.WebhookSubscription {
+	return webhookService.webhookRepository.GetAll()
+}
+
+func (webhookService *WebhookService) GetById(subscriptionId int64) (domain.WebhookSubscription, error) {
+	return webhookService.webhookRepository.GetById(subscriptionId)
+}
+
+// Add generates a secret when none is given. The secret is only returned
+// here, so the caller has to keep it to verify signatures.
+func (webhookService *WebhookService) Add(webhookCreate dto.WebhookCreate) (domain.WebhookSubscription, error) {
+	if err := validateWebhookCreate(webhookCreate); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	secret := webhookCreate.Secret
+
+	if len(secret) == 0 {
+		generated := make([]byte, 32)
+
+		if _, err := rand.Read(generated); err != nil {
+			return domain.WebhookSubscription{}, errors.New("Error while generating webhook secret")
+		}
+
+		secret = hex.EncodeToString(generated)
+	}
+
+	return webhookService.webhookRepository.Add(domain.WebhookSubscription{
+		Url:        webhookCreate.Url,
+		EventTypes: webhookCreate.EventTypes,
+		StoreId:    webhookCreate.StoreId,
+		Secret:     secret,
+	})
+}
+
+func (webhookService *WebhookService) DeleteById(subscriptionId int64) error {
+	return webhookService.webhookRepository.DeleteById(subscriptionId)
+}
+
+func (webhookService *WebhookService) GetDeliveries(subscriptionId int64, status string) ([]domain.WebhookDelivery, error) {
+	if len(status) != 0 && !webhookStatuses[status] {
+		return nil, ErrInvalidDeliveryStatus
+	}
+
+	if _, err := webhookService.webhookRepository.GetById(subscriptionId); err != nil {
+		return nil, err
+	}
+
+	return webhookService.webhookRepository.GetDeliveries(subscriptionId, status, WEBHOOK_DELIVERY_LOG_SIZE), nil
+}
+
+func (webhookService *WebhookService) Redeliver(subscriptionId int64, deliveryId int64) error {
+	return webhookService.webhookRepository.Redeliver(subscriptionId, deliveryId)
+}
+
+func validateWebhookCreate(webhookCreate dto.WebhookCreate) error {
+	webhookUrl, err := url.Parse(webhookCreate.Url)
+
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || len(webhookUrl.Host) == 0 {
+		return errors.New("Url must be an absolute http or https url")
+	}
+
+	if err := validateWebhookHost(webhookUrl.Hostname()); err != nil {
+		return err
+	}
+
+	if len(webhookCreate.EventTypes) == 0 {
+		return errors.New("Event types can not be empty")
+	}
+
+	for _, eventType := range webhookCreate.EventTypes {
+		if !webhookEventTypes[eventType] {
+			return errors.New(fmt.Sprintf("Unknown event type %s", eventType))
+		}
+	}
+
+	if webhookCreate.StoreId < 0 {
+		return errors.New("Store id can not be negative")
+	}
+
+	if len(webhookCreate.Secret) != 0 && len(webhookCreate.Secret) < MIN_WEBHOOK_SECRET_LENGTH {
+		return errors.New(fmt.Sprintf("Secret must be at least %d characters", MIN_WEBHOOK_SECRET_LENGTH))
+	}
+
+	return nil
+}
+
+// validateWebhookHost keeps subscriptions from making the dispatcher call
+// into our own network. A host that does not resolve yet is accepted, since
+// the dispatcher checks every address it connects to anyway.
+func validateWebhookHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookAddressNotPublic
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicAddress(ip) {
+			return ErrWebhookAddressNotPublic
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), WEBHOOK_LOOKUP_TIMEOUT)
+	defer cancel()
+
+	addresses, _ := net.DefaultResolver.LookupIPAddr(ctx, host)
+
+	for _, address := range addresses {
+		if !isPublicAddress(address.IP) {
+			return ErrWebhookAddressNotPublic
+		}
+	}
+
+	return nil
+}
+
+// isPublicAddress reports whether the address is outside of loopback,
+// link-local, private and unspecified ranges. The link-local range holds
+// the metadata endpoint of cloud providers at 169.254.169.254.
+func isPublicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsPrivate() && !ip.IsUnspecified()
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"encoding/json"
+	"example.com/product-api/controller"
+	"example.com/product-api/domain"
+	"example.com/product-api/graph"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// countingStoreRepository counts the batched store lookups.
+type countingStoreRepository struct {
+	persistence.IStoreRepository
+	getAllByNamesCalls int32
+}
+
+func (countingStoreRepository *countingStoreRepository) GetAllByNames(storeNames []string) []domain.Store {
+	atomic.AddInt32(&countingStoreRepository.getAllByNamesCalls, 1)
+	return countingStoreRepository.IStoreRepository.GetAllByNames(storeNames)
+}
+
+func newGraphqlServerForTest() (*echo.Echo, *countingStoreRepository) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH", Attributes: map[string]interface{}{"wattage": float64(1500)}},
+		{Id: 2, Name: "Ütü", Price: 1500.0, Discount: 10.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 3, Name: "Çamaşır Makinesi", Price: 10000.0, Discount: 15.0, StoreId: 2, Store: "DEF TECH"},
+		{Id: 4, Name: "Lambader", Price: 2000.0, Discount: 0.0, StoreId: 2, Store: "DEF TECH"},
+	}
+	stores := []domain.Store{{Id: 1, Name: "ABC TECH"}, {Id: 2, Name: "DEF TECH"}}
+
+	productRepository := fakes.NewFakeProductRepository(products)
+	storeRepository := &countingStoreRepository{IStoreRepository: fakes.NewFakeStoreRepository(stores)}
+
+	productService := service.NewProductService(productRepository)
+	storeService := service.NewStoreService(storeRepository, productRepository)
+
+	e := echo.New()
+	controller.NewGraphqlController(graph.NewExecutor(productService, storeService)).RegisterRoutes(e)
+
+	return e, storeRepository
+}
+
+type graphqlResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func executeGraphql(t *testing.T, e *echo.Echo, query string, variables map[string]interface{}) graphqlResult {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	rec := serve(e, http.MethodPost, "/graphql", map[string]string{echo.HeaderContentType: echo.MIMEApplicationJSON}, body)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result graphqlResult
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &result))
+
+	return result
+}
+
+func Test_ShouldPageProductsAndBatchStoreLookups(t *testing.T) {
+	e, storeRepository := newGraphqlServerForTest()
+
+	result := executeGraphql(t, e, `query { products(first: 3) { items { id name store { id name } } endCursor hasNextPage } }`, nil)
+
+	assert.Empty(t, result.Errors)
+
+	var products struct {
+		Items []struct {
+			Id    string
+			Name  string
+			Store struct {
+				Id   string
+				Name string
+			}
+		}
+		EndCursor   string
+		HasNextPage bool
+	}
+	assert.Nil(t, json.Unmarshal(result.Data["products"], &products))
+
+	assert.Equal(t, 3, len(products.Items))
+	assert.Equal(t, "ABC TECH", products.Items[0].Store.Name)
+	assert.Equal(t, "2", products.Items[2].Store.Id)
+	assert.Equal(t, "3", products.EndCursor)
+	assert.True(t, products.HasNextPage)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&storeRepository.getAllByNamesCalls))
+
+	result = executeGraphql(t, e, `query($after: ID) { products(first: 3, after: $after) { items { id } hasNextPage } }`,
+		map[string]interface{}{"after": products.EndCursor})
+
+	assert.JSONEq(t, `{"items":[{"id":"4"}],"hasNextPage":false}`, string(result.Data["products"]))
+}
+
+func Test_ShouldFilterProductsByStore(t *testing.T) {
+	e, _ := newGraphqlServerForTest()
+
+	result := executeGraphql(t, e, `{ products(filter: {store: "DEF TECH"}) { items { name } } }`, nil)
+
+	assert.JSONEq(t, `{"items":[{"name":"Çamaşır Makinesi"},{"name":"Lambader"}]}`, string(result.Data["products"]))
+}
+
+func Test_ShouldGetProductWithAttributes(t *testing.T) {
+	e, _ := newGraphqlServerForTest()
+
+	result := executeGraphql(t, e, `{ product(id: "1") { name price attributes } }`, nil)
+
+	assert.JSONEq(t, `{"name":"AirFryer","price":3000,"attributes":{"wattage":1500}}`, string(result.Data["product"]))
+}
+
+func Test_WhenProductDoesNotExist_ShouldReturnGraphqlError(t *testing.T) {
+	e, _ := newGraphqlServerForTest()
+
+	result := executeGraphql(t, e, `{ product(id: "99") { name } }`, nil)
+
+	assert.Equal(t, 1, len(result.Errors))
+	assert.Equal(t, "Product not found with id 99", result.Errors[0].Message)
+}
+
+func Test_ShouldCreateUpdateAndDeleteProducts(t *testing.T) {
+	e, _ := newGraphqlServerForTest()
+
+	result := executeGraphql(t, e, `mutation { createProduct(input: {name: "Kettle", price: 500, storeId: "1", attributes: {color: "red"}}) { id name store { name } } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.JSONEq(t, `{"id":"5","name":"Kettle","store":{"name":"ABC TECH"}}`, string(result.Data["createProduct"]))
+
+	result = executeGraphql(t, e, `mutation { updatePrice(id: "5", price: 450) { name price } }`, nil)
+	assert.JSONEq(t, `{"name":"Kettle","price":450}`, string(result.Data["updatePrice"]))
+
+	result = executeGraphql(t, e, `mutation { deleteProduct(id: "5") }`, nil)
+	assert.JSONEq(t, `true`, string(result.Data["deleteProduct"]))
+
+	result = executeGraphql(t, e, `mutation { updatePrice(id: "1", price: 0) { price } }`, nil)
+	assert.Equal(t, "Price must be greater than 0", result.Errors[0].Message)
+}
+
+func Test_WhenQueryIsEmpty_ShouldReturnBadRequest(t *testing.T) {
+	e, _ := newGraphqlServerForTest()
+
+	rec := serve(e, http.MethodPost, "/graphql", map[string]string{echo.HeaderContentType: echo.MIMEApplicationJSON}, []byte(`{"query":" "}`))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"example.com/product-api/controller"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newDuplicateProductServerForTest() *echo.Echo {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Air Fryer", Price: 3100.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 3, Name: "Iron", Price: 1500.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	productService := service.NewProductService(fakes.NewFakeProductRepository(products))
+	variantService := service.NewVariantService(fakes.NewFakeVariantRepository(nil), fakes.NewFakeProductRepository(products))
+	idempotencyService := service.NewIdempotencyService(fakes.NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	e := echo.New()
+	controller.NewProductController(productService, variantService, idempotencyService, nil).RegisterRoutes(e)
+
+	return e
+}
+
+func Test_WhenProductNameIsTaken_ShouldRespondConflict(t *testing.T) {
+	e := newDuplicateProductServerForTest()
+
+	rec := serve(e, http.MethodPost, "/api/products", jsonHeaders, []byte(`{"name":"IRON","price":1500,"discount":5,"storeId":1}`))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Store already has a product with this name")
+}
+
+func Test_ShouldReportDuplicateProducts(t *testing.T) {
+	e := newDuplicateProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/duplicates?store=abc%20tech&threshold=0.3", nil, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"storeId":1,"store":"ABC TECH","productId":1,"name":"AirFryer","duplicateId":2,"duplicateName":"Air Fryer","similarity":0.5833333}]`, rec.Body.String())
+
+	rec = serve(e, http.MethodGet, "/api/products/duplicates?threshold=high", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(e, http.MethodGet, "/api/products/duplicates?threshold=2", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"example.com/product-api/controller"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newProductEventServerForTest(t *testing.T) (*httptest.Server, service.IProductEventBus) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+
+	e := echo.New()
+	controller.NewProductEventController(productEventBus).RegisterRoutes(e)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	return server, productEventBus
+}
+
+// openEventStream connects and returns a reader positioned after the retry
+// line, once the server has subscribed.
+func openEventStream(t *testing.T, ctx context.Context, url string, lastEventId string) *bufio.Reader {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if len(lastEventId) != 0 {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+
+	reader := bufio.NewReader(res.Body)
+	retry, _ := reader.ReadString('\n')
+	assert.Equal(t, "retry: 3000\n", retry)
+	reader.ReadString('\n')
+
+	return reader
+}
+
+// readEvent returns the id, event and data lines of the next event.
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) == 0 {
+			return lines
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+func Test_ShouldStreamMatchingProductEvents(t *testing.T) {
+	server, productEventBus := newProductEventServerForTest(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reader := openEventStream(t, ctx, server.URL+"/api/products/events?store=abc%20tech", "")
+
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7, StoreId: 2, Store: "DEF TECH"}})
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, PreviousPrice: 3000.0,
+		Product: domain.Product{Id: 1, Name: "AirFryer", Price: 2800.0, StoreId: 1, Store: "ABC TECH"}})
+
+	lines := readEvent(t, reader)
+
+	assert.Equal(t, "id: 2", lines[0])
+	assert.Equal(t, "event: price_changed", lines[1])
+	assert.Contains(t, lines[2], `"productId":1`)
+	assert.Contains(t, lines[2], `"price":2800`)
+	assert.Contains(t, lines[2], `"previousPrice":3000`)
+}
+
+func Test_ShouldResumeFromLastEventId(t *testing.T) {
+	server, productEventBus := newProductEventServerForTest(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := int64(1); i <= 3; i++ {
+		productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: i, Store: "ABC TECH"}})
+	}
+
+	reader := openEventStream(t, ctx, server.URL+"/api/products/events?product=2,3", "1")
+
+	assert.Equal(t, "id: 2", readEvent(t, reader)[0])
+	assert.Equal(t, "id: 3", readEvent(t, reader)[0])
+}
+
+func Test_WhenProductFilterIsInvalid_ShouldReturnBadRequest(t *testing.T) {
+	server, _ := newProductEventServerForTest(t)
+
+	res, err := http.Get(server.URL + "/api/products/events?product=abc")
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
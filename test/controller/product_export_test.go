@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"example.com/product-api/controller"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// failingExportRepository fails to open the export query.
+type failingExportRepository struct {
+	persistence.IProductRepository
+}
+
+func (failingExportRepository *failingExportRepository) StreamAllByFilter(filter domain.ProductFilter, consume func(domain.Product) error) error {
+	return errors.New("connection refused")
+}
+
+func newExportServerForTest(productRepository persistence.IProductRepository) *echo.Echo {
+	productService := service.NewProductService(productRepository)
+	variantService := service.NewVariantService(fakes.NewFakeVariantRepository(nil), productRepository)
+	idempotencyService := service.NewIdempotencyService(fakes.NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	e := echo.New()
+	controller.NewProductController(productService, variantService, idempotencyService, nil).RegisterRoutes(e)
+
+	return e
+}
+
+func Test_ShouldExportProductsAsAttachment(t *testing.T) {
+	e := newExportServerForTest(fakes.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+	}))
+
+	rec := serve(e, http.MethodGet, "/api/products/export?format=csv", nil, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="products.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.Equal(t, "id,name,price,discount,storeId,store,attributes\n1,AirFryer,3000,22,1,ABC TECH,\n", rec.Body.String())
+}
+
+func Test_WhenNothingMatches_ShouldExportEmptyAttachment(t *testing.T) {
+	e := newExportServerForTest(fakes.NewFakeProductRepository(nil))
+
+	rec := serve(e, http.MethodGet, "/api/products/export?format=csv", nil, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "id,name,price,discount,storeId,store,attributes\n", rec.Body.String())
+}
+
+func Test_WhenExportQueryFails_ShouldRespondWithInternalServerError(t *testing.T) {
+	e := newExportServerForTest(&failingExportRepository{IProductRepository: fakes.NewFakeProductRepository(nil)})
+
+	rec := serve(e, http.MethodGet, "/api/products/export?format=csv", nil, nil)
+
+	var errorResponse response.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &errorResponse)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.Equal(t, "Error while exporting products", errorResponse.ErrorDescription)
+}
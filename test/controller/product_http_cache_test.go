@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"example.com/product-api/controller"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var productUpdatedAt = time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+func newCachingProductServerForTest() (*echo.Echo, service.IProductService) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH", Version: 3, UpdatedAt: productUpdatedAt},
+		{Id: 2, Name: "Iron", Price: 1500.0, StoreId: 1, Store: "ABC TECH", Version: 1, UpdatedAt: productUpdatedAt.Add(-time.Hour)},
+	}
+
+	productService := service.NewProductService(fakes.NewFakeProductRepository(products))
+	variantService := service.NewVariantService(fakes.NewFakeVariantRepository(nil), fakes.NewFakeProductRepository(products))
+
+	idempotencyService := service.NewIdempotencyService(fakes.NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	e := echo.New()
+	controller.NewProductController(productService, variantService, idempotencyService, map[string]string{
+		"/api/products":     "public, max-age=30",
+		"/api/products/:id": "public, max-age=60",
+	}).RegisterRoutes(e)
+
+	return e, productService
+}
+
+func Test_ShouldSendCachingHeadersWithProduct(t *testing.T) {
+	e, _ := newCachingProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1", nil, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept, Authorization", rec.Header().Get("Vary"))
+	assert.Equal(t, "Fri, 01 Mar 2024 10:30:00 GMT", rec.Header().Get("Last-Modified"))
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, rec.Header().Get("ETag"))
+
+	rec = serve(e, http.MethodGet, "/api/products", nil, nil)
+
+	assert.Equal(t, "public, max-age=30", rec.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	assert.Empty(t, rec.Header().Get("Last-Modified"))
+}
+
+func Test_WhenEtagMatches_ShouldRespondNotModified(t *testing.T) {
+	e, productService := newCachingProductServerForTest()
+
+	etag := serve(e, http.MethodGet, "/api/products/1", nil, nil).Header().Get("ETag")
+
+	rec := serve(e, http.MethodGet, "/api/products/1", map[string]string{"If-None-Match": `"other", ` + etag}, nil)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+
+	// Another format is another representation.
+	rec = serve(e, http.MethodGet, "/api/products/1", map[string]string{"If-None-Match": etag, "Accept": "application/xml"}, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	listEtag := serve(e, http.MethodGet, "/api/products", nil, nil).Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, serve(e, http.MethodGet, "/api/products", map[string]string{"If-None-Match": listEtag}, nil).Code)
+
+	productService.DeleteById(2)
+
+	rec = serve(e, http.MethodGet, "/api/products", map[string]string{"If-None-Match": listEtag}, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, listEtag, rec.Header().Get("ETag"))
+}
+
+func Test_WhenNotModifiedSince_ShouldRespondNotModified(t *testing.T) {
+	e, _ := newCachingProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1", map[string]string{"If-Modified-Since": "Fri, 01 Mar 2024 10:30:00 GMT"}, nil)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = serve(e, http.MethodGet, "/api/products/1", map[string]string{"If-Modified-Since": "Fri, 01 Mar 2024 10:29:59 GMT"}, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// If-None-Match wins over If-Modified-Since.
+	rec = serve(e, http.MethodGet, "/api/products/1", map[string]string{
+		"If-Modified-Since": "Fri, 01 Mar 2024 10:30:00 GMT",
+		"If-None-Match":     `W/"stale"`,
+	}, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func Test_WhenRequestIsAuthorized_ShouldOnlyAllowPrivateCaching(t *testing.T) {
+	e, _ := newCachingProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1", map[string]string{"Authorization": "Bearer token"}, nil)
+
+	assert.Equal(t, "private, max-age=60", rec.Header().Get("Cache-Control"))
+}
+
+func Test_WhenProductIsNotFound_ShouldNotAllowCaching(t *testing.T) {
+	e, _ := newCachingProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/9", nil, nil)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Cache-Control"))
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, "Accept, Authorization", rec.Header().Get("Vary"))
+}
+
+func Test_WhenVariantsAreExpanded_ShouldSendNoValidators(t *testing.T) {
+	e, _ := newCachingProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1?expand=variants", nil, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.Empty(t, rec.Header().Get("Last-Modified"))
+}
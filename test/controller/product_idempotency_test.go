@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"example.com/product-api/controller"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingProductRepository holds Add until release is closed, when it is
+// set, so that a request can be kept in flight.
+type blockingProductRepository struct {
+	persistence.IProductRepository
+	adding  chan struct{}
+	release chan struct{}
+	added   atomic.Int64
+}
+
+func (blockingRepository *blockingProductRepository) Add(product domain.Product) (domain.Product, error) {
+	if blockingRepository.release != nil {
+		blockingRepository.adding <- struct{}{}
+		<-blockingRepository.release
+	}
+
+	blockingRepository.added.Add(1)
+
+	return blockingRepository.IProductRepository.Add(product)
+}
+
+func newIdempotentProductServerForTest() (*echo.Echo, *blockingProductRepository) {
+	productRepository := &blockingProductRepository{IProductRepository: fakes.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH"},
+	})}
+
+	productService := service.NewProductService(productRepository)
+	variantService := service.NewVariantService(fakes.NewFakeVariantRepository(nil), productRepository)
+	idempotencyService := service.NewIdempotencyService(fakes.NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	e := echo.New()
+	controller.NewProductController(productService, variantService, idempotencyService, nil).RegisterRoutes(e)
+
+	return e, productRepository
+}
+
+func idempotencyHeaders(key string) map[string]string {
+	return map[string]string{echo.HeaderContentType: echo.MIMEApplicationJSON, "Idempotency-Key": key}
+}
+
+const kettleRequest = `{"name":"Kettle","price":400,"discount":5,"storeId":1}`
+
+func Test_WhenRequestIsRetried_ShouldReplayFirstResponse(t *testing.T) {
+	e, productRepository := newIdempotentProductServerForTest()
+
+	first := serve(e, http.MethodPost, "/api/products", idempotencyHeaders("retry-1"), []byte(kettleRequest))
+	retry := serve(e, http.MethodPost, "/api/products", idempotencyHeaders("retry-1"), []byte(kettleRequest))
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Header().Get(echo.HeaderContentType), retry.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, int64(1), productRepository.added.Load())
+
+	// Another key, or none, is another request.
+	serve(e, http.MethodPost, "/api/products", idempotencyHeaders("retry-2"), []byte(kettleRequest))
+	serve(e, http.MethodPost, "/api/products", jsonHeaders, []byte(kettleRequest))
+	assert.Equal(t, int64(3), productRepository.added.Load())
+}
+
+func Test_WhenKeyIsReusedForOtherPayload_ShouldRespondUnprocessable(t *testing.T) {
+	e, productRepository := newIdempotentProductServerForTest()
+
+	serve(e, http.MethodPost, "/api/products", idempotencyHeaders("reused"), []byte(kettleRequest))
+	rec := serve(e, http.MethodPost, "/api/products", idempotencyHeaders("reused"), []byte(`{"name":"Toaster","price":300,"storeId":1}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Idempotency key was already used for a different request")
+	assert.Equal(t, int64(1), productRepository.added.Load())
+
+	// Keys are scoped to the route they were sent to.
+	rec = serve(e, http.MethodPost, "/api/products/bulk", idempotencyHeaders("reused"), []byte(`{"deletes":[1]}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func Test_WhenKeyIsInFlight_ShouldRespondConflict(t *testing.T) {
+	e, productRepository := newIdempotentProductServerForTest()
+	productRepository.adding = make(chan struct{})
+	productRepository.release = make(chan struct{})
+
+	done := make(chan int)
+
+	go func() {
+		done <- serve(e, http.MethodPost, "/api/products", idempotencyHeaders("in-flight"), []byte(kettleRequest)).Code
+	}()
+
+	<-productRepository.adding
+
+	rec := serve(e, http.MethodPost, "/api/products", idempotencyHeaders("in-flight"), []byte(kettleRequest))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	close(productRepository.release)
+	assert.Equal(t, http.StatusCreated, <-done)
+
+	rec = serve(e, http.MethodPost, "/api/products", idempotencyHeaders("in-flight"), []byte(kettleRequest))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int64(1), productRepository.added.Load())
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"bytes"
+	"example.com/product-api/api/productpb"
+	"example.com/product-api/controller"
+	"example.com/product-api/controller/codec"
+	"example.com/product-api/controller/request"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newProductServerForTest() (*echo.Echo, service.IProductService) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH", Attributes: map[string]interface{}{"wattage": float64(1500)}},
+	}
+
+	productService := service.NewProductService(fakes.NewFakeProductRepository(products))
+	variantService := service.NewVariantService(fakes.NewFakeVariantRepository(nil), fakes.NewFakeProductRepository(products))
+
+	idempotencyService := service.NewIdempotencyService(fakes.NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	e := echo.New()
+	controller.NewProductController(productService, variantService, idempotencyService, nil).RegisterRoutes(e)
+
+	return e, productService
+}
+
+func serve(e *echo.Echo, method string, target string, headers map[string]string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func Test_WhenAcceptIsXml_ShouldRespondWithXml(t *testing.T) {
+	e, _ := newProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1", map[string]string{"Accept": "application/xml"}, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<name>AirFryer</name>")
+	assert.Contains(t, rec.Body.String(), `<attributes><attribute key="wattage">1500</attribute></attributes>`)
+
+	rec = serve(e, http.MethodGet, "/api/products", map[string]string{"Accept": "text/html;q=0.9, text/xml"}, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<list><item><name>AirFryer</name>")
+}
+
+func Test_WhenAcceptIsMsgpack_ShouldRespondWithMsgpack(t *testing.T) {
+	e, _ := newProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1", map[string]string{"Accept": "application/x-msgpack"}, nil)
+
+	var decoded map[string]interface{}
+	msgpack.Unmarshal(rec.Body.Bytes(), &decoded)
+	assert.Equal(t, "application/msgpack", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "AirFryer", decoded["name"])
+	assert.Equal(t, "ABC TECH", decoded["store"])
+}
+
+func Test_WhenAcceptIsProtobuf_ShouldRespondWithProductMessage(t *testing.T) {
+	e, _ := newProductServerForTest()
+
+	rec := serve(e, http.MethodGet, "/api/products/1", map[string]string{"Accept": "application/x-protobuf"}, nil)
+
+	var product productpb.ProductResponse
+	proto.Unmarshal(rec.Body.Bytes(), &product)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "AirFryer", product.Name)
+	assert.Equal(t, map[string]interface{}{"wattage": float64(1500)}, product.Attributes.AsMap())
+}
+
+func Test_WhenNoFormatIsAcceptable_ShouldRespond406(t *testing.T) {
+	e, _ := newProductServerForTest()
+
+	assert.Equal(t, http.StatusNotAcceptable, serve(e, http.MethodGet, "/api/products/1", map[string]string{"Accept": "text/html"}, nil).Code)
+	assert.Equal(t, http.StatusNotAcceptable, serve(e, http.MethodGet, "/api/products/search?q=air", map[string]string{"Accept": "application/x-protobuf"}, nil).Code)
+	assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/products/1", map[string]string{"Accept": "text/html, */*;q=0.1"}, nil).Code)
+}
+
+func Test_WhenBodyIsProtobuf_ShouldAddProduct(t *testing.T) {
+	e, productService := newProductServerForTest()
+	body, _ := request.AddProductRequest{Name: "Kettle", Price: 800.0, StoreId: 1, Attributes: codec.Attributes{"color": "red"}}.MarshalProto()
+
+	rec := serve(e, http.MethodPost, "/api/products", map[string]string{"Content-Type": "application/x-protobuf", "Accept": "application/xml"}, body)
+
+	products := productService.GetAll()
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<name>Kettle</name>")
+	assert.Equal(t, "Kettle", products[1].Name)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, products[1].Attributes)
+}
+
+func Test_WhenBodyIsXml_ShouldAddProduct(t *testing.T) {
+	e, productService := newProductServerForTest()
+	body := []byte(`<product><name>Lamp</name><price>250</price><store>Decoration Palace</store>` +
+		`<attributes><attribute key="watts">60</attribute></attributes></product>`)
+
+	rec := serve(e, http.MethodPost, "/api/products", map[string]string{"Content-Type": "application/xml"}, body)
+
+	products := productService.GetAll()
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "Lamp", products[1].Name)
+	assert.Equal(t, map[string]interface{}{"watts": float64(60)}, products[1].Attributes)
+}
+
+func Test_WhenBodyTypeIsUnsupported_ShouldRespond415(t *testing.T) {
+	e, _ := newProductServerForTest()
+
+	rec := serve(e, http.MethodPost, "/api/products", map[string]string{"Content-Type": "text/plain"}, []byte("Kettle"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = serve(e, http.MethodPost, "/api/products/bulk", map[string]string{"Content-Type": "application/x-protobuf"}, []byte{})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"example.com/product-api/controller"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newSyncProductServerForTest() (*echo.Echo, service.IProductService) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH", ExternalRef: "ERP/1"},
+		{Id: 2, Name: "Iron", Price: 1500.0, StoreId: 1, Store: "ABC TECH", ExternalRef: "ERP/2"},
+	}
+
+	productService := service.NewProductService(fakes.NewFakeProductRepository(products))
+	variantService := service.NewVariantService(fakes.NewFakeVariantRepository(nil), fakes.NewFakeProductRepository(products))
+	idempotencyService := service.NewIdempotencyService(fakes.NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	e := echo.New()
+	controller.NewProductController(productService, variantService, idempotencyService, nil).RegisterRoutes(e)
+
+	return e, productService
+}
+
+func Test_WhenUpsertingByRef_ShouldRespondCreatedThenOk(t *testing.T) {
+	e, _ := newSyncProductServerForTest()
+
+	rec := serve(e, http.MethodPut, "/api/products/by-ref/ERP%2F3", jsonHeaders, []byte(kettleRequest))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"name":"Kettle","price":400,"discount":5,"storeId":1,"store":"ABC TECH","externalRef":"ERP/3","outcome":"created"}`, rec.Body.String())
+
+	rec = serve(e, http.MethodPut, "/api/products/by-ref/ERP%2F3", jsonHeaders, []byte(`{"name":"Kettle","price":450,"discount":5,"storeId":1}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"outcome":"updated"`)
+
+	rec = serve(e, http.MethodPut, "/api/products/by-ref/ERP-4", jsonHeaders, []byte(`{"name":"AirFryer","price":3000,"storeId":1}`))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve(e, http.MethodPut, "/api/products/by-ref/ERP-4", jsonHeaders, []byte(`{"name":"Kettle","price":400,"discount":90,"storeId":1}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func Test_WhenSyncingFullFeed_ShouldReportOutcomes(t *testing.T) {
+	e, productService := newSyncProductServerForTest()
+
+	rec := serve(e, http.MethodPut, "/api/products/by-ref", jsonHeaders, []byte(`{"mode":"full","products":[
+		{"externalRef":"ERP/1","name":"AirFryer","price":3000,"storeId":1},
+		{"externalRef":"ERP/3","name":"Kettle","price":400,"store":"ABC TECH"}]}`))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"created":1,"updated":0,"unchanged":1,"discontinued":1,
+		"products":[{"externalRef":"ERP/1","outcome":"unchanged"},{"externalRef":"ERP/3","outcome":"created"}],
+		"discontinuedRefs":["ERP/2"]}`, rec.Body.String())
+
+	iron, _ := productService.GetById(2)
+	assert.True(t, iron.Discontinued)
+	assert.Contains(t, serve(e, http.MethodGet, "/api/products/2", nil, nil).Body.String(), `"discontinued":true`)
+
+	rec = serve(e, http.MethodPut, "/api/products/by-ref", jsonHeaders, []byte(`{"mode":"everything","products":[]}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(e, http.MethodPut, "/api/products/by-ref", jsonHeaders, []byte(`{"mode":"full","products":[]}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"encoding/json"
+	"example.com/product-api/controller"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var jsonHeaders = map[string]string{echo.HeaderContentType: echo.MIMEApplicationJSON}
+
+func newWebhookServerForTest() (*echo.Echo, *fakes.FakeWebhookRepository) {
+	webhookRepository := fakes.NewFakeWebhookRepository()
+
+	e := echo.New()
+	controller.NewWebhookController(service.NewWebhookService(webhookRepository)).RegisterRoutes(e)
+
+	return e, webhookRepository
+}
+
+func Test_ShouldShowWebhookSecretOnlyOnCreation(t *testing.T) {
+	e, _ := newWebhookServerForTest()
+
+	rec := serve(e, http.MethodPost, "/api/webhooks", jsonHeaders,
+		[]byte(`{"url":"https://partner.example.com/hooks","eventTypes":["price_changed"],"storeId":1,"secret":"0123456789abcdef"}`))
+
+	var created response.WebhookCreatedResponse
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "0123456789abcdef", created.Secret)
+	assert.Equal(t, int64(1), created.StoreId)
+
+	rec = serve(e, http.MethodGet, "/api/webhooks/1", nil, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+}
+
+func Test_WhenWebhookIsInvalid_ShouldReturnUnprocessableEntity(t *testing.T) {
+	e, _ := newWebhookServerForTest()
+
+	rec := serve(e, http.MethodPost, "/api/webhooks", jsonHeaders, []byte(`{"url":"partner","eventTypes":["price_changed"]}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Url must be an absolute http or https url")
+}
+
+func Test_ShouldListAndRedeliverWebhookDeliveries(t *testing.T) {
+	e, webhookRepository := newWebhookServerForTest()
+	serve(e, http.MethodPost, "/api/webhooks", jsonHeaders, []byte(`{"url":"https://partner.example.com","eventTypes":["deleted"]}`))
+	service.NewWebhookSubscriptionSink(webhookRepository).Deliver(domain.ProductEvent{Id: 10, Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 2}})
+
+	rec := serve(e, http.MethodGet, "/api/webhooks/1/deliveries?status=pending", nil, nil)
+
+	var deliveries []response.WebhookDeliveryResponse
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &deliveries))
+	assert.Equal(t, 1, len(deliveries))
+	assert.Equal(t, int64(10), deliveries[0].EventId)
+	assert.Contains(t, string(deliveries[0].Payload), `"productId":2`)
+
+	assert.Equal(t, http.StatusAccepted, serve(e, http.MethodPost, "/api/webhooks/1/deliveries/1/redeliver", nil, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(e, http.MethodPost, "/api/webhooks/1/deliveries/9/redeliver", nil, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(e, http.MethodGet, "/api/webhooks/1/deliveries?status=lost", nil, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(e, http.MethodGet, "/api/webhooks/2/deliveries", nil, nil).Code)
+}
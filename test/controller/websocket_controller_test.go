@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"example.com/product-api/controller"
+	"example.com/product-api/controller/request"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newWebsocketForTest(t *testing.T, middleware ...echo.MiddlewareFunc) (string, service.IProductEventBus) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+
+	e := echo.New()
+	e.Use(middleware...)
+	controller.NewWebsocketController(productEventBus).RegisterRoutes(e)
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws", productEventBus
+}
+
+func dialWebsocket(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	return conn
+}
+
+func Test_ShouldPushPriceUpdatesOfSubscribedProducts(t *testing.T) {
+	url, productEventBus := newWebsocketForTest(t)
+	conn := dialWebsocket(t, url)
+
+	assert.Nil(t, conn.WriteJSON(request.WebsocketMessage{Action: request.WS_SUBSCRIBE, ProductIds: []int64{1}, Stores: []string{"def tech"}}))
+
+	var subscribed response.WebsocketSubscribedResponse
+	assert.Nil(t, conn.ReadJSON(&subscribed))
+	assert.Equal(t, []int64{1}, subscribed.ProductIds)
+	assert.Equal(t, []string{"def tech"}, subscribed.Stores)
+
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 2, Price: 1400.0, Store: "ABC TECH"}})
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, PreviousPrice: 3000.0,
+		Product: domain.Product{Id: 1, Price: 2800.0, Discount: 22.0, Store: "ABC TECH"}})
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 3, Store: "DEF TECH"}})
+
+	var update response.WebsocketProductUpdate
+	assert.Nil(t, conn.ReadJSON(&update))
+	assert.Equal(t, response.WebsocketProductUpdate{Type: response.WS_PRICE, EventId: 2, ProductId: 1, Store: "ABC TECH",
+		Price: 2800.0, PreviousPrice: 3000.0, Discount: 22.0}, update)
+
+	assert.Nil(t, conn.ReadJSON(&update))
+	assert.Equal(t, response.WS_DELETED, update.Type)
+	assert.Equal(t, int64(3), update.ProductId)
+}
+
+func Test_ShouldStopPushingAfterUnsubscribe(t *testing.T) {
+	url, productEventBus := newWebsocketForTest(t)
+	conn := dialWebsocket(t, url)
+
+	var subscribed response.WebsocketSubscribedResponse
+	assert.Nil(t, conn.WriteJSON(request.WebsocketMessage{Action: request.WS_SUBSCRIBE, ProductIds: []int64{1, 2}}))
+	assert.Nil(t, conn.ReadJSON(&subscribed))
+	assert.Nil(t, conn.WriteJSON(request.WebsocketMessage{Action: request.WS_UNSUBSCRIBE, ProductIds: []int64{1}}))
+	assert.Nil(t, conn.ReadJSON(&subscribed))
+	assert.Equal(t, []int64{2}, subscribed.ProductIds)
+
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 1, Price: 2800.0}})
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 2, Price: 1400.0}})
+
+	var update response.WebsocketProductUpdate
+	assert.Nil(t, conn.ReadJSON(&update))
+	assert.Equal(t, int64(2), update.ProductId)
+}
+
+func Test_WhenMessageIsInvalid_ShouldReplyWithError(t *testing.T) {
+	url, _ := newWebsocketForTest(t)
+	conn := dialWebsocket(t, url)
+
+	var errorResponse response.WebsocketErrorResponse
+
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Nil(t, conn.ReadJSON(&errorResponse))
+	assert.Equal(t, "Message must be a JSON object", errorResponse.Error)
+
+	assert.Nil(t, conn.WriteJSON(request.WebsocketMessage{Action: "watch"}))
+	assert.Nil(t, conn.ReadJSON(&errorResponse))
+	assert.Equal(t, "Action must be subscribe or unsubscribe", errorResponse.Error)
+
+	productIds := make([]int64, controller.WS_MAX_SUBSCRIPTIONS+1)
+	for i := range productIds {
+		productIds[i] = int64(i + 1)
+	}
+	assert.Nil(t, conn.WriteJSON(request.WebsocketMessage{Action: request.WS_SUBSCRIBE, ProductIds: productIds}))
+	assert.Nil(t, conn.ReadJSON(&errorResponse))
+	assert.Equal(t, "A connection can not have more than 1000 subscriptions", errorResponse.Error)
+}
+
+func Test_WhenClientFallsBehind_ShouldCloseConnection(t *testing.T) {
+	url, productEventBus := newWebsocketForTest(t)
+	conn := dialWebsocket(t, url)
+
+	var subscribed response.WebsocketSubscribedResponse
+	assert.Nil(t, conn.WriteJSON(request.WebsocketMessage{Action: request.WS_SUBSCRIBE, ProductIds: []int64{1}}))
+	assert.Nil(t, conn.ReadJSON(&subscribed))
+
+	// Publishing never waits for the connection, so this overflows the
+	// subscription buffer faster than the server can write to the socket.
+	for i := 0; i < 100*service.PRODUCT_EVENT_SUBSCRIBER_BUFFER; i++ {
+		productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 1, Price: float32(i + 1)}})
+	}
+
+	var err error
+	for err == nil {
+		_, _, err = conn.ReadMessage()
+	}
+
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater))
+}
+
+func Test_ShouldRunServerMiddlewareBeforeUpgrade(t *testing.T) {
+	requireToken := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAuthorization) != "Bearer secret" {
+				return c.NoContent(http.StatusUnauthorized)
+			}
+			return next(c)
+		}
+	}
+	url, _ := newWebsocketForTest(t, requireToken)
+
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{echo.HeaderAuthorization: []string{"Bearer secret"}})
+	assert.Nil(t, err)
+	conn.Close()
+}
@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var INSERT_CATEGORIES = `INSERT INTO categories (name, parent_id)
+VALUES('Electronics', NULL),
+('Kitchen', 1),
+('AirFryers', 2),
+('Decoration', NULL);
+INSERT INTO product_categories (product_id, category_id)
+VALUES(1, 3),
+(2, 2),
+(4, 4);
+`
+
+func setupCategories() persistence.ICategoryRepository {
+	setup(ctx, dbPool)
+
+	_, err := dbPool.Exec(ctx, INSERT_CATEGORIES)
+	if err != nil {
+		log.Error(err)
+	}
+
+	return persistence.NewCategoryRepository(dbPool)
+}
+
+func TestGetAllProductsByCategoryWithDescendants(t *testing.T) {
+	setupCategories()
+
+	t.Run("GetAllProductsByCategoryWithDescendants", func(t *testing.T) {
+		directProducts := productRepository.GetAllByFilter(domain.ProductFilter{CategoryId: 1})
+		allProducts := productRepository.GetAllByFilter(domain.ProductFilter{CategoryId: 1, IncludeDescendants: true})
+		assert.Equal(t, 0, len(directProducts))
+		assert.Equal(t, 2, len(allProducts))
+		assert.Equal(t, "AirFryer", allProducts[0].Name)
+		assert.Equal(t, "Iron", allProducts[1].Name)
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestIsDescendant(t *testing.T) {
+	categoryRepository := setupCategories()
+
+	t.Run("IsDescendant", func(t *testing.T) {
+		assert.True(t, categoryRepository.IsDescendant(3, 1))
+		assert.False(t, categoryRepository.IsDescendant(1, 3))
+		assert.False(t, categoryRepository.IsDescendant(4, 1))
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	idempotencyRepository := persistence.NewIdempotencyRepository(dbPool)
+
+	t.Run("StoresAndReturnsFirstResponse", func(t *testing.T) {
+		claim, claimed, err := idempotencyRepository.Claim("key-1", "POST /api/products", "hash", time.Hour, time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+
+		inFlight, claimed, _ := idempotencyRepository.Claim("key-1", "POST /api/products", "hash", time.Hour, time.Minute)
+		assert.False(t, claimed)
+		assert.Nil(t, inFlight.Response)
+
+		err = idempotencyRepository.Complete(claim, domain.StoredResponse{
+			StatusCode: 201,
+			Headers:    map[string][]string{"Content-Type": {"application/json"}},
+			Body:       []byte(`{"name":"Kettle"}`),
+		})
+		assert.Nil(t, err)
+
+		completed, claimed, _ := idempotencyRepository.Claim("key-1", "POST /api/products", "hash", time.Hour, time.Minute)
+		assert.False(t, claimed)
+		assert.Equal(t, 201, completed.Response.StatusCode)
+		assert.Equal(t, []string{"application/json"}, completed.Response.Headers["Content-Type"])
+		assert.Equal(t, `{"name":"Kettle"}`, string(completed.Response.Body))
+
+		_, claimed, _ = idempotencyRepository.Claim("key-1", "POST /api/products/bulk", "hash", time.Hour, time.Minute)
+		assert.True(t, claimed)
+	})
+
+	t.Run("HandsOverAbandonedAndExpiredKeys", func(t *testing.T) {
+		abandoned, _, _ := idempotencyRepository.Claim("key-2", "POST /api/products", "hash", time.Hour, time.Minute)
+		time.Sleep(20 * time.Millisecond)
+
+		claim, claimed, _ := idempotencyRepository.Claim("key-2", "POST /api/products", "hash", time.Hour, 10*time.Millisecond)
+		assert.True(t, claimed)
+
+		idempotencyRepository.Complete(abandoned, domain.StoredResponse{StatusCode: 500})
+		idempotencyRepository.Complete(claim, domain.StoredResponse{StatusCode: 201})
+
+		completed, _, _ := idempotencyRepository.Claim("key-2", "POST /api/products", "hash", time.Hour, time.Minute)
+		assert.Equal(t, 201, completed.Response.StatusCode)
+
+		idempotencyRepository.Claim("key-3", "POST /api/products", "hash", 10*time.Millisecond, time.Minute)
+		time.Sleep(20 * time.Millisecond)
+
+		_, claimed, _ = idempotencyRepository.Claim("key-3", "POST /api/products", "other hash", time.Hour, time.Minute)
+		assert.True(t, claimed)
+	})
+
+	t.Run("ReleasesAndPurgesKeys", func(t *testing.T) {
+		claim, _, _ := idempotencyRepository.Claim("key-4", "POST /api/products", "hash", time.Hour, time.Minute)
+		assert.Nil(t, idempotencyRepository.Release(claim))
+
+		_, claimed, _ := idempotencyRepository.Claim("key-4", "POST /api/products", "hash", 10*time.Millisecond, time.Minute)
+		assert.True(t, claimed)
+		time.Sleep(20 * time.Millisecond)
+
+		purged, err := idempotencyRepository.PurgeExpired()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), purged)
+	})
+
+	clear(ctx, dbPool)
+}
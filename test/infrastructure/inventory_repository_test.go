@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReserveDoesNotOversell(t *testing.T) {
+	setup(ctx, dbPool)
+	inventoryRepository := persistence.NewInventoryRepository(dbPool)
+
+	t.Run("ReserveDoesNotOversell", func(t *testing.T) {
+		inventoryRepository.Adjust(1, 1, 5)
+
+		var reserved int32
+		var waitGroup sync.WaitGroup
+
+		for i := 0; i < 20; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				ok, _ := inventoryRepository.Reserve(1, 1, 1)
+				if ok {
+					atomic.AddInt32(&reserved, 1)
+				}
+			}()
+		}
+		waitGroup.Wait()
+
+		inventory, _ := inventoryRepository.Get(1, 1)
+		assert.Equal(t, int32(5), reserved)
+		assert.Equal(t, int32(0), inventory.Available())
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestCommitAndRelease(t *testing.T) {
+	setup(ctx, dbPool)
+	inventoryRepository := persistence.NewInventoryRepository(dbPool)
+
+	t.Run("CommitAndRelease", func(t *testing.T) {
+		inventoryRepository.Adjust(1, 1, 10)
+		inventoryRepository.Reserve(1, 1, 4)
+		committed, _ := inventoryRepository.Commit(1, 1, 3)
+		released, _ := inventoryRepository.Release(1, 1, 1)
+		overReleased, _ := inventoryRepository.Release(1, 1, 1)
+		removedReserved, _ := inventoryRepository.Adjust(1, 1, -8)
+
+		inventory, _ := inventoryRepository.Get(1, 1)
+		assert.True(t, committed)
+		assert.True(t, released)
+		assert.False(t, overReleased)
+		assert.False(t, removedReserved)
+		assert.Equal(t, domain.Inventory{ProductId: 1, StoreId: 1, Store: "ABC TECH", OnHand: 7, Reserved: 0}, inventory)
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestGetAllProductsInStock(t *testing.T) {
+	setup(ctx, dbPool)
+	inventoryRepository := persistence.NewInventoryRepository(dbPool)
+
+	t.Run("GetAllProductsInStock", func(t *testing.T) {
+		inventoryRepository.Adjust(2, 1, 1)
+		inventoryRepository.Adjust(3, 1, 1)
+		inventoryRepository.Reserve(3, 1, 1)
+
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{InStock: true})
+		assert.Equal(t, 1, len(actualProducts))
+		assert.Equal(t, "Iron", actualProducts[0].Name)
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOutbox(t *testing.T) {
+	setup(ctx, dbPool)
+	outboxRepository := persistence.NewOutboxRepository(dbPool)
+
+	claimAll := func() []domain.OutboxMessage {
+		var claimed []domain.OutboxMessage
+		outboxRepository.ProcessBatch(100, 0, func(messages []domain.OutboxMessage) []domain.OutboxOutcome {
+			claimed = messages
+			return nil
+		})
+		return claimed
+	}
+
+	t.Run("MutationsWriteEventsInTheirTransaction", func(t *testing.T) {
+		productRepository.Add(domain.Product{Name: "Kettle", Price: 800.0, StoreId: 1})
+		productRepository.UpdatePrice(1, 2800.0)
+		productRepository.UpdatePrice(1, 2800.0)
+		productRepository.DeleteById(2)
+		assert.NotNil(t, productRepository.UpdatePrice(99, 100.0))
+
+		messages := claimAll()
+
+		assert.Equal(t, 3, len(messages))
+		assert.Equal(t, domain.PRODUCT_CREATED, messages[0].Event.Type)
+		assert.Equal(t, "ABC TECH", messages[0].Event.Product.Store)
+		assert.Equal(t, domain.PRODUCT_PRICE_CHANGED, messages[1].Event.Type)
+		assert.Equal(t, float32(3000.0), messages[1].Event.PreviousPrice)
+		assert.Equal(t, domain.PRODUCT_DELETED, messages[2].Event.Type)
+		assert.Equal(t, int64(2), messages[2].Event.Product.Id)
+	})
+
+	t.Run("OutcomesAreStored", func(t *testing.T) {
+		processed, err := outboxRepository.ProcessBatch(100, time.Minute, func(messages []domain.OutboxMessage) []domain.OutboxOutcome {
+			return []domain.OutboxOutcome{
+				{Id: messages[0].Id, Delivered: true},
+				{Id: messages[1].Id, DeliveredSinks: []string{"bus"}, Error: "webhook: unavailable", NextAttemptAt: time.Now().Add(time.Hour)},
+				{Id: messages[2].Id, Error: "webhook: unavailable", DeadLetter: true},
+			}
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, processed)
+		assert.Empty(t, claimAll())
+
+		var attempts int
+		var deliveredSinks []string
+		dbPool.QueryRow(ctx, "SELECT attempts, delivered_sinks FROM outbox WHERE dead_lettered_at IS NULL AND delivered_at IS NULL").Scan(&attempts, &deliveredSinks)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, []string{"bus"}, deliveredSinks)
+	})
+
+	t.Run("LeasedMessagesAreNotClaimedAgain", func(t *testing.T) {
+		productRepository.UpdatePrice(3, 9000.0)
+
+		var leased []domain.OutboxMessage
+		outboxRepository.ProcessBatch(100, time.Minute, func(messages []domain.OutboxMessage) []domain.OutboxOutcome {
+			leased = messages
+			assert.Empty(t, claimAll())
+			return nil
+		})
+
+		assert.Equal(t, 1, len(leased))
+		assert.Empty(t, claimAll())
+	})
+
+	t.Run("OutcomeOfExpiredLeaseIsIgnored", func(t *testing.T) {
+		dbPool.Exec(ctx, "UPDATE outbox SET next_attempt_at = now() WHERE dead_lettered_at IS NULL")
+
+		outboxRepository.ProcessBatch(100, 0, func(messages []domain.OutboxMessage) []domain.OutboxOutcome {
+			// Another relay takes the messages over once the lease ran out.
+			claimAll()
+			return []domain.OutboxOutcome{{Id: messages[0].Id, Error: "webhook: unavailable", NextAttemptAt: time.Now().Add(time.Hour)}}
+		})
+
+		var attempts int
+		dbPool.QueryRow(ctx, "SELECT max(attempts) FROM outbox WHERE dead_lettered_at IS NULL AND delivered_at IS NULL").Scan(&attempts)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("DeliveredMessagesAreKeptForReplay", func(t *testing.T) {
+		lastId, err := outboxRepository.GetLastId()
+		assert.Nil(t, err)
+
+		events, err := outboxRepository.GetAfter(lastId-2, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, uint64(lastId), events[1].Id)
+
+		purged, err := outboxRepository.DeleteDeliveredBefore(time.Now().Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), purged)
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetAllProductsByAttributes(t *testing.T) {
+	setup(ctx, dbPool)
+	productRepository.UpdateAttributes(1, map[string]interface{}{"wattage": 1500, "color": "black"})
+	productRepository.UpdateAttributes(2, map[string]interface{}{"wattage": "unknown"})
+	productRepository.UpdateAttributes(4, map[string]interface{}{"wattage": 60, "color": "white"})
+
+	t.Run("FilterByNumericAttribute", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{Attributes: []domain.AttributeCondition{
+			{Key: "wattage", Operator: domain.ATTRIBUTE_GTE, Number: 1000},
+		}})
+		assert.Equal(t, 1, len(actualProducts))
+		assert.Equal(t, "AirFryer", actualProducts[0].Name)
+		assert.Equal(t, float64(1500), actualProducts[0].Attributes["wattage"])
+	})
+
+	t.Run("FilterByAttributeEquality", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{Attributes: []domain.AttributeCondition{
+			{Key: "color", Operator: domain.ATTRIBUTE_EQ, Value: "white"},
+		}})
+		assert.Equal(t, 1, len(actualProducts))
+		assert.Equal(t, "Floor Lamp", actualProducts[0].Name)
+	})
+
+	t.Run("FilterByAttributeExistence", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{Attributes: []domain.AttributeCondition{
+			{Key: "wattage", Operator: domain.ATTRIBUTE_EXISTS},
+		}})
+		assert.Equal(t, 3, len(actualProducts))
+	})
+
+	t.Run("FilterKeyIsParameterized", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{Attributes: []domain.AttributeCondition{
+			{Key: "color' OR '1'='1", Operator: domain.ATTRIBUTE_EQ, Value: "x"},
+		}})
+		assert.Equal(t, 0, len(actualProducts))
+	})
+
+	clear(ctx, dbPool)
+}
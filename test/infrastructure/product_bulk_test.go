@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplyBulk(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("AtomicFailureRollsBackEverything", func(t *testing.T) {
+		results, err := productRepository.ApplyBulk([]domain.BulkOperation{
+			{Action: domain.BULK_CREATE, Product: domain.Product{Name: "Kettle", Price: 800.0, StoreId: 1}},
+			{Action: domain.BULK_DELETE, Product: domain.Product{Id: 99}},
+		}, true)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Product not found with id 99", results[1].Error)
+		assert.Equal(t, 4, len(productRepository.GetAll()))
+	})
+
+	t.Run("BestEffortAppliesValidItems", func(t *testing.T) {
+		results, err := productRepository.ApplyBulk([]domain.BulkOperation{
+			{Action: domain.BULK_CREATE, Product: domain.Product{Name: "Kettle", Price: 800.0, StoreId: 99}},
+			{Action: domain.BULK_CREATE, Product: domain.Product{Name: "Vase", Price: 250.0, Store: "New Home"}},
+			{Action: domain.BULK_UPDATE, Product: domain.Product{Id: 1, Price: 2800.0}},
+			{Action: domain.BULK_DELETE, Product: domain.Product{Id: 4}},
+		}, false)
+		product, _ := productRepository.GetById(1)
+		assert.Nil(t, err)
+		assert.Equal(t, "Store not found with id 99", results[0].Error)
+		assert.NotEqual(t, int64(0), results[1].Id)
+		assert.Equal(t, float32(2800.0), product.Price)
+		assert.Equal(t, 4, len(productRepository.GetAll()))
+		assert.Equal(t, 1, len(productRepository.GetAllByStore("new home")))
+	})
+
+	t.Run("EventsCarryTheStoredProduct", func(t *testing.T) {
+		dbPool.Exec(ctx, "TRUNCATE outbox")
+
+		_, err := productRepository.ApplyBulk([]domain.BulkOperation{
+			{Action: domain.BULK_UPDATE, Product: domain.Product{Id: 3, Price: 9000.0}},
+			{Action: domain.BULK_DELETE, Product: domain.Product{Id: 2}},
+		}, true)
+		assert.Nil(t, err)
+
+		var events []domain.ProductEvent
+		persistence.NewOutboxRepository(dbPool).ProcessBatch(100, 0, func(messages []domain.OutboxMessage) []domain.OutboxOutcome {
+			for _, message := range messages {
+				events = append(events, message.Event)
+			}
+			return nil
+		})
+
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, "Washing Machine", events[0].Product.Name)
+		assert.Equal(t, float32(9000.0), events[0].Product.Price)
+		assert.Equal(t, "Iron", events[1].Product.Name)
+		assert.Equal(t, float32(10.0), events[1].Product.Discount)
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"context"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type recordingChangeHandler struct {
+	events        chan domain.ProductEvent
+	resyncs       chan struct{}
+	renamedStores chan int64
+}
+
+func (handler *recordingChangeHandler) ProductChanged(event domain.ProductEvent) {
+	handler.events <- event
+}
+
+func (handler *recordingChangeHandler) Resync() {
+	handler.resyncs <- struct{}{}
+}
+
+func (handler *recordingChangeHandler) StoreRenamed(storeId int64) {
+	handler.renamedStores <- storeId
+}
+
+func waitForListener(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		var listening int
+		dbPool.QueryRow(ctx, "SELECT count(*) FROM pg_stat_activity WHERE application_name = $1 AND query LIKE 'LISTEN%'",
+			persistence.PRODUCT_CHANGE_LISTENER_NAME).Scan(&listening)
+		if listening > 0 {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("Listener did not connect")
+}
+
+func TestProductChangeListener(t *testing.T) {
+	setup(ctx, dbPool)
+	listenCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	handler := &recordingChangeHandler{events: make(chan domain.ProductEvent, 10), resyncs: make(chan struct{}, 10),
+		renamedStores: make(chan int64, 10)}
+	go persistence.NewProductChangeListener(dbPool, productRepository).Listen(listenCtx, handler)
+	waitForListener(t)
+
+	t.Run("ResyncsWhenFirstListening", func(t *testing.T) {
+		select {
+		case <-handler.resyncs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Listener did not resync")
+		}
+	})
+
+	t.Run("CommittedChangesAreNotified", func(t *testing.T) {
+		productRepository.UpdatePrice(1, 2800.0)
+
+		var outboxId uint64
+		dbPool.QueryRow(ctx, "SELECT max(id) FROM outbox").Scan(&outboxId)
+
+		select {
+		case event := <-handler.events:
+			assert.Equal(t, outboxId, event.Id)
+			assert.Equal(t, domain.PRODUCT_PRICE_CHANGED, event.Type)
+			assert.Equal(t, int64(1), event.Product.Id)
+			assert.Equal(t, "AirFryer", event.Product.Name)
+			assert.Equal(t, float32(3000.0), event.PreviousPrice)
+		case <-time.After(5 * time.Second):
+			t.Fatal("No notification received")
+		}
+	})
+
+	t.Run("StoreRenamesAreNotified", func(t *testing.T) {
+		persistence.NewStoreRepository(dbPool).UpdateName(2, "DECORATION HOUSE")
+
+		select {
+		case storeId := <-handler.renamedStores:
+			assert.Equal(t, int64(2), storeId)
+		case <-time.After(5 * time.Second):
+			t.Fatal("No store rename notification received")
+		}
+	})
+
+	t.Run("ReconnectsAndResyncsAfterConnectionLoss", func(t *testing.T) {
+		dbPool.Exec(ctx, "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE application_name = $1",
+			persistence.PRODUCT_CHANGE_LISTENER_NAME)
+
+		select {
+		case <-handler.resyncs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Listener did not resync")
+		}
+
+		productRepository.DeleteById(2)
+
+		select {
+		case event := <-handler.events:
+			assert.Equal(t, domain.PRODUCT_DELETED, event.Type)
+			assert.Equal(t, int64(2), event.Product.Id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("No notification received after reconnect")
+		}
+	})
+
+	cancel()
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProductNamesAreUniquePerStore(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("SameNameInStoreIsRejected", func(t *testing.T) {
+		_, err := productRepository.Add(domain.Product{Name: " airfryer ", Price: 3000.0, StoreId: 1})
+		assert.ErrorIs(t, err, persistence.ErrDuplicateProduct)
+		assert.Equal(t, 4, len(productRepository.GetAll()))
+	})
+
+	t.Run("SameNameInOtherStoreIsAdded", func(t *testing.T) {
+		_, err := productRepository.Add(domain.Product{Name: "AirFryer", Price: 3000.0, StoreId: 2})
+		assert.Nil(t, err)
+	})
+
+	t.Run("RestoringOverReplacementIsRejected", func(t *testing.T) {
+		productRepository.DeleteById(2)
+		_, err := productRepository.Add(domain.Product{Name: "Iron", Price: 1600.0, StoreId: 1})
+		assert.Nil(t, err)
+		assert.ErrorIs(t, productRepository.Restore(2), persistence.ErrDuplicateProduct)
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestFindDuplicateProducts(t *testing.T) {
+	setup(ctx, dbPool)
+	productRepository.Add(domain.Product{Name: "Air-Fryer XL", Price: 3500.0, StoreId: 1})
+	productRepository.Add(domain.Product{Name: "AirFryer", Price: 3000.0, StoreId: 2})
+
+	t.Run("FindsSimilarNamesInStore", func(t *testing.T) {
+		duplicates := productRepository.FindDuplicates("abc tech", 0.3, 10)
+		assert.Equal(t, 1, len(duplicates))
+		assert.Equal(t, "AirFryer", duplicates[0].Product.Name)
+		assert.Equal(t, "Air-Fryer XL", duplicates[0].Duplicate.Name)
+		assert.Equal(t, "ABC TECH", duplicates[0].Duplicate.Store)
+	})
+
+	t.Run("DoesNotPairAcrossStores", func(t *testing.T) {
+		assert.Equal(t, 0, len(productRepository.FindDuplicates("Decoration Palace", 0.3, 10)))
+	})
+
+	t.Run("HigherThresholdFindsFewer", func(t *testing.T) {
+		assert.Equal(t, 0, len(productRepository.FindDuplicates("", 0.9, 10)))
+	})
+
+	clear(ctx, dbPool)
+}
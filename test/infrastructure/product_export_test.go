@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamAllProductsByFilter(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("StreamsMatchingProductsInOrder", func(t *testing.T) {
+		var names []string
+		err := productRepository.StreamAllByFilter(domain.ProductFilter{Store: "ABC TECH"}, func(product domain.Product) error {
+			names = append(names, product.Name)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"AirFryer", "Iron", "Washing Machine"}, names)
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetProductPageByFilter(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("ReturnsProductsAfterCursor", func(t *testing.T) {
+		firstPage := productRepository.GetPageByFilter(domain.ProductFilter{}, 0, 2)
+		assert.Equal(t, 2, len(firstPage))
+		assert.Equal(t, "AirFryer", firstPage[0].Name)
+		assert.Equal(t, "Iron", firstPage[1].Name)
+
+		secondPage := productRepository.GetPageByFilter(domain.ProductFilter{}, firstPage[1].Id, 2)
+		assert.Equal(t, 2, len(secondPage))
+		assert.Equal(t, "Washing Machine", secondPage[0].Name)
+		assert.Equal(t, "Floor Lamp", secondPage[1].Name)
+	})
+
+	t.Run("AppliesFilter", func(t *testing.T) {
+		page := productRepository.GetPageByFilter(domain.ProductFilter{Store: "Decoration Palace"}, 0, 10)
+		assert.Equal(t, 1, len(page))
+		assert.Equal(t, "Floor Lamp", page[0].Name)
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestGetAllStoresByNames(t *testing.T) {
+	setup(ctx, dbPool)
+	storeRepository := persistence.NewStoreRepository(dbPool)
+
+	stores := storeRepository.GetAllByNames([]string{"abc tech", "Decoration  Palace", "Unknown"})
+
+	assert.Equal(t, 2, len(stores))
+	assert.Equal(t, "ABC TECH", stores[0].Name)
+	assert.Equal(t, "Decoration Palace", stores[1].Name)
+
+	clear(ctx, dbPool)
+}
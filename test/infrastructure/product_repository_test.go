@@ -9,6 +9,7 @@ import (
 	"golang.org/x/net/context"
 	"os"
 	"testing"
+	"time"
 )
 
 var productRepository persistence.IProductRepository
@@ -50,6 +51,7 @@ func TestGetAllProducts(t *testing.T) {
 			Name:     "AirFryer",
 			Price:    3000.0,
 			Discount: 22.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -57,6 +59,7 @@ func TestGetAllProducts(t *testing.T) {
 			Name:     "Iron",
 			Price:    1500.0,
 			Discount: 10.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -64,6 +67,7 @@ func TestGetAllProducts(t *testing.T) {
 			Name:     "Washing Machine",
 			Price:    10000.0,
 			Discount: 15.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -71,6 +75,7 @@ func TestGetAllProducts(t *testing.T) {
 			Name:     "Floor Lamp",
 			Price:    2000.0,
 			Discount: 0.0,
+			StoreId:  2,
 			Store:    "Decoration Palace",
 		},
 	}
@@ -78,7 +83,7 @@ func TestGetAllProducts(t *testing.T) {
 	t.Run("GetAllProducts", func(t *testing.T) {
 		actualProducts := productRepository.GetAll()
 		assert.Equal(t, 4, len(actualProducts))
-		assert.Equal(t, expectedProducts, actualProducts)
+		assert.Equal(t, expectedProducts, withoutVersions(actualProducts...))
 	})
 
 	clear(ctx, dbPool)
@@ -92,13 +97,14 @@ func TestGetById(t *testing.T) {
 		Name:     "AirFryer",
 		Price:    3000.0,
 		Discount: 22.0,
+		StoreId:  1,
 		Store:    "ABC TECH",
 	}
 
 	t.Run("GetById", func(t *testing.T) {
 		actualProduct, _ := productRepository.GetById(1)
 		_, err := productRepository.GetById(5)
-		assert.Equal(t, expectedProduct, actualProduct)
+		assert.Equal(t, expectedProduct, withoutVersions(actualProduct)[0])
 		assert.Equal(t, "Product not found with id 5", err.Error())
 	})
 
@@ -114,6 +120,7 @@ func TestGetAllProductsByStore(t *testing.T) {
 			Name:     "AirFryer",
 			Price:    3000.0,
 			Discount: 22.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -121,6 +128,7 @@ func TestGetAllProductsByStore(t *testing.T) {
 			Name:     "Iron",
 			Price:    1500.0,
 			Discount: 10.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -128,6 +136,7 @@ func TestGetAllProductsByStore(t *testing.T) {
 			Name:     "Washing Machine",
 			Price:    10000.0,
 			Discount: 15.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 	}
@@ -135,7 +144,7 @@ func TestGetAllProductsByStore(t *testing.T) {
 	t.Run("GetAllProductsByStore", func(t *testing.T) {
 		actualProducts := productRepository.GetAllByStore("ABC TECH")
 		assert.Equal(t, 3, len(actualProducts))
-		assert.Equal(t, expectedProducts, actualProducts)
+		assert.Equal(t, expectedProducts, withoutVersions(actualProducts...))
 	})
 
 	clear(ctx, dbPool)
@@ -148,6 +157,7 @@ func TestAddProduct(t *testing.T) {
 			Name:     "Telephone",
 			Price:    20000.0,
 			Discount: 10.0,
+			StoreId:  1,
 			Store:    "Samsung",
 		},
 	}
@@ -162,7 +172,41 @@ func TestAddProduct(t *testing.T) {
 		productRepository.Add(newProduct)
 		actualProducts := productRepository.GetAll()
 		assert.Equal(t, 1, len(actualProducts))
-		assert.Equal(t, expectedProduct, actualProducts)
+		assert.Equal(t, expectedProduct, withoutVersions(actualProducts...))
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestGetAllProductsByStoreIgnoresCase(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("GetAllProductsByStoreIgnoresCase", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByStore("  abc Tech ")
+		assert.Equal(t, 3, len(actualProducts))
+		assert.Equal(t, "ABC TECH", actualProducts[0].Store)
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestAddProductToExistingStore(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("AddProductToExistingStore", func(t *testing.T) {
+		addedProduct, err := productRepository.Add(domain.Product{
+			Name:     "Kettle",
+			Price:    800.0,
+			Discount: 5.0,
+			Store:    "abc tech",
+		})
+		actualProducts := productRepository.GetAllByStoreId(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(actualProducts))
+		assert.Equal(t, "ABC TECH", actualProducts[3].Store)
+		assert.Equal(t, actualProducts[3].Id, addedProduct.Id)
+		assert.Equal(t, int64(1), addedProduct.StoreId)
+		assert.Equal(t, "ABC TECH", addedProduct.Store)
 	})
 
 	clear(ctx, dbPool)
@@ -191,6 +235,7 @@ func TestDeleteProduct(t *testing.T) {
 			Name:     "AirFryer",
 			Price:    3000.0,
 			Discount: 22.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -198,6 +243,7 @@ func TestDeleteProduct(t *testing.T) {
 			Name:     "Iron",
 			Price:    1500.0,
 			Discount: 10.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 		{
@@ -205,6 +251,7 @@ func TestDeleteProduct(t *testing.T) {
 			Name:     "Washing Machine",
 			Price:    10000.0,
 			Discount: 15.0,
+			StoreId:  1,
 			Store:    "ABC TECH",
 		},
 	}
@@ -214,7 +261,7 @@ func TestDeleteProduct(t *testing.T) {
 		actualProducts := productRepository.GetAll()
 		_, err := productRepository.GetById(4)
 		assert.Equal(t, 3, len(actualProducts))
-		assert.Equal(t, expectedProducts, actualProducts)
+		assert.Equal(t, expectedProducts, withoutVersions(actualProducts...))
 		assert.Equal(t, "Product not found with id 4", err.Error())
 
 	})
@@ -225,3 +272,35 @@ func TestDeleteProduct(t *testing.T) {
 func TestSetup(t *testing.T) {
 	setup(ctx, dbPool)
 }
+
+func TestUpdateBumpsProductVersion(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("UpdateBumpsProductVersion", func(t *testing.T) {
+		productBeforeUpdate, _ := productRepository.GetById(1)
+		assert.Equal(t, int64(1), productBeforeUpdate.Version)
+		assert.False(t, productBeforeUpdate.UpdatedAt.IsZero())
+
+		productRepository.UpdatePrice(1, 4000.0)
+		productAfterUpdate, _ := productRepository.GetById(1)
+		assert.Equal(t, int64(2), productAfterUpdate.Version)
+		assert.True(t, productAfterUpdate.UpdatedAt.After(productBeforeUpdate.UpdatedAt))
+
+		// The representation of a product carries its store name.
+		dbPool.Exec(ctx, "Update stores set name = 'ABC Technology' where id = 1")
+		productAfterRename, _ := productRepository.GetById(1)
+		assert.Equal(t, int64(3), productAfterRename.Version)
+	})
+
+	clear(ctx, dbPool)
+}
+
+// withoutVersions clears the version and update time, which depend on when
+// the test data was written.
+func withoutVersions(products ...domain.Product) []domain.Product {
+	for i := range products {
+		products[i].Version = 0
+		products[i].UpdatedAt = time.Time{}
+	}
+	return products
+}
@@ -0,0 +1,37 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSearchProducts(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("SearchByPrefix", func(t *testing.T) {
+		results := productRepository.Search("wash", domain.ProductFilter{}, 10)
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, "Washing Machine", results[0].Product.Name)
+		assert.Equal(t, "<mark>Washing</mark> Machine", results[0].Highlight)
+	})
+
+	t.Run("SearchWithTypo", func(t *testing.T) {
+		results := productRepository.Search("airfyer", domain.ProductFilter{}, 10)
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, "AirFryer", results[0].Product.Name)
+	})
+
+	t.Run("SearchWithinStore", func(t *testing.T) {
+		results := productRepository.Search("lamp", domain.ProductFilter{Store: "ABC TECH"}, 10)
+		assert.Equal(t, 0, len(results))
+	})
+
+	t.Run("SearchIgnoresQuerySyntax", func(t *testing.T) {
+		results := productRepository.Search("iron & | ! :*", domain.ProductFilter{}, 10)
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, "Iron", results[0].Product.Name)
+	})
+
+	clear(ctx, dbPool)
+}
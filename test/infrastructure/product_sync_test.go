@@ -0,0 +1,95 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpsertProductByExternalRef(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("FirstUpsertCreates", func(t *testing.T) {
+		upsert, err := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-1", Name: "Kettle", Price: 400.0, Store: "New Store"})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.UPSERT_CREATED, upsert.Outcome)
+		assert.Equal(t, "NEW STORE", upsert.Product.Store)
+		assert.Equal(t, "ERP-1", upsert.Product.ExternalRef)
+	})
+
+	t.Run("ChangedUpsertUpdates", func(t *testing.T) {
+		upsert, err := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-1", Name: "Kettle", Price: 450.0, StoreId: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.UPSERT_UPDATED, upsert.Outcome)
+		assert.Equal(t, float32(450.0), upsert.Product.Price)
+		assert.Equal(t, "ABC TECH", upsert.Product.Store)
+		assert.Equal(t, int64(2), upsert.Product.Version)
+	})
+
+	t.Run("UnchangedUpsertKeepsVersion", func(t *testing.T) {
+		upsert, err := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-1", Name: "Kettle", Price: 450.0, StoreId: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.UPSERT_UNCHANGED, upsert.Outcome)
+		assert.Equal(t, int64(2), upsert.Product.Version)
+	})
+
+	t.Run("UpsertRestoresDeletedProduct", func(t *testing.T) {
+		upsert, _ := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-1", Name: "Kettle", Price: 450.0, StoreId: 1})
+		productRepository.DeleteById(upsert.Product.Id)
+		upsert, err := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-1", Name: "Kettle", Price: 450.0, StoreId: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.UPSERT_UPDATED, upsert.Outcome)
+		_, err = productRepository.GetById(upsert.Product.Id)
+		assert.Nil(t, err)
+	})
+
+	t.Run("DuplicateNameIsRejected", func(t *testing.T) {
+		_, err := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-2", Name: "Iron", Price: 1500.0, StoreId: 1})
+		assert.ErrorIs(t, err, persistence.ErrDuplicateProduct)
+	})
+
+	t.Run("UnknownStoreIsRejected", func(t *testing.T) {
+		_, err := productRepository.UpsertByExternalRef(domain.Product{ExternalRef: "ERP-2", Name: "Toaster", Price: 300.0, StoreId: 99})
+		assert.Equal(t, "Store not found with id 99", err.Error())
+	})
+
+	clear(ctx, dbPool)
+}
+
+func TestSyncProductsByExternalRef(t *testing.T) {
+	setup(ctx, dbPool)
+	productRepository.SyncByExternalRef([]domain.Product{
+		{ExternalRef: "ERP-1", Name: "Kettle", Price: 400.0, StoreId: 1},
+		{ExternalRef: "ERP-2", Name: "Toaster", Price: 300.0, StoreId: 1},
+	}, false)
+
+	t.Run("FullFeedDiscontinuesMissingProducts", func(t *testing.T) {
+		report, err := productRepository.SyncByExternalRef([]domain.Product{
+			{ExternalRef: "ERP-1", Name: "Kettle", Price: 400.0, StoreId: 1},
+			{ExternalRef: "ERP-3", Name: "Mirror", Price: 800.0, StoreId: 2},
+		}, true)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.UPSERT_UNCHANGED, report.Upserts[0].Outcome)
+		assert.Equal(t, domain.UPSERT_CREATED, report.Upserts[1].Outcome)
+		assert.Equal(t, 1, len(report.Discontinued))
+		assert.Equal(t, "ERP-2", report.Discontinued[0].ExternalRef)
+		assert.True(t, report.Discontinued[0].Discontinued)
+	})
+
+	t.Run("UnsyncedProductsAreNotDiscontinued", func(t *testing.T) {
+		airFryer, _ := productRepository.GetById(1)
+		assert.False(t, airFryer.Discontinued)
+	})
+
+	t.Run("FailedFeedChangesNothing", func(t *testing.T) {
+		_, err := productRepository.SyncByExternalRef([]domain.Product{
+			{ExternalRef: "ERP-4", Name: "Vase", Price: 200.0, StoreId: 2},
+			{ExternalRef: "ERP-5", Name: "AirFryer", Price: 3000.0, StoreId: 1},
+		}, true)
+		assert.Equal(t, "Product ERP-5: Store already has a product with this name", err.Error())
+		assert.Equal(t, 2, len(productRepository.GetAllByStore("Decoration Palace")))
+	})
+
+	clear(ctx, dbPool)
+}
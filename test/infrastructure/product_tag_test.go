@@ -0,0 +1,36 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetAllProductsByTagsAndFacets(t *testing.T) {
+	setup(ctx, dbPool)
+	tagRepository := persistence.NewTagRepository(dbPool)
+	tagRepository.AddToProduct(1, []string{"kitchen", "sale"})
+	tagRepository.AddToProduct(2, []string{"sale"})
+	tagRepository.AddToProduct(4, []string{"lighting"})
+
+	t.Run("FilterByAnyTag", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{Tags: []string{"kitchen", "lighting"}})
+		assert.Equal(t, 2, len(actualProducts))
+	})
+
+	t.Run("FilterByAllTags", func(t *testing.T) {
+		actualProducts := productRepository.GetAllByFilter(domain.ProductFilter{Tags: []string{"kitchen", "sale"}, MatchAllTags: true})
+		assert.Equal(t, 1, len(actualProducts))
+		assert.Equal(t, "AirFryer", actualProducts[0].Name)
+	})
+
+	t.Run("FacetsFollowFilter", func(t *testing.T) {
+		facets := productRepository.GetFacets(domain.ProductFilter{Store: "ABC TECH"})
+		assert.Equal(t, []domain.FacetCount{{Value: "sale", Count: 2}, {Value: "kitchen", Count: 1}}, facets.Tags)
+		assert.Equal(t, []domain.FacetCount{{Value: "ABC TECH", Count: 3}}, facets.Stores)
+		assert.Equal(t, 3, len(facets.PriceBuckets))
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSoftDeleteRestoreAndPurge(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("DeletedProductIsHiddenAndInTrash", func(t *testing.T) {
+		productRepository.DeleteById(1)
+		_, err := productRepository.GetById(1)
+		deletedProducts := productRepository.GetAllDeleted()
+		assert.NotNil(t, err)
+		assert.Equal(t, 3, len(productRepository.GetAll()))
+		assert.Equal(t, 1, len(deletedProducts))
+		assert.Equal(t, "AirFryer", deletedProducts[0].Name)
+	})
+
+	t.Run("RestoredProductIsVisibleAgain", func(t *testing.T) {
+		err := productRepository.Restore(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(productRepository.GetAll()))
+		assert.NotNil(t, productRepository.Restore(1))
+	})
+
+	t.Run("PurgeOnlyRemovesExpiredProducts", func(t *testing.T) {
+		productRepository.DeleteById(2)
+		purged, _ := productRepository.PurgeDeletedBefore(time.Now().Add(-time.Hour))
+		assert.Equal(t, int64(0), purged)
+		purged, _ = productRepository.PurgeDeletedBefore(time.Now().Add(time.Second))
+		assert.Equal(t, int64(1), purged)
+		assert.Equal(t, 0, len(productRepository.GetAllDeleted()))
+	})
+
+	clear(ctx, dbPool)
+}
@@ -0,0 +1,63 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPromotionRepository(t *testing.T) {
+	setup(ctx, dbPool)
+	promotionRepository := persistence.NewPromotionRepository(dbPool)
+	now := time.Now()
+
+	promotionRepository.Add(domain.Promotion{Code: "TWICE", DiscountType: domain.AMOUNT_DISCOUNT, Value: 100,
+		ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour), UsageLimit: 2})
+	promotionRepository.Add(domain.Promotion{Code: "UNLIMITED", DiscountType: domain.AMOUNT_DISCOUNT, Value: 100,
+		ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour)})
+
+	t.Run("ConcurrentRedemptionsStopAtLimit", func(t *testing.T) {
+		promotion, _ := promotionRepository.GetByCode("TWICE")
+
+		var waitGroup sync.WaitGroup
+		errs := make(chan error, 5)
+
+		for i := 0; i < 5; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				errs <- promotionRepository.Redeem(promotion.Id)
+			}()
+		}
+
+		waitGroup.Wait()
+		close(errs)
+
+		exhausted := 0
+		for err := range errs {
+			if err == persistence.ErrPromotionExhausted {
+				exhausted++
+			}
+		}
+
+		promotion, _ = promotionRepository.GetByCode("TWICE")
+		assert.Equal(t, 3, exhausted)
+		assert.Equal(t, int32(2), promotion.UsageCount)
+	})
+
+	t.Run("PromotionWithoutLimitIsAlwaysRedeemed", func(t *testing.T) {
+		promotion, _ := promotionRepository.GetByCode("UNLIMITED")
+
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, promotionRepository.Redeem(promotion.Id))
+		}
+
+		promotion, _ = promotionRepository.GetByCode("UNLIMITED")
+		assert.Equal(t, int32(3), promotion.UsageCount)
+	})
+
+	clear(ctx, dbPool)
+}
@@ -7,10 +7,10 @@ import (
 )
 
 func TruncateTestData(ctx context.Context, dbPool *pgxpool.Pool) {
-	_, truncateResultErr := dbPool.Exec(ctx, "TRUNCATE products RESTART IDENTITY")
+	_, truncateResultErr := dbPool.Exec(ctx, "TRUNCATE promotions, idempotency_keys, webhook_deliveries, webhook_subscriptions, outbox, product_tags, tags, inventory, product_categories, categories, products, stores RESTART IDENTITY CASCADE")
 	if truncateResultErr != nil {
 		log.Error(truncateResultErr)
 	} else {
-		log.Info("Products table truncated")
+		log.Info("Products and stores tables truncated")
 	}
 }
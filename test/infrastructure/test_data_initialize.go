@@ -7,14 +7,26 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
-var INSERT_PRODUCTS = `INSERT INTO products (name, price, discount, store) 
-VALUES('AirFryer', 3000.0, 22.0, 'ABC TECH'),
-('Iron', 1500.0, 10.0, 'ABC TECH'),
-('Washing Machine', 10000.0, 15.0, 'ABC TECH'),
-('Floor Lamp', 2000.0, 0.0, 'Decoration Palace');
+var INSERT_STORES = `INSERT INTO stores (name)
+VALUES('ABC TECH'),
+('Decoration Palace');
+`
+
+var INSERT_PRODUCTS = `INSERT INTO products (name, price, discount, store_id) 
+VALUES('AirFryer', 3000.0, 22.0, 1),
+('Iron', 1500.0, 10.0, 1),
+('Washing Machine', 10000.0, 15.0, 1),
+('Floor Lamp', 2000.0, 0.0, 2);
 `
 
 func TestDataInitialize(ctx context.Context, dbPool *pgxpool.Pool) {
+	insertStoresResult, insertStoresErr := dbPool.Exec(ctx, INSERT_STORES)
+	if insertStoresErr != nil {
+		log.Error(insertStoresErr)
+	} else {
+		log.Info(fmt.Sprintf("Stores data created with %d rows", insertStoresResult.RowsAffected()))
+	}
+
 	insertProductsResult, insertProductsErr := dbPool.Exec(ctx, INSERT_PRODUCTS)
 	if insertProductsErr != nil {
 		log.Error(insertProductsErr)
@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	setup(ctx, dbPool)
+	webhookRepository := persistence.NewWebhookRepository(dbPool)
+
+	storeWebhook, _ := webhookRepository.Add(domain.WebhookSubscription{Url: "https://abc.example.com", EventTypes: []string{domain.PRODUCT_PRICE_CHANGED}, StoreId: 1, Secret: "secret"})
+	allStoresWebhook, _ := webhookRepository.Add(domain.WebhookSubscription{Url: "https://all.example.com", EventTypes: []string{domain.PRODUCT_PRICE_CHANGED, domain.PRODUCT_DELETED}, Secret: "secret"})
+
+	t.Run("AddWithUnknownStoreFails", func(t *testing.T) {
+		_, err := webhookRepository.Add(domain.WebhookSubscription{Url: "https://x.example.com", EventTypes: []string{domain.PRODUCT_CREATED}, StoreId: 99, Secret: "secret"})
+		assert.Equal(t, "Store not found with id 99", err.Error())
+	})
+
+	t.Run("EnqueueMatchesEventTypeAndStoreOnce", func(t *testing.T) {
+		enqueued, err := webhookRepository.EnqueueDeliveries(10, domain.PRODUCT_PRICE_CHANGED, 2, []byte(`{"productId":4}`))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), enqueued)
+
+		enqueued, _ = webhookRepository.EnqueueDeliveries(10, domain.PRODUCT_PRICE_CHANGED, 2, []byte(`{"productId":4}`))
+		assert.Equal(t, int64(0), enqueued)
+
+		enqueued, _ = webhookRepository.EnqueueDeliveries(11, domain.PRODUCT_PRICE_CHANGED, 1, []byte(`{"productId":1}`))
+		assert.Equal(t, int64(2), enqueued)
+
+		assert.Equal(t, 1, len(webhookRepository.GetDeliveries(storeWebhook.Id, "", 10)))
+		assert.Equal(t, 2, len(webhookRepository.GetDeliveries(allStoresWebhook.Id, domain.WEBHOOK_PENDING, 10)))
+	})
+
+	t.Run("OutcomesAndRedeliveryAreStored", func(t *testing.T) {
+		processed, err := webhookRepository.ProcessDueDeliveries(10, time.Minute, func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome {
+			var outcomes []domain.WebhookDeliveryOutcome
+			for _, delivery := range deliveries {
+				assert.NotEmpty(t, delivery.Url)
+				outcomes = append(outcomes, domain.WebhookDeliveryOutcome{Id: delivery.Id, Status: domain.WEBHOOK_FAILED, StatusCode: 500,
+					Error: "Webhook answered with status 500", NextAttemptAt: time.Now().Add(time.Hour)})
+			}
+			return outcomes
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, processed)
+
+		processed, _ = webhookRepository.ProcessDueDeliveries(10, time.Minute, func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome { return nil })
+		assert.Equal(t, 0, processed)
+
+		failed := webhookRepository.GetDeliveries(storeWebhook.Id, domain.WEBHOOK_FAILED, 10)[0]
+		assert.Equal(t, 1, failed.Attempts)
+		assert.Equal(t, 500, failed.LastStatusCode)
+		assert.JSONEq(t, `{"productId":1}`, string(failed.Payload))
+
+		assert.Nil(t, webhookRepository.Redeliver(storeWebhook.Id, failed.Id))
+		assert.NotNil(t, webhookRepository.Redeliver(allStoresWebhook.Id, failed.Id))
+
+		processed, _ = webhookRepository.ProcessDueDeliveries(10, time.Minute, func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome {
+			return []domain.WebhookDeliveryOutcome{{Id: deliveries[0].Id, Status: domain.WEBHOOK_SUCCEEDED, StatusCode: 200}}
+		})
+		assert.Equal(t, 1, processed)
+		assert.NotNil(t, webhookRepository.GetDeliveries(storeWebhook.Id, domain.WEBHOOK_SUCCEEDED, 10)[0].DeliveredAt)
+	})
+
+	t.Run("LeasedDeliveriesAreNotClaimedAgain", func(t *testing.T) {
+		webhookRepository.EnqueueDeliveries(12, domain.PRODUCT_DELETED, 1, []byte(`{"productId":2}`))
+
+		processed, _ := webhookRepository.ProcessDueDeliveries(10, time.Minute, func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome {
+			processed, _ := webhookRepository.ProcessDueDeliveries(10, time.Minute, func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome { return nil })
+			assert.Equal(t, 0, processed)
+			return nil
+		})
+
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, 1, len(webhookRepository.GetDeliveries(allStoresWebhook.Id, domain.WEBHOOK_PENDING, 10)))
+	})
+
+	clear(ctx, dbPool)
+}
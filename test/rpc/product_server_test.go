@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"example.com/product-api/api/productpb"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/rpc"
+	"example.com/product-api/service"
+	fakes "example.com/product-api/test/service"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+)
+
+func newProductClientForTest(t *testing.T) (productpb.ProductServiceClient, service.IProductService) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH", Attributes: map[string]interface{}{"wattage": float64(1500)}},
+		{Id: 2, Name: "Ütü", Price: 1500.0, Discount: 10.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	productService := service.NewProductService(fakes.NewFakeProductRepository(products))
+
+	return newClientForTest(t, productService), productService
+}
+
+func newClientForTest(t *testing.T, productService service.IProductService) productpb.ProductServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := rpc.NewServer(productService)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return productpb.NewProductServiceClient(conn)
+}
+
+func Test_ShouldGetProduct(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	product, err := client.GetProduct(context.Background(), &productpb.GetProductRequest{Id: 1})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "AirFryer", product.Name)
+	assert.Equal(t, float32(3000.0), product.Price)
+	assert.Equal(t, float64(1500), product.Attributes.AsMap()["wattage"])
+}
+
+func Test_WhenProductDoesNotExist_ShouldReturnNotFound(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	_, err := client.GetProduct(context.Background(), &productpb.GetProductRequest{Id: 99})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_ShouldStreamProducts(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	stream, err := client.ListProducts(context.Background(), &productpb.ListProductsRequest{})
+	assert.Nil(t, err)
+
+	var names []string
+	for {
+		product, err := stream.Recv()
+
+		if err == io.EOF {
+			break
+		}
+
+		assert.Nil(t, err)
+		names = append(names, product.Name)
+	}
+
+	assert.Equal(t, []string{"AirFryer", "Ütü"}, names)
+}
+
+func Test_WhenTagIsInvalid_ShouldReturnInvalidArgument(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	stream, err := client.ListProducts(context.Background(), &productpb.ListProductsRequest{Tags: []string{"not a tag"}})
+	assert.Nil(t, err)
+
+	_, err = stream.Recv()
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_ShouldCreateProductAndUpdatePrice(t *testing.T) {
+	client, productService := newProductClientForTest(t)
+
+	_, err := client.CreateProduct(context.Background(), &productpb.CreateProductRequest{Name: "Kettle", Price: 500.0, StoreId: 1})
+	assert.Nil(t, err)
+
+	_, err = client.UpdatePrice(context.Background(), &productpb.UpdatePriceRequest{Id: 1, Price: 2500.0})
+	assert.Nil(t, err)
+
+	created, err := productService.GetById(3)
+	assert.Nil(t, err)
+	assert.Equal(t, "Kettle", created.Name)
+
+	product, _ := productService.GetById(1)
+	assert.Equal(t, float32(2500.0), product.Price)
+}
+
+func Test_WhenPriceIsNotPositive_ShouldReturnInvalidArgument(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	_, err := client.UpdatePrice(context.Background(), &productpb.UpdatePriceRequest{Id: 1, Price: 0})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_WhenDeletedProductDoesNotExist_ShouldReturnNotFound(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	_, err := client.DeleteProduct(context.Background(), &productpb.DeleteProductRequest{Id: 99})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_WhenUpdatedProductDoesNotExist_ShouldReturnNotFound(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	_, err := client.UpdatePrice(context.Background(), &productpb.UpdatePriceRequest{Id: 99, Price: 100.0})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_WhenCreatedProductIsInvalid_ShouldReturnInvalidArgument(t *testing.T) {
+	client, productService := newProductClientForTest(t)
+
+	_, err := client.CreateProduct(context.Background(), &productpb.CreateProductRequest{Name: "Kettle", Price: 500.0, Discount: 80.0, StoreId: 1})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 2, len(productService.GetAll()))
+}
+
+func Test_WhenStoreHasProductWithSameName_ShouldReturnAlreadyExists(t *testing.T) {
+	client, _ := newProductClientForTest(t)
+
+	_, err := client.CreateProduct(context.Background(), &productpb.CreateProductRequest{Name: "airfryer", Price: 500.0, StoreId: 1})
+
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+type failingProductRepository struct {
+	persistence.IProductRepository
+}
+
+func (failingProductRepository) GetById(productId int64) (domain.Product, error) {
+	return domain.Product{}, errors.New("Error while getting product with id 1")
+}
+
+func (failingProductRepository) DeleteById(productId int64) error {
+	return errors.New("Error while deleting product with id 1")
+}
+
+func Test_WhenDatabaseFails_ShouldReturnInternal(t *testing.T) {
+	client := newClientForTest(t, service.NewProductService(failingProductRepository{}))
+
+	_, err := client.GetProduct(context.Background(), &productpb.GetProductRequest{Id: 1})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = client.DeleteProduct(context.Background(), &productpb.DeleteProductRequest{Id: 1})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var airFryerSchema = []byte(`{
+	"type": "object",
+	"properties": {"wattage": {"type": "number", "minimum": 500}},
+	"required": ["wattage"]
+}`)
+
+func newAttributeServicesForTest() (service.IAttributeService, service.ICategoryService) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH",
+			Attributes: map[string]interface{}{"wattage": float64(1500)}},
+		{Id: 2, Name: "Floor Lamp", Price: 2000.0, Discount: 0.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	categories := []domain.Category{
+		{Id: 1, Name: "AirFryers", AttributeSchema: airFryerSchema},
+	}
+
+	productRepository := NewFakeProductRepository(products)
+	categoryRepository := NewFakeCategoryRepository(categories)
+
+	return service.NewAttributeService(productRepository, categoryRepository),
+		service.NewCategoryService(categoryRepository, productRepository)
+}
+
+func Test_WhenAttributesMatchCategorySchema_ShouldUpdateAttributes(t *testing.T) {
+	attributeService, categoryService := newAttributeServicesForTest()
+	categoryService.AddProduct(1, 1)
+
+	err := attributeService.UpdateAttributes(1, map[string]interface{}{"wattage": float64(1800), "color": "black"})
+
+	assert.Nil(t, err)
+}
+
+func Test_WhenAttributesBreakCategorySchema_ShouldNotUpdateAttributes(t *testing.T) {
+	attributeService, categoryService := newAttributeServicesForTest()
+	categoryService.AddProduct(1, 1)
+
+	err := attributeService.UpdateAttributes(1, map[string]interface{}{"wattage": float64(100)})
+
+	assert.Equal(t, "Attributes do not match the schema of category AirFryers: wattage: must be >= 500 but found 100", err.Error())
+}
+
+func Test_WhenProductAttributesBreakCategorySchema_ShouldNotLinkProduct(t *testing.T) {
+	_, categoryService := newAttributeServicesForTest()
+
+	err := categoryService.AddProduct(1, 2)
+
+	assert.Equal(t, "Attributes do not match the schema of category AirFryers: missing properties: 'wattage'", err.Error())
+}
+
+func Test_WhenAttributeKeyIsNotFilterable_ShouldNotUpdateAttributes(t *testing.T) {
+	attributeService, _ := newAttributeServicesForTest()
+
+	err := attributeService.UpdateAttributes(2, map[string]interface{}{"height (cm)": float64(150)})
+
+	assert.Equal(t, "Attribute key height (cm) may only contain letters, digits and underscores", err.Error())
+}
+
+func Test_WhenAttributeSchemaIsInvalid_ShouldNotAddCategory(t *testing.T) {
+	_, categoryService := newAttributeServicesForTest()
+
+	err := categoryService.Add(dto.CategoryCreate{Name: "Lamps", AttributeSchema: []byte(`{"type": "nope"}`)})
+
+	assert.Contains(t, err.Error(), "Attribute schema of category Lamps is invalid")
+}
+
+func Test_WhenCategoryNameIsNoValidUrl_ShouldStillCompileSchema(t *testing.T) {
+	attributeService, categoryService := newAttributeServicesForTest()
+
+	err := categoryService.Add(dto.CategoryCreate{Name: "Kitchen/Air#Fryers", AttributeSchema: airFryerSchema})
+	assert.Nil(t, err)
+
+	categories := categoryService.GetAll()
+	assert.Nil(t, categoryService.AddProduct(categories[len(categories)-1].Id, 1))
+
+	err = attributeService.UpdateAttributes(1, map[string]interface{}{"wattage": float64(100)})
+	assert.Equal(t, "Attributes do not match the schema of category Kitchen/Air#Fryers: wattage: must be >= 500 but found 100", err.Error())
+}
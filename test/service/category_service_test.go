@@ -0,0 +1,62 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newCategoryServiceForTest() service.ICategoryService {
+	categories := []domain.Category{
+		{Id: 1, Name: "Electronics"},
+		{Id: 2, Name: "Kitchen", ParentId: 1},
+		{Id: 3, Name: "AirFryers", ParentId: 2},
+	}
+
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	return service.NewCategoryService(NewFakeCategoryRepository(categories), NewFakeProductRepository(products))
+}
+
+func Test_WhenParentIsADescendant_ShouldNotMoveCategory(t *testing.T) {
+	categoryService := newCategoryServiceForTest()
+
+	err := categoryService.Update(1, dto.CategoryCreate{Name: "Electronics", ParentId: 3})
+
+	assert.Equal(t, "Category 3 is a descendant of category 1", err.Error())
+}
+
+func Test_WhenParentIsItself_ShouldNotMoveCategory(t *testing.T) {
+	categoryService := newCategoryServiceForTest()
+
+	err := categoryService.Update(2, dto.CategoryCreate{Name: "Kitchen", ParentId: 2})
+
+	assert.Equal(t, "Category can not be its own parent", err.Error())
+}
+
+func Test_WhenParentDoesNotExist_ShouldNotAddCategory(t *testing.T) {
+	categoryService := newCategoryServiceForTest()
+
+	err := categoryService.Add(dto.CategoryCreate{Name: "Lamps", ParentId: 9})
+
+	assert.Equal(t, "Parent category not found with id 9", err.Error())
+	assert.Equal(t, 3, len(categoryService.GetAll()))
+}
+
+func Test_ShouldLinkProductToCategory(t *testing.T) {
+	categoryService := newCategoryServiceForTest()
+
+	err := categoryService.AddProduct(3, 1)
+	assert.Nil(t, err)
+
+	categories, err := categoryService.GetAllByProductId(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Category{{Id: 3, Name: "AirFryers", ParentId: 2}}, categories)
+
+	err = categoryService.AddProduct(3, 7)
+	assert.Equal(t, "Product not found with id 7", err.Error())
+}
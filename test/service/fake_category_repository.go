@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+)
+
+type FakeCategoryRepository struct {
+	categories        []domain.Category
+	productCategories map[int64][]int64
+}
+
+func NewFakeCategoryRepository(initialCategories []domain.Category) persistence.ICategoryRepository {
+	return &FakeCategoryRepository{
+		categories:        initialCategories,
+		productCategories: map[int64][]int64{},
+	}
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) GetAll() []domain.Category {
+	return fakeCategoryRepository.categories
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) GetById(categoryId int64) (domain.Category, error) {
+	for _, category := range fakeCategoryRepository.categories {
+		if category.Id == categoryId {
+			return category, nil
+		}
+	}
+
+	return domain.Category{}, errors.New(fmt.Sprintf("Category not found with id %d", categoryId))
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) GetChildren(categoryId int64) []domain.Category {
+	children := make([]domain.Category, 0)
+
+	for _, category := range fakeCategoryRepository.categories {
+		if category.ParentId == categoryId {
+			children = append(children, category)
+		}
+	}
+
+	return children
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) GetAllByProductId(productId int64) []domain.Category {
+	categories := make([]domain.Category, 0)
+
+	for _, categoryId := range fakeCategoryRepository.productCategories[productId] {
+		category, _ := fakeCategoryRepository.GetById(categoryId)
+		categories = append(categories, category)
+	}
+
+	return categories
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) IsDescendant(categoryId int64, ancestorId int64) bool {
+	for _, child := range fakeCategoryRepository.GetChildren(ancestorId) {
+		if child.Id == categoryId || fakeCategoryRepository.IsDescendant(categoryId, child.Id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) Add(category domain.Category) error {
+	category.Id = int64(len(fakeCategoryRepository.categories)) + 1
+	fakeCategoryRepository.categories = append(fakeCategoryRepository.categories, category)
+
+	return nil
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) Update(category domain.Category) error {
+	for i, existing := range fakeCategoryRepository.categories {
+		if existing.Id == category.Id {
+			fakeCategoryRepository.categories[i] = category
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Category not found with id %d", category.Id))
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) DeleteById(categoryId int64) error {
+	if len(fakeCategoryRepository.GetChildren(categoryId)) > 0 {
+		return errors.New(fmt.Sprintf("Category %d still has child categories", categoryId))
+	}
+
+	for i, category := range fakeCategoryRepository.categories {
+		if category.Id == categoryId {
+			fakeCategoryRepository.categories = append(fakeCategoryRepository.categories[:i], fakeCategoryRepository.categories[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Category not found with id %d", categoryId))
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) AddProduct(categoryId int64, productId int64) error {
+	fakeCategoryRepository.productCategories[productId] = append(fakeCategoryRepository.productCategories[productId], categoryId)
+
+	return nil
+}
+
+func (fakeCategoryRepository *FakeCategoryRepository) RemoveProduct(categoryId int64, productId int64) error {
+	for i, linkedId := range fakeCategoryRepository.productCategories[productId] {
+		if linkedId == categoryId {
+			linked := fakeCategoryRepository.productCategories[productId]
+			fakeCategoryRepository.productCategories[productId] = append(linked[:i], linked[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Product %d is not in category %d", productId, categoryId))
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"sync"
+	"time"
+)
+
+// FakeIdempotencyRepository keeps the idempotency keys in memory, guarded by
+// a mutex as concurrent requests are what it is about.
+type FakeIdempotencyRepository struct {
+	mutex   sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func NewFakeIdempotencyRepository() persistence.IIdempotencyRepository {
+	return &FakeIdempotencyRepository{records: map[string]domain.IdempotencyRecord{}}
+}
+
+func (fakeIdempotencyRepository *FakeIdempotencyRepository) Claim(key string, route string, requestHash string, window time.Duration, inFlightTimeout time.Duration) (domain.IdempotencyRecord, bool, error) {
+	fakeIdempotencyRepository.mutex.Lock()
+	defer fakeIdempotencyRepository.mutex.Unlock()
+
+	now := time.Now()
+	existing, found := fakeIdempotencyRepository.records[key+" "+route]
+
+	if found && existing.ExpiresAt.After(now) && (existing.Response != nil || existing.CreatedAt.Add(inFlightTimeout).After(now)) {
+		return existing, false, nil
+	}
+
+	record := domain.IdempotencyRecord{Key: key, Route: route, RequestHash: requestHash, CreatedAt: now, ExpiresAt: now.Add(window)}
+	fakeIdempotencyRepository.records[key+" "+route] = record
+
+	return record, true, nil
+}
+
+func (fakeIdempotencyRepository *FakeIdempotencyRepository) Complete(claim domain.IdempotencyRecord, response domain.StoredResponse) error {
+	fakeIdempotencyRepository.mutex.Lock()
+	defer fakeIdempotencyRepository.mutex.Unlock()
+
+	record, found := fakeIdempotencyRepository.records[claim.Key+" "+claim.Route]
+
+	if found && record.CreatedAt.Equal(claim.CreatedAt) && record.Response == nil {
+		record.Response = &response
+		fakeIdempotencyRepository.records[claim.Key+" "+claim.Route] = record
+	}
+
+	return nil
+}
+
+func (fakeIdempotencyRepository *FakeIdempotencyRepository) Release(claim domain.IdempotencyRecord) error {
+	fakeIdempotencyRepository.mutex.Lock()
+	defer fakeIdempotencyRepository.mutex.Unlock()
+
+	record, found := fakeIdempotencyRepository.records[claim.Key+" "+claim.Route]
+
+	if found && record.CreatedAt.Equal(claim.CreatedAt) && record.Response == nil {
+		delete(fakeIdempotencyRepository.records, claim.Key+" "+claim.Route)
+	}
+
+	return nil
+}
+
+func (fakeIdempotencyRepository *FakeIdempotencyRepository) PurgeExpired() (int64, error) {
+	fakeIdempotencyRepository.mutex.Lock()
+	defer fakeIdempotencyRepository.mutex.Unlock()
+
+	purged := int64(0)
+
+	for key, record := range fakeIdempotencyRepository.records {
+		if !record.ExpiresAt.After(time.Now()) {
+			delete(fakeIdempotencyRepository.records, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+	"sync"
+)
+
+type FakeInventoryRepository struct {
+	mutex       sync.Mutex
+	inventories []domain.Inventory
+}
+
+func NewFakeInventoryRepository(initialInventories []domain.Inventory) persistence.IInventoryRepository {
+	return &FakeInventoryRepository{
+		inventories: initialInventories,
+	}
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) GetAllByProductId(productId int64) []domain.Inventory {
+	fakeInventoryRepository.mutex.Lock()
+	defer fakeInventoryRepository.mutex.Unlock()
+
+	inventories := make([]domain.Inventory, 0)
+
+	for _, inventory := range fakeInventoryRepository.inventories {
+		if inventory.ProductId == productId {
+			inventories = append(inventories, inventory)
+		}
+	}
+
+	return inventories
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) Get(productId int64, storeId int64) (domain.Inventory, error) {
+	fakeInventoryRepository.mutex.Lock()
+	defer fakeInventoryRepository.mutex.Unlock()
+
+	index := fakeInventoryRepository.indexOf(productId, storeId)
+
+	if index < 0 {
+		return domain.Inventory{}, errors.New(fmt.Sprintf("Inventory not found for product %d in store %d", productId, storeId))
+	}
+
+	return fakeInventoryRepository.inventories[index], nil
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) Adjust(productId int64, storeId int64, delta int32) (bool, error) {
+	fakeInventoryRepository.mutex.Lock()
+	defer fakeInventoryRepository.mutex.Unlock()
+
+	index := fakeInventoryRepository.indexOf(productId, storeId)
+
+	if index < 0 && delta > 0 {
+		fakeInventoryRepository.inventories = append(fakeInventoryRepository.inventories, domain.Inventory{ProductId: productId, StoreId: storeId, OnHand: delta})
+		return true, nil
+	}
+
+	if index < 0 || fakeInventoryRepository.inventories[index].OnHand+delta < fakeInventoryRepository.inventories[index].Reserved {
+		return false, nil
+	}
+
+	fakeInventoryRepository.inventories[index].OnHand += delta
+	return true, nil
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) Reserve(productId int64, storeId int64, quantity int32) (bool, error) {
+	fakeInventoryRepository.mutex.Lock()
+	defer fakeInventoryRepository.mutex.Unlock()
+
+	index := fakeInventoryRepository.indexOf(productId, storeId)
+
+	if index < 0 || fakeInventoryRepository.inventories[index].Available() < quantity {
+		return false, nil
+	}
+
+	fakeInventoryRepository.inventories[index].Reserved += quantity
+	return true, nil
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) Release(productId int64, storeId int64, quantity int32) (bool, error) {
+	fakeInventoryRepository.mutex.Lock()
+	defer fakeInventoryRepository.mutex.Unlock()
+
+	index := fakeInventoryRepository.indexOf(productId, storeId)
+
+	if index < 0 || fakeInventoryRepository.inventories[index].Reserved < quantity {
+		return false, nil
+	}
+
+	fakeInventoryRepository.inventories[index].Reserved -= quantity
+	return true, nil
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) Commit(productId int64, storeId int64, quantity int32) (bool, error) {
+	fakeInventoryRepository.mutex.Lock()
+	defer fakeInventoryRepository.mutex.Unlock()
+
+	index := fakeInventoryRepository.indexOf(productId, storeId)
+
+	if index < 0 || fakeInventoryRepository.inventories[index].Reserved < quantity {
+		return false, nil
+	}
+
+	fakeInventoryRepository.inventories[index].Reserved -= quantity
+	fakeInventoryRepository.inventories[index].OnHand -= quantity
+	return true, nil
+}
+
+func (fakeInventoryRepository *FakeInventoryRepository) indexOf(productId int64, storeId int64) int {
+	for i, inventory := range fakeInventoryRepository.inventories {
+		if inventory.ProductId == productId && inventory.StoreId == storeId {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"time"
+)
+
+type fakeOutboxEntry struct {
+	message       domain.OutboxMessage
+	nextAttemptAt time.Time
+	lastError     string
+	deadLettered  bool
+	deliveredAt   time.Time
+}
+
+// FakeOutboxRepository keeps the outbox in memory. Unlike the other fakes it
+// is returned as is, so tests can inspect what the relay left behind.
+type FakeOutboxRepository struct {
+	entries []*fakeOutboxEntry
+}
+
+func NewFakeOutboxRepository(events ...domain.ProductEvent) *FakeOutboxRepository {
+	fakeOutboxRepository := &FakeOutboxRepository{}
+
+	for i, event := range events {
+		fakeOutboxRepository.entries = append(fakeOutboxRepository.entries,
+			&fakeOutboxEntry{message: domain.OutboxMessage{Id: int64(i + 1), Event: event}})
+	}
+
+	return fakeOutboxRepository
+}
+
+// ProcessBatch leases the messages it hands to process like the real
+// repository, so a message without an outcome waits for its lease to run out.
+func (fakeOutboxRepository *FakeOutboxRepository) ProcessBatch(limit int, lease time.Duration, process func(messages []domain.OutboxMessage) []domain.OutboxOutcome) (int, error) {
+	var messages []domain.OutboxMessage
+
+	for _, entry := range fakeOutboxRepository.entries {
+		if len(messages) < limit && !entry.deadLettered && entry.deliveredAt.IsZero() && !entry.nextAttemptAt.After(time.Now()) {
+			messages = append(messages, entry.message)
+		}
+	}
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	for _, message := range messages {
+		fakeOutboxRepository.entry(message.Id).nextAttemptAt = time.Now().Add(lease)
+	}
+
+	for _, outcome := range process(messages) {
+		for _, entry := range fakeOutboxRepository.entries {
+			if entry.message.Id != outcome.Id {
+				continue
+			}
+
+			if outcome.Delivered {
+				entry.deliveredAt = time.Now()
+				break
+			}
+
+			entry.message.Attempts++
+			entry.message.DeliveredSinks = outcome.DeliveredSinks
+			entry.lastError = outcome.Error
+			entry.nextAttemptAt = outcome.NextAttemptAt
+			entry.deadLettered = outcome.DeadLetter
+			break
+		}
+	}
+
+	return len(messages), nil
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) GetLastId() (int64, error) {
+	var lastId int64
+
+	for _, entry := range fakeOutboxRepository.entries {
+		if entry.message.Id > lastId {
+			lastId = entry.message.Id
+		}
+	}
+
+	return lastId, nil
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) GetAfter(afterId int64, limit int) ([]domain.ProductEvent, error) {
+	events := []domain.ProductEvent{}
+
+	for _, entry := range fakeOutboxRepository.entries {
+		if entry.message.Id > afterId {
+			event := entry.message.Event
+			event.Id = uint64(entry.message.Id)
+			events = append(events, event)
+		}
+	}
+
+	if len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+
+	return events, nil
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) DeleteDeliveredBefore(cutoff time.Time) (int64, error) {
+	kept := fakeOutboxRepository.entries[:0]
+
+	for _, entry := range fakeOutboxRepository.entries {
+		if entry.deliveredAt.IsZero() || !entry.deliveredAt.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+
+	purged := int64(len(fakeOutboxRepository.entries) - len(kept))
+	fakeOutboxRepository.entries = kept
+
+	return purged, nil
+}
+
+// Add writes an event to the outbox as another instance would.
+func (fakeOutboxRepository *FakeOutboxRepository) Add(event domain.ProductEvent) int64 {
+	lastId, _ := fakeOutboxRepository.GetLastId()
+	id := lastId + 1
+	fakeOutboxRepository.entries = append(fakeOutboxRepository.entries, &fakeOutboxEntry{message: domain.OutboxMessage{Id: id, Event: event}})
+
+	return id
+}
+
+// Pending returns the messages still waiting for delivery, dead lettered or
+// not.
+func (fakeOutboxRepository *FakeOutboxRepository) Pending() []domain.OutboxMessage {
+	var messages []domain.OutboxMessage
+
+	for _, entry := range fakeOutboxRepository.entries {
+		if entry.deliveredAt.IsZero() {
+			messages = append(messages, entry.message)
+		}
+	}
+
+	return messages
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) NextAttemptAt(id int64) time.Time {
+	return fakeOutboxRepository.entry(id).nextAttemptAt
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) LastError(id int64) string {
+	return fakeOutboxRepository.entry(id).lastError
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) IsDeadLettered(id int64) bool {
+	return fakeOutboxRepository.entry(id).deadLettered
+}
+
+// MakeDue moves every retry to now, as if the backoff had passed.
+func (fakeOutboxRepository *FakeOutboxRepository) MakeDue() {
+	for _, entry := range fakeOutboxRepository.entries {
+		entry.nextAttemptAt = time.Time{}
+	}
+}
+
+func (fakeOutboxRepository *FakeOutboxRepository) entry(id int64) *fakeOutboxEntry {
+	for _, entry := range fakeOutboxRepository.entries {
+		if entry.message.Id == id {
+			return entry
+		}
+	}
+	return &fakeOutboxEntry{}
+}
@@ -5,10 +5,16 @@ import (
 	"example.com/product-api/domain"
 	"example.com/product-api/persistence"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 )
 
 type FakeProductRepository struct {
-	products []domain.Product
+	products        []domain.Product
+	deletedProducts []domain.DeletedProduct
 }
 
 func NewFakeProductRepository(initialProducts []domain.Product) persistence.IProductRepository {
@@ -28,14 +34,14 @@ func (fakeProductRepository *FakeProductRepository) GetById(productId int64) (do
 		}
 	}
 
-	return domain.Product{}, errors.New(fmt.Sprintf("Product not found with id %d", productId))
+	return domain.Product{}, fmt.Errorf("%w with id %d", persistence.ErrProductNotFound, productId)
 }
 
 func (fakeProductRepository *FakeProductRepository) GetAllByStore(storeName string) []domain.Product {
 	products := make([]domain.Product, 0)
 
 	for _, product := range fakeProductRepository.products {
-		if product.Store == storeName {
+		if strings.EqualFold(product.Store, domain.NormalizeStoreName(storeName)) {
 			products = append(products, product)
 		}
 	}
@@ -43,18 +49,233 @@ func (fakeProductRepository *FakeProductRepository) GetAllByStore(storeName stri
 	return products
 }
 
-func (fakeProductRepository *FakeProductRepository) Add(product domain.Product) error {
-	fakeProductRepository.products = append(fakeProductRepository.products, domain.Product{
-		Id:       int64(len(fakeProductRepository.products)) + 1,
-		Name:     product.Name,
-		Price:    product.Price,
-		Discount: product.Discount,
-		Store:    product.Store,
-	})
+func (fakeProductRepository *FakeProductRepository) GetAllByStoreId(storeId int64) []domain.Product {
+	products := make([]domain.Product, 0)
+
+	for _, product := range fakeProductRepository.products {
+		if product.StoreId == storeId {
+			products = append(products, product)
+		}
+	}
+
+	return products
+}
+
+// GetAllByFilter only understands the store filter; the fake keeps no
+// category links.
+func (fakeProductRepository *FakeProductRepository) GetAllByFilter(filter domain.ProductFilter) []domain.Product {
+	if len(filter.Store) == 0 {
+		return fakeProductRepository.products
+	}
+
+	return fakeProductRepository.GetAllByStore(filter.Store)
+}
+
+func (fakeProductRepository *FakeProductRepository) GetPageByFilter(filter domain.ProductFilter, afterId int64, limit int) []domain.Product {
+	products := make([]domain.Product, 0)
+
+	for _, product := range fakeProductRepository.GetAllByFilter(filter) {
+		if product.Id > afterId && len(products) < limit {
+			products = append(products, product)
+		}
+	}
+
+	return products
+}
+
+func (fakeProductRepository *FakeProductRepository) StreamAllByFilter(filter domain.ProductFilter, consume func(domain.Product) error) error {
+	for _, product := range fakeProductRepository.GetAllByFilter(filter) {
+		if err := consume(product); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// Search ranks nothing; it returns the products whose name contains the query.
+func (fakeProductRepository *FakeProductRepository) Search(query string, filter domain.ProductFilter, limit int) []domain.ProductSearchResult {
+	results := make([]domain.ProductSearchResult, 0)
+
+	for _, product := range fakeProductRepository.GetAllByFilter(filter) {
+		if len(results) < limit && strings.Contains(strings.ToLower(product.Name), strings.ToLower(query)) {
+			results = append(results, domain.ProductSearchResult{Product: product, Rank: 1, Highlight: product.Name})
+		}
+	}
+
+	return results
+}
+
+// GetFacets only counts stores; the fake keeps no tags.
+func (fakeProductRepository *FakeProductRepository) GetFacets(filter domain.ProductFilter) domain.ProductFacets {
+	facets := domain.ProductFacets{Tags: []domain.FacetCount{}, Stores: []domain.FacetCount{}}
+
+	for _, product := range fakeProductRepository.GetAllByFilter(filter) {
+		found := false
+		for i := range facets.Stores {
+			if facets.Stores[i].Value == product.Store {
+				facets.Stores[i].Count++
+				found = true
+			}
+		}
+		if !found {
+			facets.Stores = append(facets.Stores, domain.FacetCount{Value: product.Store, Count: 1})
+		}
+	}
+
+	return facets
+}
+
+// FindDuplicates compares the names by their trigrams the way pg_trgm does.
+func (fakeProductRepository *FakeProductRepository) FindDuplicates(storeName string, threshold float32, limit int) []domain.ProductDuplicate {
+	duplicates := make([]domain.ProductDuplicate, 0)
+	products := fakeProductRepository.products
+
+	if len(storeName) > 0 {
+		products = fakeProductRepository.GetAllByStore(storeName)
+	}
+
+	for i, product := range products {
+		for _, other := range products[i+1:] {
+			if other.StoreId != product.StoreId {
+				continue
+			}
+
+			if similarity := trigramSimilarity(product.Name, other.Name); similarity >= threshold {
+				duplicates = append(duplicates, domain.ProductDuplicate{Product: product, Duplicate: other, Similarity: similarity})
+			}
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+
+	if len(duplicates) > limit {
+		duplicates = duplicates[:limit]
+	}
+
+	return duplicates
+}
+
+func trigramSimilarity(a string, b string) float32 {
+	trigramsA, trigramsB := trigrams(a), trigrams(b)
+	shared := 0
+
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			shared++
+		}
+	}
+
+	if all := len(trigramsA) + len(trigramsB) - shared; all > 0 {
+		return float32(shared) / float32(all)
+	}
+
+	return 0
+}
+
+func trigrams(text string) map[string]bool {
+	trigrams := map[string]bool{}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams[string(padded[i:i+3])] = true
+		}
+	}
+
+	return trigrams
+}
+
+// Add fills in the store from the products already in that store, the way
+// the real repository joins it, and refuses a second product with the same
+// name in a store.
+func (fakeProductRepository *FakeProductRepository) Add(product domain.Product) (domain.Product, error) {
+	product = fakeProductRepository.withStore(product)
+
+	for _, existing := range fakeProductRepository.products {
+		if existing.StoreId == product.StoreId && strings.EqualFold(domain.NormalizeStoreName(existing.Name), domain.NormalizeStoreName(product.Name)) {
+			return domain.Product{}, persistence.ErrDuplicateProduct
+		}
+	}
+
+	product.Id = int64(len(fakeProductRepository.products)) + 1
+	fakeProductRepository.products = append(fakeProductRepository.products, product)
+
+	return product, nil
+}
+
+func (fakeProductRepository *FakeProductRepository) withStore(product domain.Product) domain.Product {
+	for _, existing := range fakeProductRepository.products {
+		if product.StoreId != 0 && existing.StoreId == product.StoreId {
+			product.Store = existing.Store
+		} else if product.StoreId == 0 && strings.EqualFold(existing.Store, domain.NormalizeStoreName(product.Store)) {
+			product.StoreId = existing.StoreId
+		}
+	}
+
+	return product
+}
+
+// UpsertByExternalRef updates the product with the reference in place, or
+// adds it when there is none. Unlike the real repository it ignores the trash.
+func (fakeProductRepository *FakeProductRepository) UpsertByExternalRef(product domain.Product) (domain.ProductUpsert, error) {
+	product = fakeProductRepository.withStore(product)
+
+	for i, existing := range fakeProductRepository.products {
+		if existing.ExternalRef != product.ExternalRef {
+			continue
+		}
+
+		if existing.Name == product.Name && existing.Price == product.Price && existing.Discount == product.Discount &&
+			existing.StoreId == product.StoreId && reflect.DeepEqual(existing.Attributes, product.Attributes) && !existing.Discontinued {
+			return domain.ProductUpsert{Product: existing, Outcome: domain.UPSERT_UNCHANGED}, nil
+		}
+
+		product.Id, product.Version = existing.Id, existing.Version+1
+		fakeProductRepository.products[i] = product
+
+		return domain.ProductUpsert{Product: product, Outcome: domain.UPSERT_UPDATED}, nil
+	}
+
+	added, err := fakeProductRepository.Add(product)
+
+	return domain.ProductUpsert{Product: added, Outcome: domain.UPSERT_CREATED}, err
+}
+
+// SyncByExternalRef restores the previous products when any upsert fails.
+func (fakeProductRepository *FakeProductRepository) SyncByExternalRef(products []domain.Product, full bool) (domain.ProductSyncReport, error) {
+	previousProducts := append([]domain.Product{}, fakeProductRepository.products...)
+	report := domain.ProductSyncReport{Discontinued: []domain.Product{}}
+	refs := map[string]bool{}
+
+	for _, product := range products {
+		upsert, err := fakeProductRepository.UpsertByExternalRef(product)
+
+		if err != nil {
+			fakeProductRepository.products = previousProducts
+			return domain.ProductSyncReport{}, errors.New(fmt.Sprintf("Product %s: %s", product.ExternalRef, err.Error()))
+		}
+
+		report.Upserts = append(report.Upserts, upsert)
+		refs[product.ExternalRef] = true
+	}
+
+	for i, product := range fakeProductRepository.products {
+		if full && len(product.ExternalRef) > 0 && !refs[product.ExternalRef] && !product.Discontinued {
+			fakeProductRepository.products[i].Discontinued = true
+			report.Discontinued = append(report.Discontinued, fakeProductRepository.products[i])
+		}
+	}
+
+	return report, nil
+}
+
 func (fakeProductRepository *FakeProductRepository) UpdatePrice(productId int64, newPrice float32) error {
 	for i, product := range fakeProductRepository.products {
 		if product.Id == productId {
@@ -63,17 +284,111 @@ func (fakeProductRepository *FakeProductRepository) UpdatePrice(productId int64,
 		}
 	}
 
-	return errors.New("product not found")
+	return fmt.Errorf("%w with id %d", persistence.ErrProductNotFound, productId)
+}
+
+func (fakeProductRepository *FakeProductRepository) UpdateAttributes(productId int64, attributes map[string]interface{}) error {
+	for i, product := range fakeProductRepository.products {
+		if product.Id == productId {
+			fakeProductRepository.products[i].Attributes = attributes
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w with id %d", persistence.ErrProductNotFound, productId)
 }
 
 func (fakeProductRepository *FakeProductRepository) DeleteById(productId int64) error {
 	for i, product := range fakeProductRepository.products {
 		if product.Id == productId {
-			// Remove product by index
+			// Move product to the trash by index
 			fakeProductRepository.products = append(fakeProductRepository.products[:i], fakeProductRepository.products[i+1:]...)
+			fakeProductRepository.deletedProducts = append(fakeProductRepository.deletedProducts, domain.DeletedProduct{Product: product, DeletedAt: time.Now()})
 			return nil
 		}
 	}
 
-	return errors.New("product not found")
+	return fmt.Errorf("%w with id %d", persistence.ErrProductNotFound, productId)
+}
+
+func (fakeProductRepository *FakeProductRepository) GetAllDeleted() []domain.DeletedProduct {
+	return append([]domain.DeletedProduct{}, fakeProductRepository.deletedProducts...)
+}
+
+func (fakeProductRepository *FakeProductRepository) Restore(productId int64) error {
+	for i, deletedProduct := range fakeProductRepository.deletedProducts {
+		if deletedProduct.Id == productId {
+			fakeProductRepository.deletedProducts = append(fakeProductRepository.deletedProducts[:i], fakeProductRepository.deletedProducts[i+1:]...)
+			fakeProductRepository.products = append(fakeProductRepository.products, deletedProduct.Product)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Deleted product not found with id %d", productId))
+}
+
+func (fakeProductRepository *FakeProductRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	var remainingProducts []domain.DeletedProduct
+
+	for _, deletedProduct := range fakeProductRepository.deletedProducts {
+		if !deletedProduct.DeletedAt.Before(cutoff) {
+			remainingProducts = append(remainingProducts, deletedProduct)
+		}
+	}
+
+	purged := int64(len(fakeProductRepository.deletedProducts) - len(remainingProducts))
+	fakeProductRepository.deletedProducts = remainingProducts
+
+	return purged, nil
+}
+
+// ApplyBulk applies the operations one by one; in atomic mode it restores the
+// previous products when any of them fails.
+func (fakeProductRepository *FakeProductRepository) ApplyBulk(operations []domain.BulkOperation, atomic bool) ([]domain.BulkResult, error) {
+	products := append([]domain.Product{}, fakeProductRepository.products...)
+	deletedProducts := append([]domain.DeletedProduct{}, fakeProductRepository.deletedProducts...)
+	results := make([]domain.BulkResult, 0, len(operations))
+	failed := false
+
+	for _, operation := range operations {
+		result := domain.BulkResult{Action: operation.Action, Index: operation.Index, Id: operation.Product.Id}
+		var err error
+
+		switch operation.Action {
+		case domain.BULK_CREATE:
+			var product domain.Product
+			product, err = fakeProductRepository.Add(operation.Product)
+			result.Id, result.StoreId, result.Store = product.Id, product.StoreId, product.Store
+		case domain.BULK_UPDATE:
+			var product domain.Product
+			product, err = fakeProductRepository.GetById(operation.Product.Id)
+			if err == nil {
+				result.StoreId, result.Store = product.StoreId, product.Store
+				err = fakeProductRepository.UpdatePrice(operation.Product.Id, operation.Product.Price)
+			}
+		case domain.BULK_DELETE:
+			var product domain.Product
+			product, err = fakeProductRepository.GetById(operation.Product.Id)
+			if err == nil {
+				result.StoreId, result.Store = product.StoreId, product.Store
+				err = fakeProductRepository.DeleteById(operation.Product.Id)
+			}
+		}
+
+		if err != nil {
+			result.Id, result.StoreId, result.Store = 0, 0, ""
+			result.Error = err.Error()
+			failed = true
+		}
+
+		results = append(results, result)
+	}
+
+	if atomic && failed {
+		fakeProductRepository.products = products
+		fakeProductRepository.deletedProducts = deletedProducts
+		return results, errors.New("Bulk operations rolled back")
+	}
+
+	return results, nil
 }
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+)
+
+type FakePromotionRepository struct {
+	promotions []domain.Promotion
+}
+
+func NewFakePromotionRepository(initialPromotions []domain.Promotion) persistence.IPromotionRepository {
+	return &FakePromotionRepository{
+		promotions: initialPromotions,
+	}
+}
+
+func (fakePromotionRepository *FakePromotionRepository) GetAll() []domain.Promotion {
+	return fakePromotionRepository.promotions
+}
+
+func (fakePromotionRepository *FakePromotionRepository) GetById(promotionId int64) (domain.Promotion, error) {
+	for _, promotion := range fakePromotionRepository.promotions {
+		if promotion.Id == promotionId {
+			return promotion, nil
+		}
+	}
+
+	return domain.Promotion{}, errors.New(fmt.Sprintf("Promotion not found with id %d", promotionId))
+}
+
+func (fakePromotionRepository *FakePromotionRepository) GetByCode(code string) (domain.Promotion, error) {
+	for _, promotion := range fakePromotionRepository.promotions {
+		if promotion.Code == code {
+			return promotion, nil
+		}
+	}
+
+	return domain.Promotion{}, errors.New(fmt.Sprintf("Promotion not found with code %s", code))
+}
+
+func (fakePromotionRepository *FakePromotionRepository) Add(promotion domain.Promotion) error {
+	promotion.Id = int64(len(fakePromotionRepository.promotions)) + 1
+	fakePromotionRepository.promotions = append(fakePromotionRepository.promotions, promotion)
+
+	return nil
+}
+
+func (fakePromotionRepository *FakePromotionRepository) Update(promotion domain.Promotion) error {
+	for i, existing := range fakePromotionRepository.promotions {
+		if existing.Id == promotion.Id {
+			promotion.UsageCount = existing.UsageCount
+			fakePromotionRepository.promotions[i] = promotion
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Promotion not found with id %d", promotion.Id))
+}
+
+func (fakePromotionRepository *FakePromotionRepository) DeleteById(promotionId int64) error {
+	for i, promotion := range fakePromotionRepository.promotions {
+		if promotion.Id == promotionId {
+			fakePromotionRepository.promotions = append(fakePromotionRepository.promotions[:i], fakePromotionRepository.promotions[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Promotion not found with id %d", promotionId))
+}
+
+func (fakePromotionRepository *FakePromotionRepository) Redeem(promotionId int64) error {
+	for i, promotion := range fakePromotionRepository.promotions {
+		if promotion.Id == promotionId {
+			if promotion.UsageLimit > 0 && promotion.UsageCount >= promotion.UsageLimit {
+				return persistence.ErrPromotionExhausted
+			}
+			fakePromotionRepository.promotions[i].UsageCount++
+			return nil
+		}
+	}
+
+	return persistence.ErrPromotionExhausted
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+	"strings"
+)
+
+type FakeStoreRepository struct {
+	stores []domain.Store
+}
+
+func NewFakeStoreRepository(initialStores []domain.Store) persistence.IStoreRepository {
+	return &FakeStoreRepository{
+		stores: initialStores,
+	}
+}
+
+func (fakeStoreRepository *FakeStoreRepository) GetAll() []domain.Store {
+	return fakeStoreRepository.stores
+}
+
+func (fakeStoreRepository *FakeStoreRepository) GetById(storeId int64) (domain.Store, error) {
+	for _, store := range fakeStoreRepository.stores {
+		if store.Id == storeId {
+			return store, nil
+		}
+	}
+
+	return domain.Store{}, fmt.Errorf("%w with id %d", persistence.ErrStoreNotFound, storeId)
+}
+
+func (fakeStoreRepository *FakeStoreRepository) GetByName(storeName string) (domain.Store, error) {
+	for _, store := range fakeStoreRepository.stores {
+		if strings.EqualFold(store.Name, domain.NormalizeStoreName(storeName)) {
+			return store, nil
+		}
+	}
+
+	return domain.Store{}, errors.New(fmt.Sprintf("Store not found with name %s", storeName))
+}
+
+func (fakeStoreRepository *FakeStoreRepository) GetAllByNames(storeNames []string) []domain.Store {
+	stores := make([]domain.Store, 0)
+
+	for _, storeName := range storeNames {
+		if store, err := fakeStoreRepository.GetByName(storeName); err == nil {
+			stores = append(stores, store)
+		}
+	}
+
+	return stores
+}
+
+func (fakeStoreRepository *FakeStoreRepository) Add(store domain.Store) error {
+	_, err := fakeStoreRepository.GetByName(store.Name)
+
+	if err == nil {
+		return errors.New(fmt.Sprintf("Store already exists with name %s", store.Name))
+	}
+
+	fakeStoreRepository.stores = append(fakeStoreRepository.stores, domain.Store{
+		Id:   int64(len(fakeStoreRepository.stores)) + 1,
+		Name: store.Name,
+	})
+
+	return nil
+}
+
+func (fakeStoreRepository *FakeStoreRepository) UpdateName(storeId int64, newName string) error {
+	existing, err := fakeStoreRepository.GetByName(newName)
+
+	if err == nil && existing.Id != storeId {
+		return errors.New(fmt.Sprintf("Store already exists with name %s", newName))
+	}
+
+	for i, store := range fakeStoreRepository.stores {
+		if store.Id == storeId {
+			fakeStoreRepository.stores[i].Name = newName
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w with id %d", persistence.ErrStoreNotFound, storeId)
+}
+
+func (fakeStoreRepository *FakeStoreRepository) DeleteById(storeId int64) error {
+	for i, store := range fakeStoreRepository.stores {
+		if store.Id == storeId {
+			fakeStoreRepository.stores = append(fakeStoreRepository.stores[:i], fakeStoreRepository.stores[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w with id %d", persistence.ErrStoreNotFound, storeId)
+}
@@ -0,0 +1,47 @@
+package service
+
+import "example.com/product-api/persistence"
+
+type FakeTagRepository struct {
+	productTags map[int64][]string
+}
+
+func NewFakeTagRepository(initialProductTags map[int64][]string) persistence.ITagRepository {
+	return &FakeTagRepository{
+		productTags: initialProductTags,
+	}
+}
+
+func (fakeTagRepository *FakeTagRepository) GetAllByProductId(productId int64) []string {
+	tags := make([]string, 0)
+	return append(tags, fakeTagRepository.productTags[productId]...)
+}
+
+func (fakeTagRepository *FakeTagRepository) AddToProduct(productId int64, tags []string) error {
+	for _, tag := range tags {
+		if !containsTag(fakeTagRepository.productTags[productId], tag) {
+			fakeTagRepository.productTags[productId] = append(fakeTagRepository.productTags[productId], tag)
+		}
+	}
+	return nil
+}
+
+func (fakeTagRepository *FakeTagRepository) RemoveFromProduct(productId int64, tags []string) error {
+	remainingTags := make([]string, 0)
+	for _, tag := range fakeTagRepository.productTags[productId] {
+		if !containsTag(tags, tag) {
+			remainingTags = append(remainingTags, tag)
+		}
+	}
+	fakeTagRepository.productTags[productId] = remainingTags
+	return nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, existingTag := range tags {
+		if existingTag == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"fmt"
+)
+
+type FakeVariantRepository struct {
+	variants []domain.Variant
+}
+
+func NewFakeVariantRepository(initialVariants []domain.Variant) persistence.IVariantRepository {
+	return &FakeVariantRepository{
+		variants: initialVariants,
+	}
+}
+
+func (fakeVariantRepository *FakeVariantRepository) GetAllByProductId(productId int64) []domain.Variant {
+	return fakeVariantRepository.GetAllByProductIds([]int64{productId})
+}
+
+func (fakeVariantRepository *FakeVariantRepository) GetAllByProductIds(productIds []int64) []domain.Variant {
+	variants := make([]domain.Variant, 0)
+
+	for _, variant := range fakeVariantRepository.variants {
+		for _, productId := range productIds {
+			if variant.ProductId == productId {
+				variants = append(variants, variant)
+			}
+		}
+	}
+
+	return variants
+}
+
+func (fakeVariantRepository *FakeVariantRepository) GetById(productId int64, variantId int64) (domain.Variant, error) {
+	for _, variant := range fakeVariantRepository.variants {
+		if variant.ProductId == productId && variant.Id == variantId {
+			return variant, nil
+		}
+	}
+
+	return domain.Variant{}, errors.New(fmt.Sprintf("Variant %d not found for product %d", variantId, productId))
+}
+
+func (fakeVariantRepository *FakeVariantRepository) Add(variant domain.Variant) error {
+	for _, existing := range fakeVariantRepository.variants {
+		if existing.Sku == variant.Sku {
+			return errors.New(fmt.Sprintf("Variant already exists with sku %s or barcode %s", variant.Sku, variant.Barcode))
+		}
+	}
+
+	variant.Id = int64(len(fakeVariantRepository.variants)) + 1
+	fakeVariantRepository.variants = append(fakeVariantRepository.variants, variant)
+
+	return nil
+}
+
+func (fakeVariantRepository *FakeVariantRepository) Update(variant domain.Variant) error {
+	for i, existing := range fakeVariantRepository.variants {
+		if existing.ProductId == variant.ProductId && existing.Id == variant.Id {
+			fakeVariantRepository.variants[i] = variant
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Variant %d not found for product %d", variant.Id, variant.ProductId))
+}
+
+func (fakeVariantRepository *FakeVariantRepository) DeleteById(productId int64, variantId int64) error {
+	for i, variant := range fakeVariantRepository.variants {
+		if variant.ProductId == productId && variant.Id == variantId {
+			fakeVariantRepository.variants = append(fakeVariantRepository.variants[:i], fakeVariantRepository.variants[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Variant %d not found for product %d", variantId, productId))
+}
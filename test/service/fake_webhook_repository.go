@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"fmt"
+	"time"
+)
+
+// FakeWebhookRepository keeps subscriptions and deliveries in memory. Like
+// FakeOutboxRepository it is returned as is, so tests can move retries
+// forward in time.
+type FakeWebhookRepository struct {
+	subscriptions []domain.WebhookSubscription
+	deliveries    []domain.WebhookDelivery
+}
+
+func NewFakeWebhookRepository(subscriptions ...domain.WebhookSubscription) *FakeWebhookRepository {
+	return &FakeWebhookRepository{subscriptions: subscriptions}
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) GetAll() []domain.WebhookSubscription {
+	return append([]domain.WebhookSubscription{}, fakeWebhookRepository.subscriptions...)
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) GetById(subscriptionId int64) (domain.WebhookSubscription, error) {
+	for _, subscription := range fakeWebhookRepository.subscriptions {
+		if subscription.Id == subscriptionId {
+			return subscription, nil
+		}
+	}
+
+	return domain.WebhookSubscription{}, errors.New(fmt.Sprintf("Webhook not found with id %d", subscriptionId))
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) Add(subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	subscription.Id = int64(len(fakeWebhookRepository.subscriptions)) + 1
+	subscription.CreatedAt = time.Now()
+	fakeWebhookRepository.subscriptions = append(fakeWebhookRepository.subscriptions, subscription)
+
+	return subscription, nil
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) DeleteById(subscriptionId int64) error {
+	for i, subscription := range fakeWebhookRepository.subscriptions {
+		if subscription.Id == subscriptionId {
+			fakeWebhookRepository.subscriptions = append(fakeWebhookRepository.subscriptions[:i], fakeWebhookRepository.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Webhook not found with id %d", subscriptionId))
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) EnqueueDeliveries(eventId int64, eventType string, storeId int64, payload []byte) (int64, error) {
+	var enqueued int64
+
+	for _, subscription := range fakeWebhookRepository.subscriptions {
+		if !containsEventType(subscription.EventTypes, eventType) || (subscription.StoreId != 0 && subscription.StoreId != storeId) {
+			continue
+		}
+
+		if fakeWebhookRepository.hasDelivery(subscription.Id, eventId) {
+			continue
+		}
+
+		fakeWebhookRepository.deliveries = append(fakeWebhookRepository.deliveries, domain.WebhookDelivery{
+			Id:             int64(len(fakeWebhookRepository.deliveries)) + 1,
+			SubscriptionId: subscription.Id,
+			EventId:        eventId,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         domain.WEBHOOK_PENDING,
+			CreatedAt:      time.Now(),
+		})
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) GetDeliveries(subscriptionId int64, status string, limit int) []domain.WebhookDelivery {
+	var deliveries = []domain.WebhookDelivery{}
+
+	for i := len(fakeWebhookRepository.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		delivery := fakeWebhookRepository.deliveries[i]
+
+		if delivery.SubscriptionId == subscriptionId && (len(status) == 0 || delivery.Status == status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return deliveries
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) Redeliver(subscriptionId int64, deliveryId int64) error {
+	for i, delivery := range fakeWebhookRepository.deliveries {
+		if delivery.Id == deliveryId && delivery.SubscriptionId == subscriptionId {
+			fakeWebhookRepository.deliveries[i].Status = domain.WEBHOOK_PENDING
+			fakeWebhookRepository.deliveries[i].Attempts = 0
+			fakeWebhookRepository.deliveries[i].NextAttemptAt = time.Time{}
+			fakeWebhookRepository.deliveries[i].DeliveredAt = nil
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Webhook delivery not found with id %d", deliveryId))
+}
+
+// ProcessDueDeliveries leases the deliveries it hands to process like the
+// real repository.
+func (fakeWebhookRepository *FakeWebhookRepository) ProcessDueDeliveries(limit int, lease time.Duration, process func(deliveries []domain.WebhookDelivery) []domain.WebhookDeliveryOutcome) (int, error) {
+	var due []domain.WebhookDelivery
+
+	for _, delivery := range fakeWebhookRepository.deliveries {
+		isDue := delivery.Status == domain.WEBHOOK_PENDING || delivery.Status == domain.WEBHOOK_FAILED
+
+		if len(due) < limit && isDue && !delivery.NextAttemptAt.After(time.Now()) {
+			subscription, _ := fakeWebhookRepository.GetById(delivery.SubscriptionId)
+			delivery.Url, delivery.Secret = subscription.Url, subscription.Secret
+			due = append(due, delivery)
+		}
+	}
+
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	for i := range fakeWebhookRepository.deliveries {
+		for _, delivery := range due {
+			if fakeWebhookRepository.deliveries[i].Id == delivery.Id {
+				fakeWebhookRepository.deliveries[i].NextAttemptAt = time.Now().Add(lease)
+			}
+		}
+	}
+
+	for _, outcome := range process(due) {
+		for i := range fakeWebhookRepository.deliveries {
+			delivery := &fakeWebhookRepository.deliveries[i]
+
+			if delivery.Id != outcome.Id {
+				continue
+			}
+
+			delivery.Status = outcome.Status
+			delivery.Attempts++
+			delivery.LastStatusCode = outcome.StatusCode
+			delivery.LastError = outcome.Error
+
+			if !outcome.NextAttemptAt.IsZero() {
+				delivery.NextAttemptAt = outcome.NextAttemptAt
+			}
+
+			if outcome.Status == domain.WEBHOOK_SUCCEEDED {
+				deliveredAt := time.Now()
+				delivery.DeliveredAt = &deliveredAt
+			}
+		}
+	}
+
+	return len(due), nil
+}
+
+// MakeDue moves every retry to now, as if the backoff had passed.
+func (fakeWebhookRepository *FakeWebhookRepository) MakeDue() {
+	for i := range fakeWebhookRepository.deliveries {
+		fakeWebhookRepository.deliveries[i].NextAttemptAt = time.Time{}
+	}
+}
+
+func (fakeWebhookRepository *FakeWebhookRepository) hasDelivery(subscriptionId int64, eventId int64) bool {
+	for _, delivery := range fakeWebhookRepository.deliveries {
+		if delivery.SubscriptionId == subscriptionId && delivery.EventId == eventId {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEventType(eventTypes []string, eventType string) bool {
+	for _, candidate := range eventTypes {
+		if candidate == eventType {
+			return true
+		}
+	}
+	return false
+}
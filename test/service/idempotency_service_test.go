@@ -0,0 +1,73 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_WhenKeyIsInvalid_ShouldNotClaimIt(t *testing.T) {
+	idempotencyService := service.NewIdempotencyService(NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	_, _, err := idempotencyService.Begin("", "POST /api/products", "hash")
+	assert.Equal(t, service.ErrInvalidIdempotencyKey, err)
+
+	_, _, err = idempotencyService.Begin(strings.Repeat("k", service.MAX_IDEMPOTENCY_KEY_LENGTH+1), "POST /api/products", "hash")
+	assert.Equal(t, service.ErrInvalidIdempotencyKey, err)
+}
+
+func Test_WhenClaimIsReleased_ShouldAllowRetry(t *testing.T) {
+	idempotencyService := service.NewIdempotencyService(NewFakeIdempotencyRepository(), time.Hour, time.Minute)
+
+	claim, stored, err := idempotencyService.Begin("key", "POST /api/products", "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+
+	_, _, err = idempotencyService.Begin("key", "POST /api/products", "hash")
+	assert.Equal(t, service.ErrIdempotencyKeyInFlight, err)
+
+	idempotencyService.Release(claim)
+
+	_, stored, err = idempotencyService.Begin("key", "POST /api/products", "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+}
+
+func Test_WhenInFlightRequestTimesOut_ShouldHandKeyOver(t *testing.T) {
+	idempotencyService := service.NewIdempotencyService(NewFakeIdempotencyRepository(), time.Hour, 10*time.Millisecond)
+
+	abandoned, _, _ := idempotencyService.Begin("key", "POST /api/products", "hash")
+	time.Sleep(20 * time.Millisecond)
+
+	claim, stored, err := idempotencyService.Begin("key", "POST /api/products", "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+
+	// The abandoned request finishing late does not overwrite the new claim.
+	idempotencyService.Complete(abandoned, domain.StoredResponse{StatusCode: 500})
+	idempotencyService.Complete(claim, domain.StoredResponse{StatusCode: 201, Body: []byte("created")})
+
+	_, stored, err = idempotencyService.Begin("key", "POST /api/products", "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, 201, stored.StatusCode)
+	assert.Equal(t, "created", string(stored.Body))
+}
+
+func Test_WhenWindowHasPassed_ShouldForgetResponse(t *testing.T) {
+	idempotencyService := service.NewIdempotencyService(NewFakeIdempotencyRepository(), 10*time.Millisecond, time.Minute)
+
+	claim, _, _ := idempotencyService.Begin("key", "POST /api/products", "hash")
+	idempotencyService.Complete(claim, domain.StoredResponse{StatusCode: 201})
+	time.Sleep(20 * time.Millisecond)
+
+	_, stored, err := idempotencyService.Begin("key", "POST /api/products", "other hash")
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+
+	time.Sleep(20 * time.Millisecond)
+	purged, _ := idempotencyService.PurgeExpired()
+	assert.Equal(t, int64(1), purged)
+}
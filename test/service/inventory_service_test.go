@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newInventoryServiceForTest() service.IInventoryService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	inventories := []domain.Inventory{
+		{ProductId: 1, StoreId: 1, OnHand: 2},
+	}
+
+	return service.NewInventoryService(NewFakeInventoryRepository(inventories), NewFakeProductRepository(products))
+}
+
+func Test_WhenStockIsAvailable_ShouldReserve(t *testing.T) {
+	inventoryService := newInventoryServiceForTest()
+
+	inventory, err := inventoryService.Reserve(1, 1, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), inventory.Reserved)
+	assert.Equal(t, int32(0), inventory.Available())
+}
+
+func Test_WhenStockIsNotAvailable_ShouldRefuseReserve(t *testing.T) {
+	inventoryService := newInventoryServiceForTest()
+
+	_, err := inventoryService.Reserve(1, 1, 3)
+
+	var inventoryConflictError *service.InventoryConflictError
+	assert.True(t, errors.As(err, &inventoryConflictError))
+	assert.Equal(t, "Inventory of product 1 in store 1: not enough stock available", err.Error())
+}
+
+func Test_WhenCommittingReservedStock_ShouldReduceOnHand(t *testing.T) {
+	inventoryService := newInventoryServiceForTest()
+
+	inventoryService.Reserve(1, 1, 1)
+	inventory, err := inventoryService.Commit(1, 1, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, domain.Inventory{ProductId: 1, StoreId: 1, OnHand: 1, Reserved: 0}, inventory)
+
+	_, err = inventoryService.Commit(1, 1, 1)
+	assert.Equal(t, "Inventory of product 1 in store 1: not enough stock reserved", err.Error())
+}
+
+func Test_WhenProductDoesNotExist_ShouldNotAdjust(t *testing.T) {
+	inventoryService := newInventoryServiceForTest()
+
+	_, err := inventoryService.Adjust(9, 1, 5)
+
+	assert.Equal(t, "Product not found with id 9", err.Error())
+}
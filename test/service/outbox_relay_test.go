@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingSink remembers the events it accepted and fails the first
+// failures deliveries.
+type recordingSink struct {
+	name     string
+	failures int
+	events   []domain.ProductEvent
+}
+
+func (recordingSink *recordingSink) Name() string {
+	return recordingSink.name
+}
+
+func (recordingSink *recordingSink) Deliver(event domain.ProductEvent) error {
+	if recordingSink.failures > 0 {
+		recordingSink.failures--
+		return errors.New("unavailable")
+	}
+
+	recordingSink.events = append(recordingSink.events, event)
+	return nil
+}
+
+var outboxRelayConfigForTest = service.OutboxRelayConfig{
+	PollInterval:    time.Second,
+	BatchSize:       10,
+	Lease:           time.Minute,
+	MaxAttempts:     3,
+	RetryBackoff:    time.Minute,
+	MaxRetryBackoff: 90 * time.Second,
+	Retention:       time.Hour,
+}
+
+func Test_ShouldDeliverOutboxMessagesToEverySink(t *testing.T) {
+	outboxRepository := NewFakeOutboxRepository(
+		domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7, Store: "ABC TECH"}},
+		domain.ProductEvent{Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 2, Store: "ABC TECH"}},
+	)
+	first, second := &recordingSink{name: "first"}, &recordingSink{name: "second"}
+	outboxRelay := service.NewOutboxRelay(outboxRepository, []service.IOutboxSink{first, second}, outboxRelayConfigForTest)
+
+	assert.Equal(t, 2, outboxRelay.RelayOnce())
+	assert.Equal(t, 0, outboxRelay.RelayOnce())
+
+	assert.Empty(t, outboxRepository.Pending())
+	assert.Equal(t, 2, len(second.events))
+	assert.Equal(t, uint64(1), first.events[0].Id)
+	assert.Equal(t, domain.PRODUCT_DELETED, first.events[1].Type)
+}
+
+func Test_WhenSinkFails_ShouldRetryOnlyThatSinkWithBackoff(t *testing.T) {
+	outboxRepository := NewFakeOutboxRepository(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7}})
+	healthy, flaky := &recordingSink{name: "healthy"}, &recordingSink{name: "flaky", failures: 2}
+	outboxRelay := service.NewOutboxRelay(outboxRepository, []service.IOutboxSink{healthy, flaky}, outboxRelayConfigForTest)
+
+	outboxRelay.RelayOnce()
+
+	assert.Equal(t, []string{"healthy"}, outboxRepository.Pending()[0].DeliveredSinks)
+	assert.Equal(t, "flaky: unavailable", outboxRepository.LastError(1))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), outboxRepository.NextAttemptAt(1), 5*time.Second)
+	assert.Equal(t, 0, outboxRelay.RelayOnce())
+
+	outboxRepository.MakeDue()
+	outboxRelay.RelayOnce()
+
+	// The backoff doubles but stays under MaxRetryBackoff.
+	assert.WithinDuration(t, time.Now().Add(90*time.Second), outboxRepository.NextAttemptAt(1), 5*time.Second)
+
+	outboxRepository.MakeDue()
+	outboxRelay.RelayOnce()
+
+	assert.Empty(t, outboxRepository.Pending())
+	assert.Equal(t, 1, len(healthy.events))
+	assert.Equal(t, 1, len(flaky.events))
+}
+
+func Test_WhenMaxAttemptsAreReached_ShouldDeadLetterMessage(t *testing.T) {
+	outboxRepository := NewFakeOutboxRepository(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7}})
+	broken := &recordingSink{name: "broken", failures: 100}
+	outboxRelay := service.NewOutboxRelay(outboxRepository, []service.IOutboxSink{broken}, outboxRelayConfigForTest)
+
+	for i := 0; i < 5; i++ {
+		outboxRepository.MakeDue()
+		outboxRelay.RelayOnce()
+	}
+
+	assert.True(t, outboxRepository.IsDeadLettered(1))
+	assert.Equal(t, 3, outboxRepository.Pending()[0].Attempts)
+	assert.Equal(t, 97, broken.failures)
+}
+
+// relayingSink runs another relay pass while it delivers, as a second
+// relay instance would.
+type relayingSink struct {
+	relay   *service.OutboxRelay
+	relayed []int
+}
+
+func (relayingSink *relayingSink) Name() string {
+	return "relaying"
+}
+
+func (relayingSink *relayingSink) Deliver(event domain.ProductEvent) error {
+	relayingSink.relayed = append(relayingSink.relayed, relayingSink.relay.RelayOnce())
+	return nil
+}
+
+func Test_WhileMessagesAreDelivered_ShouldKeepThemFromOtherRelays(t *testing.T) {
+	outboxRepository := NewFakeOutboxRepository(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7}})
+	sink := &relayingSink{}
+	sink.relay = service.NewOutboxRelay(outboxRepository, []service.IOutboxSink{sink}, outboxRelayConfigForTest)
+
+	assert.Equal(t, 1, sink.relay.RelayOnce())
+	assert.Equal(t, []int{0}, sink.relayed)
+	assert.Empty(t, outboxRepository.Pending())
+}
+
+func Test_ShouldPurgeDeliveredMessagesAfterRetention(t *testing.T) {
+	outboxRepository := NewFakeOutboxRepository(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7}})
+	outboxRelay := service.NewOutboxRelay(outboxRepository, []service.IOutboxSink{&recordingSink{name: "first"}}, outboxRelayConfigForTest)
+	outboxRelay.RelayOnce()
+
+	assert.Equal(t, int64(0), outboxRelay.PurgeOnce())
+
+	events, _ := outboxRepository.GetAfter(0, 10)
+	assert.Equal(t, 1, len(events))
+
+	config := outboxRelayConfigForTest
+	config.Retention = -time.Minute
+	assert.Equal(t, int64(1), service.NewOutboxRelay(outboxRepository, nil, config).PurgeOnce())
+}
+
+func Test_ProductEventBusSink_ShouldPublishToSubscribers(t *testing.T) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+	subscription := productEventBus.Subscribe(0)
+	defer subscription.Close()
+
+	sink := service.NewProductEventBusSink(productEventBus)
+
+	assert.Nil(t, sink.Deliver(domain.ProductEvent{Id: 42, Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 1, Price: 2800.0}}))
+
+	event := <-subscription.Events
+	assert.Equal(t, uint64(42), event.Id)
+	assert.Equal(t, float32(2800.0), event.Product.Price)
+}
+
+func Test_LogFileSink_ShouldAppendOneJsonLinePerEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	sink := service.NewLogFileSink(path)
+
+	assert.Nil(t, sink.Deliver(domain.ProductEvent{Id: 1, Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7, Name: "Kettle"}}))
+	assert.Nil(t, sink.Deliver(domain.ProductEvent{Id: 2, Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 7}}))
+
+	content, err := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[0], `"name":"Kettle"`)
+	assert.Contains(t, lines[1], `"type":"deleted"`)
+}
+
+func Test_WebhookSink_ShouldFailOnNon2xxStatus(t *testing.T) {
+	var received map[string]interface{}
+	status := http.StatusInternalServerError
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := service.NewWebhookSink(server.URL, time.Second)
+	event := domain.ProductEvent{Id: 5, Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 1, Price: 2800.0}, PreviousPrice: 3000.0}
+
+	assert.Equal(t, "Webhook answered with status 500", sink.Deliver(event).Error())
+
+	status = http.StatusNoContent
+
+	assert.Nil(t, sink.Deliver(event))
+	assert.Equal(t, float64(5), received["id"])
+	assert.Equal(t, float64(3000), received["previousPrice"])
+}
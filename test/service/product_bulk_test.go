@@ -0,0 +1,96 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newBulkProductServiceForTest() service.IProductService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Iron", Price: 1500.0, Discount: 10.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	return service.NewProductService(NewFakeProductRepository(products))
+}
+
+func Test_WhenAllBulkItemsAreValid_ShouldApplyAll(t *testing.T) {
+	productService := newBulkProductServiceForTest()
+
+	results, err := productService.ApplyBulk(dto.ProductBulk{
+		Atomic:  true,
+		Creates: []dto.ProductCreate{{Name: "Kettle", Price: 800.0, StoreId: 1}},
+		Updates: []dto.ProductPriceUpdate{{Id: 1, Price: 2800.0}},
+		Deletes: []int64{2},
+	})
+
+	product, _ := productService.GetById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.BulkResult{
+		{Action: domain.BULK_CREATE, Index: 0, Id: 3, StoreId: 1, Store: "ABC TECH"},
+		{Action: domain.BULK_UPDATE, Index: 0, Id: 1, StoreId: 1, Store: "ABC TECH"},
+		{Action: domain.BULK_DELETE, Index: 0, Id: 2, StoreId: 1, Store: "ABC TECH"},
+	}, results)
+	assert.Equal(t, float32(2800.0), product.Price)
+	assert.Equal(t, 2, len(productService.GetAll()))
+}
+
+func Test_WhenAtomicBulkItemIsInvalid_ShouldApplyNothing(t *testing.T) {
+	productService := newBulkProductServiceForTest()
+
+	results, err := productService.ApplyBulk(dto.ProductBulk{
+		Atomic:  true,
+		Creates: []dto.ProductCreate{{Name: "Kettle", Price: 800.0, StoreId: 1}, {Name: "Toaster", Price: 900.0, Discount: 80.0, StoreId: 1}},
+	})
+
+	assert.Equal(t, "Bulk operations rolled back", err.Error())
+	assert.Equal(t, "", results[0].Error)
+	assert.Equal(t, "Discount can not be greater than 70", results[1].Error)
+	assert.Equal(t, 2, len(productService.GetAll()))
+}
+
+func Test_WhenAtomicBulkItemIsNotFound_ShouldRollBack(t *testing.T) {
+	productService := newBulkProductServiceForTest()
+
+	results, err := productService.ApplyBulk(dto.ProductBulk{
+		Atomic:  true,
+		Updates: []dto.ProductPriceUpdate{{Id: 1, Price: 2800.0}},
+		Deletes: []int64{9},
+	})
+
+	product, _ := productService.GetById(1)
+	assert.Equal(t, "Bulk operations rolled back", err.Error())
+	assert.Equal(t, "Product not found with id 9", results[1].Error)
+	assert.Equal(t, float32(3000.0), product.Price)
+}
+
+func Test_WhenBestEffortBulkItemFails_ShouldApplyTheOthers(t *testing.T) {
+	productService := newBulkProductServiceForTest()
+
+	results, err := productService.ApplyBulk(dto.ProductBulk{
+		Creates: []dto.ProductCreate{{Name: "Kettle", Price: 800.0}, {Name: "Toaster", Price: 900.0, StoreId: 1}},
+		Updates: []dto.ProductPriceUpdate{{Id: 1, Price: 0}, {Id: 2, Price: 1400.0}},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.BulkResult{
+		{Action: domain.BULK_CREATE, Index: 0, Error: "Store can not be empty"},
+		{Action: domain.BULK_CREATE, Index: 1, Id: 3, StoreId: 1, Store: "ABC TECH"},
+		{Action: domain.BULK_UPDATE, Index: 0, Error: "Price must be greater than 0"},
+		{Action: domain.BULK_UPDATE, Index: 1, Id: 2, StoreId: 1, Store: "ABC TECH"},
+	}, results)
+	assert.Equal(t, 3, len(productService.GetAll()))
+}
+
+func Test_WhenBulkRequestIsEmptyOrTooLarge_ShouldRefuse(t *testing.T) {
+	productService := newBulkProductServiceForTest()
+
+	_, err := productService.ApplyBulk(dto.ProductBulk{})
+	assert.Equal(t, "Bulk request has no operations", err.Error())
+
+	_, err = productService.ApplyBulk(dto.ProductBulk{Deletes: make([]int64, service.MAX_BULK_OPERATIONS+1)})
+	assert.Equal(t, "Bulk request can not have more than 10000 operations", err.Error())
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProductRepository counts the reads that reach the repository and
+// holds them until release is closed, when it is set.
+type countingProductRepository struct {
+	persistence.IProductRepository
+	getByIdCalls       atomic.Int64
+	getAllByStoreCalls atomic.Int64
+	release            chan struct{}
+}
+
+func (countingRepository *countingProductRepository) GetById(productId int64) (domain.Product, error) {
+	countingRepository.getByIdCalls.Add(1)
+
+	if countingRepository.release != nil {
+		<-countingRepository.release
+	}
+
+	return countingRepository.IProductRepository.GetById(productId)
+}
+
+func (countingRepository *countingProductRepository) GetAllByStore(storeName string) []domain.Product {
+	countingRepository.getAllByStoreCalls.Add(1)
+	return countingRepository.IProductRepository.GetAllByStore(storeName)
+}
+
+var productCacheConfig = persistence.ProductCacheConfig{Size: 100, Ttl: time.Minute, NegativeTtl: time.Minute}
+
+func newCachedProductRepository(config persistence.ProductCacheConfig) (*persistence.CachedProductRepository, *countingProductRepository) {
+	countingRepository := &countingProductRepository{IProductRepository: NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Iron", Price: 1500.0, StoreId: 1, Store: "ABC TECH"},
+	})}
+
+	return persistence.NewCachedProductRepository(countingRepository, config), countingRepository
+}
+
+func Test_ShouldServeRepeatedReadsFromCache(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+
+	for i := 0; i < 3; i++ {
+		product, err := cachedRepository.GetById(1)
+		assert.Nil(t, err)
+		assert.Equal(t, "AirFryer", product.Name)
+
+		assert.Equal(t, 2, len(cachedRepository.GetAllByStore("abc tech")))
+	}
+
+	assert.Equal(t, int64(1), countingRepository.getByIdCalls.Load())
+	assert.Equal(t, int64(1), countingRepository.getAllByStoreCalls.Load())
+
+	stats := cachedRepository.Stats()
+	assert.Equal(t, int64(4), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 1, stats.Products)
+	assert.Equal(t, 1, stats.Stores)
+}
+
+func Test_ShouldCacheNotFoundProducts(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+
+	_, err := cachedRepository.GetById(99)
+	assert.Equal(t, "Product not found with id 99", err.Error())
+
+	_, err = cachedRepository.GetById(99)
+	assert.Equal(t, "Product not found with id 99", err.Error())
+
+	assert.Equal(t, int64(1), countingRepository.getByIdCalls.Load())
+	assert.Equal(t, 1, cachedRepository.Stats().NotFound)
+}
+
+func Test_WhenProductIsWritten_ShouldInvalidateIt(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+
+	cachedRepository.GetById(1)
+	cachedRepository.GetAllByStore("ABC TECH")
+
+	assert.Nil(t, cachedRepository.UpdatePrice(1, 2500.0))
+
+	product, _ := cachedRepository.GetById(1)
+	assert.Equal(t, float32(2500.0), product.Price)
+	assert.Equal(t, float32(2500.0), cachedRepository.GetAllByStore("ABC TECH")[0].Price)
+	assert.Equal(t, int64(2), countingRepository.getByIdCalls.Load())
+	assert.Equal(t, int64(2), countingRepository.getAllByStoreCalls.Load())
+
+	// A product that was missing is found once it is added.
+	_, err := cachedRepository.GetById(3)
+	assert.NotNil(t, err)
+
+	added, _ := cachedRepository.Add(domain.Product{Name: "Kettle", Price: 400.0, StoreId: 1, Store: "ABC TECH"})
+	assert.Equal(t, int64(3), added.Id)
+
+	product, err = cachedRepository.GetById(3)
+	assert.Nil(t, err)
+	assert.Equal(t, "Kettle", product.Name)
+	assert.Equal(t, 3, len(cachedRepository.GetAllByStore("ABC TECH")))
+}
+
+func Test_WhenOtherInstanceChangesProduct_ShouldInvalidateIt(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+
+	cachedRepository.GetById(1)
+	cachedRepository.GetById(2)
+
+	cachedRepository.ProductChanged(domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: 1}})
+	assert.Equal(t, 1, cachedRepository.Stats().Products)
+
+	cachedRepository.GetById(1)
+	assert.Equal(t, int64(3), countingRepository.getByIdCalls.Load())
+
+	cachedRepository.Resync()
+	assert.Equal(t, 0, cachedRepository.Stats().Products)
+}
+
+func Test_WhenStoreIsRenamed_ShouldDropItsProducts(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+
+	cachedRepository.GetById(1)
+	cachedRepository.GetAllByStore("ABC TECH")
+
+	cachedRepository.StoreRenamed(1)
+	assert.Equal(t, 0, cachedRepository.Stats().Products)
+
+	cachedRepository.GetById(1)
+	cachedRepository.GetAllByStore("ABC TECH")
+	assert.Equal(t, int64(2), countingRepository.getByIdCalls.Load())
+	assert.Equal(t, int64(2), countingRepository.getAllByStoreCalls.Load())
+}
+
+func Test_WhenEntryExpires_ShouldReadAgain(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(persistence.ProductCacheConfig{
+		Size: 100, Ttl: 20 * time.Millisecond, NegativeTtl: 20 * time.Millisecond,
+	})
+
+	cachedRepository.GetById(1)
+	time.Sleep(50 * time.Millisecond)
+	cachedRepository.GetById(1)
+
+	assert.Equal(t, int64(2), countingRepository.getByIdCalls.Load())
+}
+
+func Test_ShouldCollapseConcurrentMisses(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+	countingRepository.release = make(chan struct{})
+
+	var waitGroup sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+			product, err := cachedRepository.GetById(2)
+			assert.Nil(t, err)
+			assert.Equal(t, "Iron", product.Name)
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return cachedRepository.Stats().Misses == 10
+	}, time.Second, time.Millisecond)
+
+	// Gives the callers that counted their miss time to join the query.
+	time.Sleep(20 * time.Millisecond)
+	close(countingRepository.release)
+	waitGroup.Wait()
+
+	assert.Equal(t, int64(1), countingRepository.getByIdCalls.Load())
+}
+
+func Test_WhenWriteHappensDuringMiss_ShouldNotCacheTheOldProduct(t *testing.T) {
+	cachedRepository, countingRepository := newCachedProductRepository(productCacheConfig)
+	countingRepository.release = make(chan struct{})
+
+	loaded := make(chan domain.Product)
+
+	go func() {
+		product, _ := cachedRepository.GetById(1)
+		loaded <- product
+	}()
+
+	assert.Eventually(t, func() bool {
+		return countingRepository.getByIdCalls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	cachedRepository.ProductChanged(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 1}})
+	close(countingRepository.release)
+	<-loaded
+
+	assert.Equal(t, 0, cachedRepository.Stats().Products)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newDuplicateProductServiceForTest() service.IProductService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Air Fryer", Price: 3100.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 3, Name: "Iron", Price: 1500.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 4, Name: "AirFryer", Price: 2900.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	return service.NewProductService(NewFakeProductRepository(products))
+}
+
+func Test_WhenNameIsTakenInStore_ShouldNotAddProduct(t *testing.T) {
+	productService := newDuplicateProductServiceForTest()
+
+	_, err := productService.Add(dto.ProductCreate{Name: "airfryer ", Price: 3000.0, StoreId: 1})
+	assert.True(t, errors.Is(err, service.ErrDuplicateProduct))
+
+	_, err = productService.Add(dto.ProductCreate{Name: "Iron", Price: 1500.0, StoreId: 2})
+	assert.Nil(t, err)
+}
+
+func Test_ShouldFindDuplicatesWithinStore(t *testing.T) {
+	productService := newDuplicateProductServiceForTest()
+
+	duplicates, err := productService.FindDuplicates("abc tech", 0.3, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(duplicates))
+	assert.Equal(t, int64(1), duplicates[0].Product.Id)
+	assert.Equal(t, int64(2), duplicates[0].Duplicate.Id)
+}
+
+func Test_WhenDuplicateThresholdIsOutOfRange_ShouldNotFindDuplicates(t *testing.T) {
+	productService := newDuplicateProductServiceForTest()
+
+	_, err := productService.FindDuplicates("", 1.5, 0)
+	assert.Equal(t, "Threshold must be between 0 and 1", err.Error())
+
+	_, err = productService.FindDuplicates("", 0, 5000)
+	assert.Equal(t, "Limit must be between 1 and 1000", err.Error())
+}
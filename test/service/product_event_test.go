@@ -0,0 +1,121 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ShouldReplayEventsAfterLastEventId(t *testing.T) {
+	productEventBus := service.NewProductEventBus(3)
+
+	for i := int64(1); i <= 5; i++ {
+		productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: i}})
+	}
+
+	resumed := productEventBus.Subscribe(3)
+	defer resumed.Close()
+	assert.Equal(t, 2, len(resumed.Replay))
+	assert.Equal(t, uint64(4), resumed.Replay[0].Id)
+
+	// Only the last three events are kept, so an older id gets all of them.
+	tooOld := productEventBus.Subscribe(1)
+	defer tooOld.Close()
+	assert.Equal(t, 3, len(tooOld.Replay))
+	assert.Equal(t, uint64(3), tooOld.Replay[0].Id)
+
+	fresh := productEventBus.Subscribe(0)
+	defer fresh.Close()
+	assert.Empty(t, fresh.Replay)
+}
+
+func Test_WhenEventHasOutboxId_ShouldKeepIt(t *testing.T) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+
+	productEventBus.Publish(domain.ProductEvent{Id: 41, Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: 1}})
+	productEventBus.Publish(domain.ProductEvent{Id: 42, Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 1}})
+	productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: 2}})
+
+	resumed := productEventBus.Subscribe(41)
+	defer resumed.Close()
+	assert.Equal(t, 2, len(resumed.Replay))
+	assert.Equal(t, uint64(42), resumed.Replay[0].Id)
+	assert.Equal(t, uint64(43), resumed.Replay[1].Id)
+}
+
+func Test_WhenEventsCommitOutOfIdOrder_ShouldReplayInPublishOrder(t *testing.T) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+
+	for _, id := range []uint64{10, 12, 11, 13} {
+		productEventBus.Publish(domain.ProductEvent{Id: id, Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: 1}})
+	}
+
+	resumed := productEventBus.Subscribe(12)
+	defer resumed.Close()
+	assert.Equal(t, 2, len(resumed.Replay))
+	assert.Equal(t, uint64(11), resumed.Replay[0].Id)
+	assert.Equal(t, uint64(13), resumed.Replay[1].Id)
+
+	unknown := productEventBus.Subscribe(7)
+	defer unknown.Close()
+	assert.Equal(t, 4, len(unknown.Replay))
+}
+
+func Test_WhenSubscriberFallsBehind_ShouldDropIt(t *testing.T) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+	subscription := productEventBus.Subscribe(0)
+
+	for i := 0; i <= service.PRODUCT_EVENT_SUBSCRIBER_BUFFER; i++ {
+		productEventBus.Publish(domain.ProductEvent{Type: domain.PRODUCT_UPDATED})
+	}
+
+	received := 0
+	for range subscription.Events {
+		received++
+	}
+
+	assert.Equal(t, service.PRODUCT_EVENT_SUBSCRIBER_BUFFER, received)
+	subscription.Close()
+}
+
+func Test_ProductEventFeed_ShouldPublishChangesOnBus(t *testing.T) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+	subscription := productEventBus.Subscribe(0)
+	defer subscription.Close()
+
+	outboxRepository := NewFakeOutboxRepository(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 1}})
+	productEventFeed := service.NewProductEventFeed(service.NewProductEventBusSink(productEventBus), outboxRepository)
+	productEventFeed.Resync()
+	productEventFeed.ProductChanged(domain.ProductEvent{Id: 2, Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 2}})
+
+	event := <-subscription.Events
+	assert.Equal(t, domain.PRODUCT_DELETED, event.Type)
+	assert.Equal(t, 0, len(subscription.Events))
+}
+
+func Test_WhenListenerReconnects_ShouldReplayMissedChangesOnce(t *testing.T) {
+	productEventBus := service.NewProductEventBus(service.PRODUCT_EVENT_REPLAY_SIZE)
+	subscription := productEventBus.Subscribe(0)
+	defer subscription.Close()
+
+	outboxRepository := NewFakeOutboxRepository(domain.ProductEvent{Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 1}})
+	productEventFeed := service.NewProductEventFeed(service.NewProductEventBusSink(productEventBus), outboxRepository)
+	productEventFeed.Resync()
+
+	// Ids 2 and 3 are taken in that order, but 3 commits first and is the
+	// only one notified before the connection is lost.
+	outboxRepository.Add(domain.ProductEvent{Type: domain.PRODUCT_UPDATED, Product: domain.Product{Id: 1}})
+	delivered := outboxRepository.Add(domain.ProductEvent{Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 2}})
+	productEventFeed.ProductChanged(domain.ProductEvent{Id: uint64(delivered), Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 2}})
+	outboxRepository.Add(domain.ProductEvent{Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 3}})
+
+	productEventFeed.Resync()
+
+	var ids []uint64
+	for len(subscription.Events) > 0 {
+		ids = append(ids, (<-subscription.Events).Id)
+	}
+
+	assert.Equal(t, []uint64{3, 2, 4}, ids)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"example.com/product-api/controller/response"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newExportProductServiceForTest() service.IProductService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH", Attributes: map[string]interface{}{"color": "black"}},
+		{Id: 2, Name: "Floor Lamp", Price: 2000.0, Discount: 0.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	return service.NewProductService(NewFakeProductRepository(products))
+}
+
+func exportForTest(productService service.IProductService, format string, filter domain.ProductFilter) string {
+	var buffer bytes.Buffer
+	exportWriter, _ := response.NewProductExportWriter(format, &buffer)
+
+	productService.Export(filter, exportWriter.Write)
+	exportWriter.Close()
+
+	return buffer.String()
+}
+
+func Test_ShouldExportProductsAsCsv(t *testing.T) {
+	productService := newExportProductServiceForTest()
+
+	assert.Equal(t, "id,name,price,discount,storeId,store,attributes\n"+
+		"1,AirFryer,3000,22,1,ABC TECH,\"{\"\"color\"\":\"\"black\"\"}\"\n"+
+		"2,Floor Lamp,2000,0,2,Decoration Palace,\n",
+		exportForTest(productService, response.EXPORT_FORMAT_CSV, domain.ProductFilter{}))
+}
+
+func Test_ShouldExportFilteredProductsAsNdjson(t *testing.T) {
+	productService := newExportProductServiceForTest()
+
+	assert.Equal(t, `{"id":2,"name":"Floor Lamp","price":2000,"discount":0,"storeId":2,"store":"Decoration Palace"}`+"\n",
+		exportForTest(productService, response.EXPORT_FORMAT_NDJSON, domain.ProductFilter{Store: "Decoration Palace"}))
+}
+
+func Test_ShouldExportProductsAsParquet(t *testing.T) {
+	productService := newExportProductServiceForTest()
+
+	exported := exportForTest(productService, response.EXPORT_FORMAT_PARQUET, domain.ProductFilter{})
+
+	assert.Equal(t, "PAR1", exported[:4])
+	assert.Equal(t, "PAR1", exported[len(exported)-4:])
+}
+
+func Test_WhenExportConsumerFails_ShouldStopExport(t *testing.T) {
+	productService := newExportProductServiceForTest()
+	exported := 0
+
+	err := productService.Export(domain.ProductFilter{}, func(product domain.Product) error {
+		exported++
+		return errors.New("client went away")
+	})
+
+	assert.Equal(t, "client went away", err.Error())
+	assert.Equal(t, 1, exported)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newProductImportServiceForTest() (service.IProductImportService, service.IProductService) {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	productService := service.NewProductService(NewFakeProductRepository(products))
+
+	return service.NewProductImportService(productService), productService
+}
+
+const importCsv = `Product Name,Cost,discount,store,attr.color
+Kettle,800,5,ABC TECH,red
+Toaster,abc,0,ABC TECH,
+Blender,1200,90,ABC TECH,
+
+Mixer,950,0,ABC TECH,
+`
+
+func Test_WhenCsvIsMapped_ShouldImportValidRowsAndReportOthers(t *testing.T) {
+	productImportService, productService := newProductImportServiceForTest()
+
+	report, err := productImportService.Import(strings.NewReader(importCsv), service.IMPORT_FORMAT_CSV,
+		map[string]string{"name": "Product Name", "price": "cost"}, false)
+
+	products := productService.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, report.Rows)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, []domain.ImportRowError{
+		{Row: 3, Error: "Price must be a number"},
+		{Row: 4, Error: "Discount can not be greater than 70"},
+	}, report.Errors)
+	assert.Equal(t, 3, len(products))
+	assert.Equal(t, "Kettle", products[1].Name)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, products[1].Attributes)
+}
+
+func Test_WhenImportIsDryRun_ShouldOnlyValidate(t *testing.T) {
+	productImportService, productService := newProductImportServiceForTest()
+
+	report, err := productImportService.Import(strings.NewReader(importCsv), service.IMPORT_FORMAT_CSV,
+		map[string]string{"name": "Product Name", "price": "cost"}, true)
+
+	assert.Nil(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 2, len(report.Errors))
+	assert.Equal(t, 1, len(productService.GetAll()))
+}
+
+func Test_WhenImportColumnsAreMissing_ShouldRefuseFile(t *testing.T) {
+	productImportService, _ := newProductImportServiceForTest()
+
+	_, err := productImportService.Import(strings.NewReader("name,price\nKettle,800\n"), service.IMPORT_FORMAT_CSV, nil, false)
+	assert.Equal(t, "Column for storeId or store not found", err.Error())
+
+	_, err = productImportService.Import(strings.NewReader(importCsv), service.IMPORT_FORMAT_CSV, map[string]string{"colour": "attr.color"}, false)
+	assert.Equal(t, "Unknown import field \"colour\"", err.Error())
+
+	_, err = productImportService.Import(strings.NewReader(importCsv), "pdf", nil, false)
+	assert.Equal(t, "Import format must be csv or xlsx", err.Error())
+}
+
+func Test_WhenXlsxIsUploaded_ShouldImportFirstSheet(t *testing.T) {
+	spoolDir := t.TempDir()
+	t.Setenv("TMPDIR", spoolDir)
+	productImportService, productService := newProductImportServiceForTest()
+
+	file := excelize.NewFile()
+	sheet := file.GetSheetName(0)
+	file.SetSheetRow(sheet, "A1", &[]interface{}{"name", "price", "storeId"})
+	file.SetSheetRow(sheet, "A2", &[]interface{}{"Kettle", 800, 1})
+	file.SetSheetRow(sheet, "A3", &[]interface{}{"Toaster", 450.5, 1})
+	var buffer bytes.Buffer
+	file.Write(&buffer)
+
+	report, err := productImportService.Import(&buffer, service.IMPORT_FORMAT_XLSX, nil, false)
+
+	products := productService.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, float32(450.5), products[2].Price)
+
+	spooled, _ := os.ReadDir(spoolDir)
+	assert.Empty(t, spooled)
+}
+
+func Test_WhenXlsxIsTooLarge_ShouldRefuseFile(t *testing.T) {
+	spoolDir := t.TempDir()
+	t.Setenv("TMPDIR", spoolDir)
+	productImportService, _ := newProductImportServiceForTest()
+
+	source := bytes.NewReader(make([]byte, service.MAX_XLSX_IMPORT_SIZE+1))
+	_, err := productImportService.Import(source, service.IMPORT_FORMAT_XLSX, nil, false)
+
+	assert.True(t, errors.Is(err, service.ErrImportTooLarge))
+
+	spooled, _ := os.ReadDir(spoolDir)
+	assert.Empty(t, spooled)
+}
+
+func Test_WhenXlsxHeaderIsRefused_ShouldRemoveSpooledFile(t *testing.T) {
+	spoolDir := t.TempDir()
+	t.Setenv("TMPDIR", spoolDir)
+	productImportService, _ := newProductImportServiceForTest()
+
+	file := excelize.NewFile()
+	file.SetSheetRow(file.GetSheetName(0), "A1", &[]interface{}{"name", "price"})
+	var buffer bytes.Buffer
+	file.Write(&buffer)
+
+	_, err := productImportService.Import(&buffer, service.IMPORT_FORMAT_XLSX, nil, false)
+
+	assert.Equal(t, "Column for storeId or store not found", err.Error())
+
+	spooled, _ := os.ReadDir(spoolDir)
+	assert.Empty(t, spooled)
+}
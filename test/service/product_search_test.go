@@ -0,0 +1,43 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newSearchProductServiceForTest() service.IProductService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "AirFryer XL", Price: 4000.0, Discount: 10.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	return service.NewProductService(NewFakeProductRepository(products))
+}
+
+func Test_WhenSearchQueryIsBlank_ShouldNotSearch(t *testing.T) {
+	productService := newSearchProductServiceForTest()
+
+	_, err := productService.Search("  ", domain.ProductFilter{}, 0)
+
+	assert.Equal(t, "Search query can not be empty", err.Error())
+}
+
+func Test_WhenSearchLimitIsTooHigh_ShouldNotSearch(t *testing.T) {
+	productService := newSearchProductServiceForTest()
+
+	_, err := productService.Search("air", domain.ProductFilter{}, 1000)
+
+	assert.Equal(t, "Limit must be between 1 and 100", err.Error())
+}
+
+func Test_ShouldSearchWithinStore(t *testing.T) {
+	productService := newSearchProductServiceForTest()
+
+	results, err := productService.Search("air", domain.ProductFilter{Store: "abc tech"}, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, int64(1), results[0].Product.Id)
+}
@@ -79,7 +79,7 @@ func Test_WhenNoValidationErrorOccurred_ShouldAddProduct(t *testing.T) {
 
 func Test_WhenDiscountIsHigherThan70_ShouldNotAddProduct(t *testing.T) {
 	t.Run("WhenDiscountIsHigherThan70_ShouldNotAddProduct", func(t *testing.T) {
-		err := productService.Add(dto.ProductCreate{
+		_, err := productService.Add(dto.ProductCreate{
 			Name:     "Telephone",
 			Price:    20000.0,
 			Discount: 80.0,
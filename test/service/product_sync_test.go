@@ -0,0 +1,91 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newSyncProductServiceForTest() service.IProductService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC TECH", ExternalRef: "ERP-1"},
+		{Id: 2, Name: "Iron", Price: 1500.0, StoreId: 1, Store: "ABC TECH", ExternalRef: "ERP-2"},
+		{Id: 3, Name: "Floor Lamp", Price: 2000.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	return service.NewProductService(NewFakeProductRepository(products))
+}
+
+func Test_ShouldReportWhetherUpsertCreatedOrUpdated(t *testing.T) {
+	productService := newSyncProductServiceForTest()
+
+	upsert, err := productService.UpsertByExternalRef(dto.ProductCreate{ExternalRef: "ERP-3", Name: "Kettle", Price: 400.0, StoreId: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, domain.UPSERT_CREATED, upsert.Outcome)
+	assert.Equal(t, "ABC TECH", upsert.Product.Store)
+
+	upsert, err = productService.UpsertByExternalRef(dto.ProductCreate{ExternalRef: "ERP-1", Name: "AirFryer", Price: 2800.0, StoreId: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, domain.UPSERT_UPDATED, upsert.Outcome)
+	assert.Equal(t, int64(1), upsert.Product.Id)
+
+	upsert, _ = productService.UpsertByExternalRef(dto.ProductCreate{ExternalRef: "ERP-1", Name: "AirFryer", Price: 2800.0, StoreId: 1})
+	assert.Equal(t, domain.UPSERT_UNCHANGED, upsert.Outcome)
+	assert.Equal(t, 4, len(productService.GetAll()))
+}
+
+func Test_WhenExternalRefIsMissing_ShouldNotUpsert(t *testing.T) {
+	productService := newSyncProductServiceForTest()
+
+	_, err := productService.UpsertByExternalRef(dto.ProductCreate{ExternalRef: " ", Name: "Kettle", Price: 400.0, StoreId: 1})
+
+	assert.Equal(t, "External reference must be 1 to 100 characters", err.Error())
+}
+
+func Test_WhenFeedIsFull_ShouldDiscontinueMissingSyncedProducts(t *testing.T) {
+	productService := newSyncProductServiceForTest()
+
+	report, err := productService.SyncByExternalRef(dto.ProductSync{Full: true, Products: []dto.ProductCreate{
+		{ExternalRef: "ERP-1", Name: "AirFryer", Price: 3000.0, StoreId: 1},
+		{ExternalRef: "ERP-3", Name: "Kettle", Price: 400.0, Store: "abc tech"},
+	}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{domain.UPSERT_UNCHANGED, domain.UPSERT_CREATED}, []string{report.Upserts[0].Outcome, report.Upserts[1].Outcome})
+	assert.Equal(t, 1, len(report.Discontinued))
+	assert.Equal(t, "ERP-2", report.Discontinued[0].ExternalRef)
+
+	// Products that were never synced are left alone.
+	lamp, _ := productService.GetById(3)
+	assert.False(t, lamp.Discontinued)
+
+	// Sending a discontinued product again brings it back.
+	report, _ = productService.SyncByExternalRef(dto.ProductSync{Products: []dto.ProductCreate{
+		{ExternalRef: "ERP-2", Name: "Iron", Price: 1500.0, StoreId: 1},
+	}})
+	iron, _ := productService.GetById(2)
+	assert.Equal(t, domain.UPSERT_UPDATED, report.Upserts[0].Outcome)
+	assert.False(t, iron.Discontinued)
+}
+
+func Test_WhenFeedIsInvalid_ShouldNotSync(t *testing.T) {
+	productService := newSyncProductServiceForTest()
+
+	_, err := productService.SyncByExternalRef(dto.ProductSync{Full: true})
+	assert.Equal(t, "Sync feed has no products", err.Error())
+
+	_, err = productService.SyncByExternalRef(dto.ProductSync{Products: []dto.ProductCreate{
+		{ExternalRef: "ERP-3", Name: "Kettle", Price: 400.0, StoreId: 1},
+		{ExternalRef: "ERP-3", Name: "Toaster", Price: 300.0, StoreId: 1},
+	}})
+	assert.Equal(t, "External reference ERP-3 appears more than once", err.Error())
+
+	_, err = productService.SyncByExternalRef(dto.ProductSync{Products: []dto.ProductCreate{
+		{ExternalRef: "ERP-3", Name: "Kettle", Price: 400.0, StoreId: 1},
+		{ExternalRef: "ERP-4", Name: "Toaster", Price: 300.0, Discount: 80.0, StoreId: 1},
+	}})
+	assert.Equal(t, "Product 1: Discount can not be greater than 70", err.Error())
+	assert.Equal(t, 3, len(productService.GetAll()))
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTrashProductServiceForTest() service.IProductService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Iron", Price: 1500.0, Discount: 10.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	return service.NewProductService(NewFakeProductRepository(products))
+}
+
+func Test_WhenProductIsDeleted_ShouldMoveItToTrash(t *testing.T) {
+	productService := newTrashProductServiceForTest()
+
+	err := productService.DeleteById(1)
+
+	_, getErr := productService.GetById(1)
+	deletedProducts := productService.GetAllDeleted()
+	assert.Nil(t, err)
+	assert.Equal(t, "Product not found with id 1", getErr.Error())
+	assert.Equal(t, 1, len(deletedProducts))
+	assert.Equal(t, "AirFryer", deletedProducts[0].Name)
+}
+
+func Test_WhenDeletedProductIsRestored_ShouldGetProductAgain(t *testing.T) {
+	productService := newTrashProductServiceForTest()
+	productService.DeleteById(1)
+
+	err := productService.Restore(1)
+
+	product, getErr := productService.GetById(1)
+	assert.Nil(t, err)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "AirFryer", product.Name)
+	assert.Equal(t, 0, len(productService.GetAllDeleted()))
+	assert.Equal(t, "Deleted product not found with id 2", productService.Restore(2).Error())
+}
+
+func Test_ShouldOnlyPurgeProductsOlderThanRetention(t *testing.T) {
+	productService := newTrashProductServiceForTest()
+	productService.DeleteById(1)
+
+	purged, err := productService.PurgeDeleted(time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	time.Sleep(2 * time.Millisecond)
+	purged = service.NewTrashPurgeJob(productService, time.Millisecond, time.Hour).PurgeOnce()
+	assert.Equal(t, int64(1), purged)
+	assert.Equal(t, 0, len(productService.GetAllDeleted()))
+
+	_, err = productService.PurgeDeleted(0)
+	assert.Equal(t, "Retention must be greater than 0", err.Error())
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"example.com/product-api/domain"
+	"example.com/product-api/persistence"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newPromotionServiceForTest() service.IPromotionService {
+	now := time.Now()
+
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 20.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Floor Lamp", Price: 2000.0, Discount: 0.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	promotions := []domain.Promotion{
+		{Id: 1, Code: "TECH10", StoreIds: []int64{1}, DiscountType: domain.PERCENT_DISCOUNT, Value: 10,
+			ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour)},
+		{Id: 2, Code: "LAMP500", ProductIds: []int64{2}, DiscountType: domain.AMOUNT_DISCOUNT, Value: 500,
+			ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour)},
+		{Id: 3, Code: "OLD", DiscountType: domain.PERCENT_DISCOUNT, Value: 10,
+			ValidFrom: now.Add(-2 * time.Hour), ValidUntil: now.Add(-time.Hour)},
+		{Id: 4, Code: "USEDUP", DiscountType: domain.AMOUNT_DISCOUNT, Value: 100,
+			ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour), UsageLimit: 3, UsageCount: 3},
+	}
+
+	return service.NewPromotionService(NewFakePromotionRepository(promotions), NewFakeProductRepository(products), newStoreRepositoryForTest())
+}
+
+func newStoreRepositoryForTest() persistence.IStoreRepository {
+	return NewFakeStoreRepository([]domain.Store{{Id: 1, Name: "ABC TECH"}, {Id: 2, Name: "Decoration Palace"}})
+}
+
+func Test_WhenCouponIsEmpty_ShouldQuoteDiscountedPrice(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+
+	quote, err := promotionService.Quote(1, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(2400.0), quote.EffectivePrice)
+}
+
+func Test_WhenPercentCouponApplies_ShouldQuoteCouponPrice(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+
+	quote, err := promotionService.Quote(1, "TECH10")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "TECH10", quote.CouponCode)
+	assert.Equal(t, float32(240.0), quote.CouponDiscount)
+	assert.Equal(t, float32(2160.0), quote.EffectivePrice)
+}
+
+func Test_WhenAmountCouponApplies_ShouldQuoteCouponPrice(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+
+	quote, err := promotionService.Quote(2, "LAMP500")
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1500.0), quote.EffectivePrice)
+}
+
+func Test_WhenStoreIsRenamed_ShouldStillApplyStoreCoupon(t *testing.T) {
+	now := time.Now()
+	products := []domain.Product{{Id: 1, Name: "AirFryer", Price: 3000.0, StoreId: 1, Store: "ABC Technology"}}
+	promotions := []domain.Promotion{{Id: 1, Code: "TECH10", StoreIds: []int64{1}, DiscountType: domain.PERCENT_DISCOUNT, Value: 10,
+		ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour)}}
+	promotionService := service.NewPromotionService(NewFakePromotionRepository(promotions), NewFakeProductRepository(products), newStoreRepositoryForTest())
+
+	quote, err := promotionService.Quote(1, "TECH10")
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(2700.0), quote.EffectivePrice)
+}
+
+func Test_WhenCouponIsNotUsable_ShouldRefuseWithReason(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+
+	cases := map[string]struct {
+		productId int64
+		code      string
+		reason    string
+	}{
+		"Expired":       {productId: 1, code: "OLD", reason: "expired"},
+		"Exhausted":     {productId: 1, code: "USEDUP", reason: "usage limit reached"},
+		"OutOfScope":    {productId: 2, code: "TECH10", reason: "not applicable to this product"},
+		"OtherProducts": {productId: 1, code: "LAMP500", reason: "not applicable to this product"},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := promotionService.Quote(testCase.productId, testCase.code)
+
+			var couponRefusedError *service.CouponRefusedError
+			assert.True(t, errors.As(err, &couponRefusedError))
+			assert.Equal(t, testCase.reason, couponRefusedError.Reason)
+		})
+	}
+}
+
+func Test_WhenCouponIsRedeemed_ShouldEnforceUsageLimit(t *testing.T) {
+	now := time.Now()
+	products := []domain.Product{{Id: 1, Name: "AirFryer", Price: 3000.0, Store: "ABC TECH"}}
+	promotions := []domain.Promotion{{Id: 1, Code: "TWICE", DiscountType: domain.AMOUNT_DISCOUNT, Value: 100,
+		ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour), UsageLimit: 2}}
+	promotionService := service.NewPromotionService(NewFakePromotionRepository(promotions), NewFakeProductRepository(products), newStoreRepositoryForTest())
+
+	for i := 0; i < 2; i++ {
+		quote, err := promotionService.Redeem(1, "TWICE")
+		assert.Nil(t, err)
+		assert.Equal(t, float32(2900.0), quote.EffectivePrice)
+	}
+
+	_, err := promotionService.Redeem(1, "TWICE")
+
+	var couponRefusedError *service.CouponRefusedError
+	assert.True(t, errors.As(err, &couponRefusedError))
+	assert.Equal(t, "usage limit reached", couponRefusedError.Reason)
+
+	promotion, _ := promotionService.GetById(1)
+	assert.Equal(t, int32(2), promotion.UsageCount)
+}
+
+func Test_WhenCouponIsOnlyQuoted_ShouldNotCountUse(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+
+	_, err := promotionService.Quote(1, "TECH10")
+	assert.Nil(t, err)
+
+	promotion, _ := promotionService.GetById(1)
+	assert.Equal(t, int32(0), promotion.UsageCount)
+}
+
+func Test_WhenCouponDoesNotExist_ShouldReturnNotFound(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+
+	_, err := promotionService.Quote(1, "MISSING")
+
+	assert.Equal(t, "Promotion not found with code MISSING", err.Error())
+}
+
+func Test_WhenPromotionIsInvalid_ShouldNotAddPromotion(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+	now := time.Now()
+
+	err := promotionService.Add(dto.PromotionCreate{
+		Code:         "BROKEN",
+		DiscountType: domain.PERCENT_DISCOUNT,
+		Value:        150,
+		ValidFrom:    now,
+		ValidUntil:   now.Add(time.Hour),
+	})
+
+	assert.Equal(t, "Percent value can not be greater than 100", err.Error())
+	assert.Equal(t, 4, len(promotionService.GetAll()))
+}
+
+func Test_WhenPromotionStoreDoesNotExist_ShouldNotAddPromotion(t *testing.T) {
+	promotionService := newPromotionServiceForTest()
+	now := time.Now()
+
+	err := promotionService.Add(dto.PromotionCreate{
+		Code:         "NOSTORE",
+		StoreIds:     []int64{9},
+		DiscountType: domain.PERCENT_DISCOUNT,
+		Value:        10,
+		ValidFrom:    now,
+		ValidUntil:   now.Add(time.Hour),
+	})
+
+	assert.Equal(t, "Store not found with id 9", err.Error())
+	assert.Equal(t, 4, len(promotionService.GetAll()))
+}
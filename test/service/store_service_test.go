@@ -0,0 +1,53 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newStoreServiceForTest() service.IStoreService {
+	stores := []domain.Store{
+		{Id: 1, Name: "ABC TECH"},
+		{Id: 2, Name: "Decoration Palace"},
+	}
+
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 2, Name: "Iron", Price: 1500.0, Discount: 10.0, StoreId: 1, Store: "ABC TECH"},
+		{Id: 3, Name: "Floor Lamp", Price: 2000.0, Discount: 0.0, StoreId: 2, Store: "Decoration Palace"},
+	}
+
+	return service.NewStoreService(NewFakeStoreRepository(stores), NewFakeProductRepository(products))
+}
+
+func Test_WhenStoreNameDiffersOnlyByCase_ShouldNotAddStore(t *testing.T) {
+	storeService := newStoreServiceForTest()
+
+	err := storeService.Add(dto.StoreCreate{Name: "  abc   Tech "})
+
+	assert.Equal(t, "Store already exists with name abc Tech", err.Error())
+	assert.Equal(t, 2, len(storeService.GetAll()))
+}
+
+func Test_WhenStoreNameIsBlank_ShouldNotAddStore(t *testing.T) {
+	storeService := newStoreServiceForTest()
+
+	err := storeService.Add(dto.StoreCreate{Name: "   "})
+
+	assert.Equal(t, "Store name can not be empty", err.Error())
+}
+
+func Test_ShouldGetProductsOfStore(t *testing.T) {
+	storeService := newStoreServiceForTest()
+
+	products, err := storeService.GetProducts(1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(products))
+
+	_, err = storeService.GetProducts(3)
+	assert.Equal(t, "Store not found with id 3", err.Error())
+}
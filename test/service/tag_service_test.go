@@ -0,0 +1,44 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTagServiceForTest() service.ITagService {
+	products := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: 3000.0, Discount: 22.0, StoreId: 1, Store: "ABC TECH"},
+	}
+
+	return service.NewTagService(NewFakeTagRepository(map[int64][]string{1: {"kitchen"}}), NewFakeProductRepository(products))
+}
+
+func Test_WhenTagsAreValid_ShouldNormalizeAndAddTags(t *testing.T) {
+	tagService := newTagServiceForTest()
+
+	err := tagService.AddToProduct(1, []string{" Sale ", "sale", "Kitchen", "new-arrival"})
+
+	tags, _ := tagService.GetAllByProductId(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"kitchen", "sale", "new-arrival"}, tags)
+}
+
+func Test_WhenTagIsInvalid_ShouldNotAddTags(t *testing.T) {
+	tagService := newTagServiceForTest()
+
+	assert.Equal(t, "Tag \"on sale\" may only contain letters, digits and dashes", tagService.AddToProduct(1, []string{"on sale"}).Error())
+	assert.Equal(t, "At least one tag is required", tagService.AddToProduct(1, []string{}).Error())
+	assert.Equal(t, "Product not found with id 9", tagService.AddToProduct(9, []string{"sale"}).Error())
+}
+
+func Test_ShouldRemoveTagsFromProduct(t *testing.T) {
+	tagService := newTagServiceForTest()
+
+	err := tagService.RemoveFromProduct(1, []string{"KITCHEN"})
+
+	tags, _ := tagService.GetAllByProductId(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, tags)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newVariantServiceForTest() service.IVariantService {
+	products := []domain.Product{
+		{Id: 1, Name: "T-Shirt", Price: 300.0, Discount: 0.0, StoreId: 1, Store: "Fashion Hub"},
+		{Id: 2, Name: "Sneakers", Price: 1800.0, Discount: 0.0, StoreId: 1, Store: "Fashion Hub"},
+	}
+
+	variants := []domain.Variant{
+		{Id: 1, ProductId: 1, Sku: "TS-RED-M", Attributes: map[string]string{"color": "red", "size": "M"}},
+	}
+
+	return service.NewVariantService(NewFakeVariantRepository(variants), NewFakeProductRepository(products))
+}
+
+func Test_WhenSkuIsNew_ShouldAddVariant(t *testing.T) {
+	variantService := newVariantServiceForTest()
+	priceOverride := float32(320.0)
+
+	err := variantService.Add(1, dto.VariantCreate{
+		Sku:           " TS-RED-L ",
+		Attributes:    map[string]string{"color": "red", "size": "L"},
+		PriceOverride: &priceOverride,
+		Barcode:       "8690000000017",
+	})
+
+	variants, _ := variantService.GetAllByProductId(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(variants))
+	assert.Equal(t, "TS-RED-L", variants[1].Sku)
+	assert.Equal(t, float32(320.0), *variants[1].PriceOverride)
+}
+
+func Test_WhenSkuIsTaken_ShouldNotAddVariant(t *testing.T) {
+	variantService := newVariantServiceForTest()
+
+	err := variantService.Add(2, dto.VariantCreate{Sku: "TS-RED-M"})
+
+	assert.Equal(t, "Variant already exists with sku TS-RED-M or barcode ", err.Error())
+}
+
+func Test_WhenVariantIsInvalid_ShouldNotAddVariant(t *testing.T) {
+	variantService := newVariantServiceForTest()
+	negativePrice := float32(-1)
+
+	assert.Equal(t, "Sku can not be empty", variantService.Add(1, dto.VariantCreate{Sku: " "}).Error())
+	assert.Equal(t, "Price override must be greater than 0", variantService.Add(1, dto.VariantCreate{Sku: "X", PriceOverride: &negativePrice}).Error())
+	assert.Equal(t, "Barcode must be 8, 12, 13 or 14 digits", variantService.Add(1, dto.VariantCreate{Sku: "X", Barcode: "12AB"}).Error())
+	assert.Equal(t, "Product not found with id 9", variantService.Add(9, dto.VariantCreate{Sku: "X"}).Error())
+}
+
+func Test_ShouldGetVariantsOfSeveralProducts(t *testing.T) {
+	variantService := newVariantServiceForTest()
+	variantService.Add(2, dto.VariantCreate{Sku: "SN-42"})
+
+	variants := variantService.GetAllByProductIds([]int64{1, 2})
+
+	assert.Equal(t, 2, len(variants))
+	assert.Equal(t, 0, len(variantService.GetAllByProductIds([]int64{})))
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"example.com/product-api/domain"
+	"example.com/product-api/service"
+	"example.com/product-api/service/dto"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const webhookSecretForTest = "0123456789abcdef"
+
+var webhookDispatcherConfigForTest = service.WebhookDispatcherConfig{
+	PollInterval:          time.Second,
+	BatchSize:             10,
+	Lease:                 time.Minute,
+	MaxAttempts:           3,
+	RetryBackoff:          time.Minute,
+	MaxRetryBackoff:       time.Hour,
+	Timeout:               time.Second,
+	AllowPrivateAddresses: true,
+}
+
+// webhookReceiver is a local partner endpoint that answers with the given
+// statuses in turn and then with 200, and remembers what it received.
+type webhookReceiver struct {
+	mutex    sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) (*webhookReceiver, string) {
+	receiver := &webhookReceiver{statuses: statuses}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	return receiver, server.URL
+}
+
+func (receiver *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	receiver.requests = append(receiver.requests, r)
+	receiver.bodies = append(receiver.bodies, body)
+
+	if len(receiver.statuses) > 0 {
+		w.WriteHeader(receiver.statuses[0])
+		receiver.statuses = receiver.statuses[1:]
+	}
+}
+
+func enqueueForTest(t *testing.T, webhookRepository *FakeWebhookRepository, events ...domain.ProductEvent) {
+	sink := service.NewWebhookSubscriptionSink(webhookRepository)
+
+	for _, event := range events {
+		assert.Nil(t, sink.Deliver(event))
+	}
+}
+
+func Test_WhenWebhookIsInvalid_ShouldNotAddIt(t *testing.T) {
+	webhookService := service.NewWebhookService(NewFakeWebhookRepository())
+
+	invalidWebhooks := map[string]dto.WebhookCreate{
+		"Url must be an absolute http or https url": {Url: "ftp://partner.example.com", EventTypes: []string{domain.PRODUCT_CREATED}},
+		"Event types can not be empty":              {Url: "https://partner.example.com"},
+		"Unknown event type renamed":                {Url: "https://partner.example.com", EventTypes: []string{"renamed"}},
+		"Secret must be at least 16 characters":     {Url: "https://partner.example.com", EventTypes: []string{domain.PRODUCT_CREATED}, Secret: "short"},
+	}
+
+	for message, webhookCreate := range invalidWebhooks {
+		_, err := webhookService.Add(webhookCreate)
+		assert.Equal(t, message, err.Error())
+	}
+
+	assert.Empty(t, webhookService.GetAll())
+}
+
+func Test_WhenWebhookUrlIsNotPublic_ShouldNotAddIt(t *testing.T) {
+	webhookService := service.NewWebhookService(NewFakeWebhookRepository())
+
+	urls := []string{"http://localhost:8080/hooks", "http://api.localhost", "http://127.0.0.1", "http://127.8.0.1:9000",
+		"http://169.254.169.254/latest/meta-data", "http://10.0.0.5", "http://172.16.4.2", "http://192.168.1.10", "http://[::1]/hooks",
+		"http://[fe80::1]", "http://0.0.0.0"}
+
+	for _, url := range urls {
+		_, err := webhookService.Add(dto.WebhookCreate{Url: url, EventTypes: []string{domain.PRODUCT_CREATED}})
+		assert.ErrorIs(t, err, service.ErrWebhookAddressNotPublic, url)
+	}
+
+	assert.Empty(t, webhookService.GetAll())
+}
+
+func Test_WhenWebhookAddressIsNotPublic_ShouldNotConnect(t *testing.T) {
+	receiver, url := newWebhookReceiver(t)
+	webhookRepository := NewFakeWebhookRepository(
+		domain.WebhookSubscription{Id: 1, Url: url, EventTypes: []string{domain.PRODUCT_CREATED}, Secret: webhookSecretForTest})
+	enqueueForTest(t, webhookRepository, domain.ProductEvent{Id: 10, Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 1}})
+
+	config := webhookDispatcherConfigForTest
+	config.AllowPrivateAddresses = false
+	service.NewWebhookDispatcher(webhookRepository, config).DispatchOnce()
+
+	assert.Empty(t, receiver.requests)
+	assert.Equal(t, domain.WEBHOOK_FAILED, webhookRepository.GetDeliveries(1, "", 10)[0].Status)
+}
+
+func Test_WhenSecretIsMissing_ShouldGenerateOne(t *testing.T) {
+	webhookService := service.NewWebhookService(NewFakeWebhookRepository())
+
+	subscription, err := webhookService.Add(dto.WebhookCreate{Url: "https://partner.example.com", EventTypes: []string{domain.PRODUCT_PRICE_CHANGED}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 64, len(subscription.Secret))
+}
+
+func Test_ShouldEnqueueDeliveriesForMatchingSubscriptionsOnce(t *testing.T) {
+	webhookRepository := NewFakeWebhookRepository(
+		domain.WebhookSubscription{Id: 1, EventTypes: []string{domain.PRODUCT_PRICE_CHANGED}, StoreId: 1},
+		domain.WebhookSubscription{Id: 2, EventTypes: []string{domain.PRODUCT_PRICE_CHANGED, domain.PRODUCT_DELETED}},
+		domain.WebhookSubscription{Id: 3, EventTypes: []string{domain.PRODUCT_CREATED}},
+	)
+	priceChanged := domain.ProductEvent{Id: 10, Type: domain.PRODUCT_PRICE_CHANGED, Product: domain.Product{Id: 1, StoreId: 2}}
+
+	// The outbox may hand the same event over twice.
+	enqueueForTest(t, webhookRepository, priceChanged, priceChanged)
+
+	assert.Empty(t, webhookRepository.GetDeliveries(1, "", 10))
+	assert.Equal(t, 1, len(webhookRepository.GetDeliveries(2, "", 10)))
+	assert.Empty(t, webhookRepository.GetDeliveries(3, "", 10))
+}
+
+func Test_ShouldSignDeliveriesWithSecretAndTimestamp(t *testing.T) {
+	receiver, url := newWebhookReceiver(t)
+	webhookRepository := NewFakeWebhookRepository(
+		domain.WebhookSubscription{Id: 1, Url: url, EventTypes: []string{domain.PRODUCT_PRICE_CHANGED}, Secret: webhookSecretForTest})
+	enqueueForTest(t, webhookRepository, domain.ProductEvent{Id: 10, Type: domain.PRODUCT_PRICE_CHANGED, PreviousPrice: 3000.0,
+		Product: domain.Product{Id: 1, Price: 2800.0, StoreId: 1, Store: "ABC TECH"}})
+
+	assert.Equal(t, 1, service.NewWebhookDispatcher(webhookRepository, webhookDispatcherConfigForTest).DispatchOnce())
+
+	request, body := receiver.requests[0], receiver.bodies[0]
+	timestamp := request.Header.Get(service.WEBHOOK_TIMESTAMP_HEADER)
+	sentAt, _ := strconv.ParseInt(timestamp, 10, 64)
+
+	mac := hmac.New(sha256.New, []byte(webhookSecretForTest))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.Header.Get(service.WEBHOOK_SIGNATURE_HEADER))
+	assert.WithinDuration(t, time.Now(), time.Unix(sentAt, 0), 5*time.Second)
+	assert.Equal(t, domain.PRODUCT_PRICE_CHANGED, request.Header.Get(service.WEBHOOK_EVENT_HEADER))
+	assert.Equal(t, "1", request.Header.Get(service.WEBHOOK_ID_HEADER))
+	assert.Contains(t, string(body), `"previousPrice":3000`)
+	assert.Equal(t, domain.WEBHOOK_SUCCEEDED, webhookRepository.GetDeliveries(1, "", 10)[0].Status)
+}
+
+func Test_WhenReceiverFails_ShouldRetryWithBackoff(t *testing.T) {
+	receiver, url := newWebhookReceiver(t, http.StatusServiceUnavailable, http.StatusInternalServerError)
+	webhookRepository := NewFakeWebhookRepository(
+		domain.WebhookSubscription{Id: 1, Url: url, EventTypes: []string{domain.PRODUCT_CREATED}, Secret: webhookSecretForTest})
+	enqueueForTest(t, webhookRepository, domain.ProductEvent{Id: 10, Type: domain.PRODUCT_CREATED, Product: domain.Product{Id: 7}})
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepository, webhookDispatcherConfigForTest)
+
+	webhookDispatcher.DispatchOnce()
+
+	delivery := webhookRepository.GetDeliveries(1, "", 10)[0]
+	assert.Equal(t, domain.WEBHOOK_FAILED, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+	assert.Equal(t, "Webhook answered with status 503", delivery.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+	assert.Equal(t, 0, webhookDispatcher.DispatchOnce())
+
+	webhookRepository.MakeDue()
+	webhookDispatcher.DispatchOnce()
+
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), webhookRepository.GetDeliveries(1, "", 10)[0].NextAttemptAt, 5*time.Second)
+
+	webhookRepository.MakeDue()
+	webhookDispatcher.DispatchOnce()
+
+	delivery = webhookRepository.GetDeliveries(1, "", 10)[0]
+	assert.Equal(t, domain.WEBHOOK_SUCCEEDED, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, 3, len(receiver.requests))
+	assert.Equal(t, receiver.bodies[0], receiver.bodies[2])
+}
+
+func Test_WhenAttemptsRunOut_ShouldKeepDeliveryDeadUntilRedelivered(t *testing.T) {
+	receiver, url := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	webhookRepository := NewFakeWebhookRepository(
+		domain.WebhookSubscription{Id: 1, Url: url, EventTypes: []string{domain.PRODUCT_DELETED}, Secret: webhookSecretForTest})
+	enqueueForTest(t, webhookRepository, domain.ProductEvent{Id: 10, Type: domain.PRODUCT_DELETED, Product: domain.Product{Id: 2}})
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepository, webhookDispatcherConfigForTest)
+	webhookService := service.NewWebhookService(webhookRepository)
+
+	for i := 0; i < 5; i++ {
+		webhookRepository.MakeDue()
+		webhookDispatcher.DispatchOnce()
+	}
+
+	deadDeliveries, err := webhookService.GetDeliveries(1, domain.WEBHOOK_DEAD)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deadDeliveries))
+	assert.Equal(t, 3, len(receiver.requests))
+
+	assert.Nil(t, webhookService.Redeliver(1, deadDeliveries[0].Id))
+	webhookDispatcher.DispatchOnce()
+
+	assert.Equal(t, 4, len(receiver.requests))
+	assert.Equal(t, domain.WEBHOOK_SUCCEEDED, webhookRepository.GetDeliveries(1, "", 10)[0].Status)
+	assert.Equal(t, "Webhook delivery not found with id 9", webhookService.Redeliver(1, 9).Error())
+}
+
+func Test_WhenDeliveryStatusIsUnknown_ShouldNotListDeliveries(t *testing.T) {
+	webhookService := service.NewWebhookService(NewFakeWebhookRepository(domain.WebhookSubscription{Id: 1}))
+
+	_, err := webhookService.GetDeliveries(1, "lost")
+	assert.Equal(t, service.ErrInvalidDeliveryStatus, err)
+
+	_, err = webhookService.GetDeliveries(2, "")
+	assert.Equal(t, "Webhook not found with id 2", err.Error())
+}